@@ -0,0 +1,29 @@
+package main
+
+import (
+	"context"
+	"time"
+
+	"github.com/boreq/errors"
+	"github.com/planetary-social/scuttlego-pub/service/adapters"
+	"github.com/planetary-social/scuttlego-pub/service/ports/admin"
+)
+
+// adminRequestTimeout limits the time spent waiting for the running pub to
+// execute a request sent over the admin socket.
+const adminRequestTimeout = 1 * time.Minute
+
+// newAdminClient creates a client of the admin socket of the pub running with
+// the config stored in the provided directory. Only the location of the socket
+// is loaded so the config file isn't upgraded and secrets aren't read.
+func newAdminClient(configDirectory string) (*admin.Client, error) {
+	config, err := adapters.NewConfigStorage(configDirectory).LoadPaths()
+	if err != nil {
+		return nil, errors.Wrap(err, "error loading config")
+	}
+	return admin.NewClient(config.AdminSocketPath()), nil
+}
+
+func newAdminRequestContext() (context.Context, context.CancelFunc) {
+	return context.WithTimeout(context.Background(), adminRequestTimeout)
+}
@@ -10,8 +10,14 @@ import (
 var rootCommand = guinea.Command{
 	Run: nil,
 	Subcommands: map[string]*guinea.Command{
-		"run":  &runCommand,
-		"init": &initCommand,
+		"run":      &runCommand,
+		"init":     &initCommand,
+		"config":   &configCommand,
+		"identity": &identityCommand,
+		"invites":  &invitesCommand,
+		"bans":     &bansCommand,
+		"status":   &statusCommand,
+		"peers":    &peersCommand,
 	},
 	Options:          nil,
 	Arguments:        nil,
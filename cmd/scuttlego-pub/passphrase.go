@@ -0,0 +1,129 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+
+	"github.com/boreq/errors"
+	"github.com/boreq/guinea"
+	"github.com/planetary-social/scuttlego-pub/service"
+	"github.com/planetary-social/scuttlego-pub/service/adapters"
+	"github.com/planetary-social/scuttlego/service/domain/identity"
+	"golang.org/x/term"
+)
+
+const (
+	optionPassphraseFD    = "passphrase-fd"
+	optionEncryptIdentity = "encrypt-identity"
+)
+
+var passphraseFDOption = guinea.Option{
+	Name:        optionPassphraseFD,
+	Type:        guinea.Int,
+	Default:     -1,
+	Description: fmt.Sprintf("File descriptor from which the passphrase of the identity is read. If it isn't set then the passphrase is read from %s or a prompt.", adapters.IdentityPassphraseEnvironmentVariable),
+}
+
+var encryptIdentityOption = guinea.Option{
+	Name:        optionEncryptIdentity,
+	Type:        guinea.Bool,
+	Default:     false,
+	Description: "Encrypt the identity with a passphrase which will have to be provided every time the pub is started.",
+}
+
+// newIdentityStorage uses the identity file from the config if it is set.
+// Relative paths are resolved against the config directory.
+func newIdentityStorage(configDirectory string, config service.Config) *adapters.IdentityStorage {
+	if config.IdentityFile != "" {
+		return adapters.NewIdentityStorageWithFile(adapters.ResolveSecretFile(configDirectory, config.IdentityFile))
+	}
+	return adapters.NewIdentityStorage(configDirectory)
+}
+
+// loadIdentity asks for the passphrase only if the identity is encrypted.
+func loadIdentity(identityStorage *adapters.IdentityStorage, options map[string]guinea.OptionValue) (identity.Private, error) {
+	encrypted, err := identityStorage.IsEncrypted()
+	if err != nil {
+		return identity.Private{}, errors.Wrap(err, "error checking if the identity is encrypted")
+	}
+
+	if !encrypted {
+		return identityStorage.Load()
+	}
+
+	passphrase, err := readPassphrase(options[optionPassphraseFD].Int(), false)
+	if err != nil {
+		return identity.Private{}, errors.Wrap(err, "error reading the passphrase")
+	}
+
+	return identityStorage.LoadEncrypted(passphrase)
+}
+
+// readPassphrase reads the passphrase from the file descriptor if it isn't
+// negative, then from the environment variable and finally from a prompt. If
+// confirm is set the prompt asks for the passphrase twice.
+func readPassphrase(fd int, confirm bool) ([]byte, error) {
+	if fd >= 0 {
+		return readPassphraseFromFD(fd)
+	}
+
+	if passphrase, ok := os.LookupEnv(adapters.IdentityPassphraseEnvironmentVariable); ok {
+		// the variable shouldn't be inherited by child processes
+		if err := os.Unsetenv(adapters.IdentityPassphraseEnvironmentVariable); err != nil {
+			return nil, errors.Wrap(err, "error unsetting the environment variable")
+		}
+		return []byte(passphrase), nil
+	}
+
+	passphrase, err := promptForPassphrase("Passphrase: ")
+	if err != nil {
+		return nil, errors.Wrap(err, "error prompting for the passphrase")
+	}
+
+	if confirm {
+		confirmation, err := promptForPassphrase("Repeat the passphrase: ")
+		if err != nil {
+			return nil, errors.Wrap(err, "error prompting for the passphrase")
+		}
+
+		if !bytes.Equal(passphrase, confirmation) {
+			return nil, errors.New("passphrases don't match")
+		}
+	}
+
+	return passphrase, nil
+}
+
+func readPassphraseFromFD(fd int) ([]byte, error) {
+	b, err := adapters.ReadSecretFile(fmt.Sprintf("fd:%d", fd))
+	if err != nil {
+		return nil, errors.Wrapf(err, "error reading from file descriptor %d", fd)
+	}
+
+	return bytes.TrimRight(b, "\r\n"), nil
+}
+
+func promptForPassphrase(prompt string) ([]byte, error) {
+	fmt.Fprint(os.Stderr, prompt)
+	defer fmt.Fprintln(os.Stderr)
+
+	return readFromTerminalWithoutEcho()
+}
+
+// readFromTerminalWithoutEcho reads a line from the standard input which has
+// to be a terminal. Echo is disabled while the line is being read.
+func readFromTerminalWithoutEcho() ([]byte, error) {
+	fd := int(os.Stdin.Fd())
+
+	if !term.IsTerminal(fd) {
+		return nil, errors.New("standard input is not a terminal")
+	}
+
+	line, err := term.ReadPassword(fd)
+	if err != nil {
+		return nil, errors.Wrap(err, "read error")
+	}
+
+	return line, nil
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/boreq/errors"
+	"github.com/boreq/guinea"
+	"github.com/planetary-social/scuttlego/service/domain/refs"
+)
+
+var bansCommand = guinea.Command{
+	Run: nil,
+	Subcommands: map[string]*guinea.Command{
+		"add":    &bansAddCommand,
+		"remove": &bansRemoveCommand,
+	},
+	Options:          nil,
+	Arguments:        nil,
+	ShortDescription: "manages banned feeds",
+	Description:      "Commands used to manage feeds which the pub refuses to replicate. The pub must be running.",
+}
+
+var bansFeedArguments = []guinea.Argument{
+	{
+		Name:        "config_directory",
+		Multiple:    false,
+		Optional:    false,
+		Description: "Path to the directory containing the configuration.",
+	},
+	{
+		Name:        "feed",
+		Multiple:    false,
+		Optional:    false,
+		Description: "Feed, for example @CIlwTOK+m6v1hT2zUVOCJvvZq7KE/65ErN6yA2yrURY=.ed25519.",
+	},
+}
+
+var bansAddCommand = guinea.Command{
+	Run:              bansAddFn,
+	Subcommands:      nil,
+	Options:          nil,
+	Arguments:        bansFeedArguments,
+	ShortDescription: "bans a feed",
+	Description:      "Bans a feed. Stored messages of the feed are removed and the feed is no longer replicated.",
+}
+
+func bansAddFn(cliContext guinea.Context) error {
+	configDirectory := cliContext.Arguments[0]
+
+	feed, err := refs.NewFeed(cliContext.Arguments[1])
+	if err != nil {
+		return errors.Wrap(err, "invalid feed")
+	}
+
+	client, err := newAdminClient(configDirectory)
+	if err != nil {
+		return errors.Wrap(err, "error creating the admin client")
+	}
+
+	ctx, cancel := newAdminRequestContext()
+	defer cancel()
+
+	if err := client.BanFeed(ctx, feed); err != nil {
+		return errors.Wrap(err, "error banning the feed")
+	}
+
+	fmt.Printf("banned %s\n", feed)
+	return nil
+}
+
+var bansRemoveCommand = guinea.Command{
+	Run:              bansRemoveFn,
+	Subcommands:      nil,
+	Options:          nil,
+	Arguments:        bansFeedArguments,
+	ShortDescription: "unbans a feed",
+	Description:      "Unbans a feed so that it can be replicated again.",
+}
+
+func bansRemoveFn(cliContext guinea.Context) error {
+	configDirectory := cliContext.Arguments[0]
+
+	feed, err := refs.NewFeed(cliContext.Arguments[1])
+	if err != nil {
+		return errors.Wrap(err, "invalid feed")
+	}
+
+	client, err := newAdminClient(configDirectory)
+	if err != nil {
+		return errors.Wrap(err, "error creating the admin client")
+	}
+
+	ctx, cancel := newAdminRequestContext()
+	defer cancel()
+
+	if err := client.UnbanFeed(ctx, feed); err != nil {
+		return errors.Wrap(err, "error unbanning the feed")
+	}
+
+	fmt.Printf("unbanned %s\n", feed)
+	return nil
+}
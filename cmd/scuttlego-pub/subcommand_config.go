@@ -0,0 +1,88 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/boreq/errors"
+	"github.com/boreq/guinea"
+	"github.com/planetary-social/scuttlego-pub/service/adapters"
+)
+
+var configCommand = guinea.Command{
+	Run: nil,
+	Subcommands: map[string]*guinea.Command{
+		"print": &configPrintCommand,
+		"check": &configCheckCommand,
+	},
+	Options:          nil,
+	Arguments:        nil,
+	ShortDescription: "manages the configuration",
+	Description:      "Commands used to manage the configuration.",
+}
+
+var configPrintCommand = guinea.Command{
+	Run:         configPrintFn,
+	Subcommands: nil,
+	Options:     nil,
+	Arguments: []guinea.Argument{
+		{
+			Name:        "config_directory",
+			Multiple:    false,
+			Optional:    false,
+			Description: "Path to the directory containing the configuration.",
+		},
+	},
+	ShortDescription: "prints the effective configuration",
+	Description: fmt.Sprintf(
+		"Prints the configuration after applying environment variables with secrets redacted. Config files created by older versions of the program are upgraded in memory but not rewritten. The following environment variables override the values from the config file: %s. Byte values can be encoded either as hex or base64.",
+		strings.Join(adapters.EnvironmentVariableNames(), ", "),
+	),
+}
+
+func configPrintFn(cliContext guinea.Context) error {
+	configDirectory := cliContext.Arguments[0]
+
+	configStorage := adapters.NewConfigStorage(configDirectory)
+
+	config, err := configStorage.LoadWithoutUpgrading()
+	if err != nil {
+		return errors.Wrap(err, "error loading config")
+	}
+
+	if err := adapters.WriteRedactedConfig(os.Stdout, config); err != nil {
+		return errors.Wrap(err, "error writing config")
+	}
+
+	return nil
+}
+
+var configCheckCommand = guinea.Command{
+	Run:         configCheckFn,
+	Subcommands: nil,
+	Options:     nil,
+	Arguments: []guinea.Argument{
+		{
+			Name:        "config_directory",
+			Multiple:    false,
+			Optional:    false,
+			Description: "Path to the directory containing the configuration.",
+		},
+	},
+	ShortDescription: "checks the configuration",
+	Description:      "Loads the configuration after applying environment variables and reports all problems which would prevent the pub from running. The config file is never modified, config files created by older versions of the program are upgraded in memory.",
+}
+
+func configCheckFn(cliContext guinea.Context) error {
+	configDirectory := cliContext.Arguments[0]
+
+	configStorage := adapters.NewConfigStorage(configDirectory)
+
+	if err := configStorage.Check(); err != nil {
+		return errors.Wrap(err, "error checking config")
+	}
+
+	fmt.Println("config is valid")
+	return nil
+}
@@ -0,0 +1,184 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/boreq/errors"
+	"github.com/boreq/guinea"
+	"github.com/planetary-social/scuttlego-pub/service/adapters"
+)
+
+const identityImportOptionForce = "force"
+
+var identityCommand = guinea.Command{
+	Run: nil,
+	Subcommands: map[string]*guinea.Command{
+		"import": &identityImportCommand,
+		"export": &identityExportCommand,
+	},
+	Options:          nil,
+	Arguments:        nil,
+	ShortDescription: "manages the identity",
+	Description:      "Commands used to move identities between scuttlego-pub and other Secure Scuttlebutt implementations such as ssb-server and go-sbot.",
+}
+
+var identityImportCommand = guinea.Command{
+	Run:         identityImportFn,
+	Subcommands: nil,
+	Options: []guinea.Option{
+		{
+			Name:        identityImportOptionForce,
+			Type:        guinea.Bool,
+			Default:     false,
+			Description: "Confirm that the identity is no longer used by any other installation. The existing identity is overwritten and lost.",
+		},
+		encryptIdentityOption,
+		passphraseFDOption,
+	},
+	Arguments: []guinea.Argument{
+		{
+			Name:        "config_directory",
+			Multiple:    false,
+			Optional:    false,
+			Description: "Path to the directory containing the configuration.",
+		},
+		{
+			Name:        "secret_file",
+			Multiple:    false,
+			Optional:    false,
+			Description: "Path to the secret file, usually ~/.ssb/secret.",
+		},
+	},
+	ShortDescription: "imports an identity from a secret file",
+	Description:      "Imports an identity from the secret file used by ssb-server and go-sbot. The installation which used the identity so far must be shut down and never started again as publishing from both of them forks the feed of the identity which can't be repaired. The existing identity is overwritten so --force must be used to confirm that. If the existing identity is encrypted then --encrypt-identity must be used so that the imported identity is also encrypted. The pub must not be running.",
+}
+
+func identityImportFn(cliContext guinea.Context) error {
+	configDirectory := cliContext.Arguments[0]
+	secretFile := cliContext.Arguments[1]
+
+	f, err := os.Open(secretFile)
+	if err != nil {
+		return errors.Wrap(err, "error opening the secret file")
+	}
+	defer f.Close()
+
+	iden, err := adapters.ReadSSBSecret(f)
+	if err != nil {
+		return errors.Wrap(err, "error reading the secret file")
+	}
+
+	config, err := adapters.NewConfigStorage(configDirectory).LoadPaths()
+	if err != nil {
+		return errors.Wrap(err, "error loading config")
+	}
+
+	identityStorage := newIdentityStorage(configDirectory, config)
+
+	fmt.Fprintf(os.Stderr, "warning: the installation which used identity %s so far must be shut down and never started again. Publishing messages from both installations forks the feed which can't be repaired.\n", iden.Public())
+
+	if !cliContext.Options[identityImportOptionForce].Bool() {
+		return errors.New("refusing to import the identity, use --force to confirm that it is no longer used anywhere else and to overwrite the existing identity")
+	}
+
+	encrypt := cliContext.Options[optionEncryptIdentity].Bool()
+
+	if !encrypt {
+		if err := checkIdentityIsNotEncrypted(identityStorage); err != nil {
+			return errors.Wrap(err, "refusing to store the imported identity in plaintext, use --encrypt-identity to encrypt it")
+		}
+	}
+
+	var passphrase []byte
+	if encrypt {
+		passphrase, err = readPassphrase(cliContext.Options[optionPassphraseFD].Int(), true)
+		if err != nil {
+			return errors.Wrap(err, "error reading the passphrase")
+		}
+	}
+
+	if err := saveNewIdentity(identityStorage, iden, encrypt, passphrase, true); err != nil {
+		return errors.Wrap(err, "error saving identity")
+	}
+
+	fmt.Printf("imported identity %s\n", iden.Public())
+	return nil
+}
+
+// checkIdentityIsNotEncrypted returns an error if the existing identity is
+// encrypted so that it isn't replaced with one stored in plaintext.
+func checkIdentityIsNotEncrypted(identityStorage *adapters.IdentityStorage) error {
+	exists, err := identityStorage.Exists()
+	if err != nil {
+		return errors.Wrap(err, "error checking if the identity exists")
+	}
+
+	if !exists {
+		return nil
+	}
+
+	encrypted, err := identityStorage.IsEncrypted()
+	if err != nil {
+		return errors.Wrap(err, "error checking if the identity is encrypted")
+	}
+
+	if encrypted {
+		return errors.New("existing identity is encrypted")
+	}
+
+	return nil
+}
+
+var identityExportCommand = guinea.Command{
+	Run:         identityExportFn,
+	Subcommands: nil,
+	Options: []guinea.Option{
+		passphraseFDOption,
+	},
+	Arguments: []guinea.Argument{
+		{
+			Name:        "config_directory",
+			Multiple:    false,
+			Optional:    false,
+			Description: "Path to the directory containing the configuration.",
+		},
+		{
+			Name:        "secret_file",
+			Multiple:    false,
+			Optional:    false,
+			Description: "Path to the secret file which will be created.",
+		},
+	},
+	ShortDescription: "exports the identity to a secret file",
+	Description:      "Exports the identity to a secret file in the format used by ssb-server and go-sbot. The secret file must not exist.",
+}
+
+func identityExportFn(cliContext guinea.Context) error {
+	configDirectory := cliContext.Arguments[0]
+	secretFile := cliContext.Arguments[1]
+
+	config, err := adapters.NewConfigStorage(configDirectory).LoadPaths()
+	if err != nil {
+		return errors.Wrap(err, "error loading config")
+	}
+
+	identityStorage := newIdentityStorage(configDirectory, config)
+
+	iden, err := loadIdentity(identityStorage, cliContext.Options)
+	if err != nil {
+		return errors.Wrap(err, "error loading identity")
+	}
+
+	f, err := os.OpenFile(secretFile, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600)
+	if err != nil {
+		return errors.Wrap(err, "error creating the secret file")
+	}
+	defer f.Close()
+
+	if err := adapters.WriteSSBSecret(f, iden); err != nil {
+		return errors.Wrap(err, "error writing the secret file")
+	}
+
+	return f.Close()
+}
@@ -2,18 +2,70 @@ package main
 
 import (
 	"os"
+	"path/filepath"
+	"strings"
 
 	"github.com/boreq/errors"
 	"github.com/boreq/guinea"
 	"github.com/planetary-social/scuttlego-pub/service"
 	"github.com/planetary-social/scuttlego-pub/service/adapters"
+	"github.com/planetary-social/scuttlego/service/domain/graph"
 	"github.com/planetary-social/scuttlego/service/domain/identity"
+	"github.com/planetary-social/scuttlego/service/domain/transport/boxstream"
+)
+
+const (
+	initOptionForce           = "force"
+	initOptionDataDirectory   = "data-directory"
+	initOptionListenAddresses = "listen-addresses"
+	initOptionPublicAddress   = "public-address"
+	initOptionHops            = "hops"
+	initOptionNetworkKey      = "network-key"
 )
 
 var initCommand = guinea.Command{
 	Run:         initFn,
 	Subcommands: nil,
-	Options:     nil,
+	Options: []guinea.Option{
+		{
+			Name:        initOptionForce,
+			Type:        guinea.Bool,
+			Default:     false,
+			Description: "Overwrite the existing identity and configuration. The old identity is lost.",
+		},
+		{
+			Name:        initOptionDataDirectory,
+			Type:        guinea.String,
+			Default:     "",
+			Description: "Directory for data storage. Defaults to the data directory inside of the config directory.",
+		},
+		{
+			Name:        initOptionListenAddresses,
+			Type:        guinea.String,
+			Default:     strings.Join(service.NewDefaultConfig().ListenAddresses, ","),
+			Description: "Comma separated listen addresses.",
+		},
+		{
+			Name:        initOptionPublicAddress,
+			Type:        guinea.String,
+			Default:     "",
+			Description: "Address under which other peers can reach the pub in the format host:port.",
+		},
+		{
+			Name:        initOptionHops,
+			Type:        guinea.Int,
+			Default:     service.NewDefaultConfig().Hops.Int(),
+			Description: "Distance of replicated feeds in the social graph.",
+		},
+		{
+			Name:        initOptionNetworkKey,
+			Type:        guinea.String,
+			Default:     "",
+			Description: "Network key encoded as hex or base64. Defaults to the Secure Scuttlebutt mainnet network key.",
+		},
+		encryptIdentityOption,
+		passphraseFDOption,
+	},
 	Arguments: []guinea.Argument{
 		{
 			Name:        "config_directory",
@@ -23,7 +75,7 @@ var initCommand = guinea.Command{
 		},
 	},
 	ShortDescription: "initializes the configuration",
-	Description:      "Initializes the configuration in the provided directory.",
+	Description:      "Initializes the configuration and creates a new identity in the provided directory. Existing files are never overwritten unless --force is used.",
 }
 
 func initFn(cliContext guinea.Context) error {
@@ -33,19 +85,47 @@ func initFn(cliContext guinea.Context) error {
 		return errors.Wrap(err, "error checking if directory exists")
 	}
 
+	config, err := newInitConfig(directory, cliContext.Options)
+	if err != nil {
+		return errors.Wrap(err, "error creating the config")
+	}
+
+	if err := config.Validate(); err != nil {
+		return errors.Wrap(err, "error validating config")
+	}
+
+	force := cliContext.Options[initOptionForce].Bool()
+	identityStorage := adapters.NewIdentityStorage(directory)
+	configStorage := adapters.NewConfigStorage(directory)
+
+	if !force {
+		if err := checkConfigDoesNotExist(configStorage); err != nil {
+			return errors.Wrap(err, "refusing to overwrite existing files, use --force to overwrite them")
+		}
+	}
+
 	privateIdentity, err := identity.NewPrivate()
 	if err != nil {
 		return errors.Wrap(err, "error creating private identity")
 	}
 
-	config := service.NewDefaultConfig()
+	encrypt := cliContext.Options[optionEncryptIdentity].Bool()
 
-	identityStorage := adapters.NewIdentityStorage(directory)
-	if err := identityStorage.Save(privateIdentity); err != nil {
+	var passphrase []byte
+	if encrypt {
+		passphrase, err = readPassphrase(cliContext.Options[optionPassphraseFD].Int(), true)
+		if err != nil {
+			return errors.Wrap(err, "error reading the passphrase")
+		}
+	}
+
+	if err := saveNewIdentity(identityStorage, privateIdentity, encrypt, passphrase, force); err != nil {
+		if errors.Is(err, adapters.ErrIdentityExists) {
+			return errors.Wrap(err, "refusing to overwrite existing files, use --force to overwrite them")
+		}
 		return errors.Wrap(err, "error saving identity")
 	}
 
-	configStorage := adapters.NewConfigStorage(directory)
 	if err := configStorage.Save(config); err != nil {
 		return errors.Wrap(err, "error saving config")
 	}
@@ -53,6 +133,77 @@ func initFn(cliContext guinea.Context) error {
 	return nil
 }
 
+func newInitConfig(directory string, options map[string]guinea.OptionValue) (service.Config, error) {
+	config := service.NewDefaultConfig()
+
+	dataDirectory := options[initOptionDataDirectory].Str()
+	if dataDirectory == "" {
+		dataDirectory = filepath.Join(directory, "data")
+	}
+
+	dataDirectory, err := filepath.Abs(dataDirectory)
+	if err != nil {
+		return service.Config{}, errors.Wrap(err, "error determining the absolute path of the data directory")
+	}
+	config.DataDirectory = dataDirectory
+
+	if listenAddresses := options[initOptionListenAddresses].Str(); listenAddresses != "" {
+		config.ListenAddresses = strings.Split(listenAddresses, ",")
+	}
+
+	config.PublicAddress = options[initOptionPublicAddress].Str()
+
+	hops, err := graph.NewHops(options[initOptionHops].Int())
+	if err != nil {
+		return service.Config{}, errors.Wrap(err, "invalid hops")
+	}
+	config.Hops = hops
+
+	if networkKeyString := options[initOptionNetworkKey].Str(); networkKeyString != "" {
+		b, err := adapters.DecodeBytes(networkKeyString)
+		if err != nil {
+			return service.Config{}, errors.Wrap(err, "error decoding the network key")
+		}
+
+		networkKey, err := boxstream.NewNetworkKey(b)
+		if err != nil {
+			return service.Config{}, errors.Wrap(err, "invalid network key")
+		}
+		config.NetworkKey = networkKey
+	}
+
+	return config, nil
+}
+
+// saveNewIdentity creates the identity file only if it doesn't exist unless
+// overwrite is set. The existence of the file isn't checked beforehand so that
+// a file created in the meantime is never replaced.
+func saveNewIdentity(identityStorage *adapters.IdentityStorage, iden identity.Private, encrypt bool, passphrase []byte, overwrite bool) error {
+	switch {
+	case encrypt && overwrite:
+		return identityStorage.SaveEncrypted(iden, passphrase)
+	case encrypt:
+		return identityStorage.CreateEncrypted(iden, passphrase)
+	case overwrite:
+		return identityStorage.Save(iden)
+	default:
+		return identityStorage.Create(iden)
+	}
+}
+
+func checkConfigDoesNotExist(configStorage *adapters.ConfigStorage) error {
+	configExists, err := configStorage.Exists()
+	if err != nil {
+		return errors.Wrap(err, "error checking if the config exists")
+	}
+
+	if configExists {
+		return errors.New("config already exists")
+	}
+
+	return nil
+}
+
 func checkDirectoryExists(directory string) error {
 	stat, err := os.Stat(directory)
 	if err != nil {
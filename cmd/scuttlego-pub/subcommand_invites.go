@@ -0,0 +1,102 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/boreq/errors"
+	"github.com/boreq/guinea"
+	"github.com/planetary-social/scuttlego-pub/internal"
+	"github.com/planetary-social/scuttlego-pub/service/ports/admin"
+)
+
+const (
+	invitesCreateOptionUses     = "uses"
+	invitesCreateOptionValidFor = "valid-for"
+)
+
+var invitesCommand = guinea.Command{
+	Run: nil,
+	Subcommands: map[string]*guinea.Command{
+		"create": &invitesCreateCommand,
+	},
+	Options:          nil,
+	Arguments:        nil,
+	ShortDescription: "manages invites",
+	Description:      "Commands used to manage invites which let new members join the pub. The pub must be running.",
+}
+
+var invitesCreateCommand = guinea.Command{
+	Run:         invitesCreateFn,
+	Subcommands: nil,
+	Options: []guinea.Option{
+		{
+			Name:        invitesCreateOptionUses,
+			Type:        guinea.Int,
+			Default:     1,
+			Description: "Number of times the invite can be used. Set to 0 to create an invite which can be used any number of times.",
+		},
+		{
+			Name:        invitesCreateOptionValidFor,
+			Type:        guinea.String,
+			Default:     "",
+			Description: "Duration after which the invite expires, for example 72h. By default the invite doesn't expire.",
+		},
+	},
+	Arguments: []guinea.Argument{
+		{
+			Name:        "config_directory",
+			Multiple:    false,
+			Optional:    false,
+			Description: "Path to the directory containing the configuration.",
+		},
+	},
+	ShortDescription: "creates an invite",
+	Description:      "Creates an invite and prints the invite code. If the public address of the pub isn't configured only the secret key seed of the invite is printed.",
+}
+
+func invitesCreateFn(cliContext guinea.Context) error {
+	configDirectory := cliContext.Arguments[0]
+
+	var params admin.CreateInviteParams
+
+	uses := cliContext.Options[invitesCreateOptionUses].Int()
+	if uses < 0 {
+		return errors.New("number of uses can't be negative")
+	}
+	if uses > 0 {
+		params.NumberOfUses = internal.Pointer(uses)
+	}
+
+	if validFor := cliContext.Options[invitesCreateOptionValidFor].Str(); validFor != "" {
+		duration, err := time.ParseDuration(validFor)
+		if err != nil {
+			return errors.Wrap(err, "error parsing the duration")
+		}
+		if duration <= 0 {
+			return errors.New("duration must be positive")
+		}
+		params.ValidUntil = internal.Pointer(time.Now().Add(duration))
+	}
+
+	client, err := newAdminClient(configDirectory)
+	if err != nil {
+		return errors.Wrap(err, "error creating the admin client")
+	}
+
+	ctx, cancel := newAdminRequestContext()
+	defer cancel()
+
+	result, err := client.CreateInvite(ctx, params)
+	if err != nil {
+		return errors.Wrap(err, "error creating the invite")
+	}
+
+	if result.InviteCode == "" {
+		fmt.Printf("public address isn't configured, secret key seed of the invite: %s\n", result.SecretKeySeed)
+		return nil
+	}
+
+	fmt.Println(result.InviteCode)
+	return nil
+}
@@ -0,0 +1,190 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/boreq/errors"
+	"github.com/boreq/guinea"
+	"github.com/planetary-social/scuttlego/service/domain/refs"
+)
+
+const peersDisconnectOptionAll = "all"
+
+var peersCommand = guinea.Command{
+	Run: nil,
+	Subcommands: map[string]*guinea.Command{
+		"connect":    &peersConnectCommand,
+		"disconnect": &peersDisconnectCommand,
+		"list":       &peersListCommand,
+	},
+	Options:          nil,
+	Arguments:        nil,
+	ShortDescription: "manages connected peers",
+	Description:      "Commands used to manage peers which the pub is connected to. The pub must be running.",
+}
+
+var peersConnectCommand = guinea.Command{
+	Run:         peersConnectFn,
+	Subcommands: nil,
+	Options:     nil,
+	Arguments: []guinea.Argument{
+		{
+			Name:        "config_directory",
+			Multiple:    false,
+			Optional:    false,
+			Description: "Path to the directory containing the configuration.",
+		},
+		{
+			Name:        "address",
+			Multiple:    false,
+			Optional:    false,
+			Description: "Multiserver address, for example net:example.com:8008~shs:CIlwTOK+m6v1hT2zUVOCJvvZq7KE/65ErN6yA2yrURY=.",
+		},
+	},
+	ShortDescription: "connects to a peer",
+	Description:      "Connects to a peer. Nothing happens if the pub is already connected to the peer. The peer isn't reconnected to if the connection is lost.",
+}
+
+func peersConnectFn(cliContext guinea.Context) error {
+	configDirectory := cliContext.Arguments[0]
+	address := cliContext.Arguments[1]
+
+	client, err := newAdminClient(configDirectory)
+	if err != nil {
+		return errors.Wrap(err, "error creating the admin client")
+	}
+
+	ctx, cancel := newAdminRequestContext()
+	defer cancel()
+
+	if err := client.Connect(ctx, address); err != nil {
+		return errors.Wrap(err, "error connecting to the peer")
+	}
+
+	fmt.Printf("connected to %s\n", address)
+	return nil
+}
+
+var peersDisconnectCommand = guinea.Command{
+	Run:         peersDisconnectFn,
+	Subcommands: nil,
+	Options: []guinea.Option{
+		{
+			Name:        peersDisconnectOptionAll,
+			Type:        guinea.Bool,
+			Default:     false,
+			Description: "Disconnect from all peers instead of the specified one.",
+		},
+	},
+	Arguments: []guinea.Argument{
+		{
+			Name:        "config_directory",
+			Multiple:    false,
+			Optional:    false,
+			Description: "Path to the directory containing the configuration.",
+		},
+		{
+			Name:        "feed",
+			Multiple:    false,
+			Optional:    true,
+			Description: "Feed of the peer, for example @CIlwTOK+m6v1hT2zUVOCJvvZq7KE/65ErN6yA2yrURY=.ed25519. Must not be provided together with --all.",
+		},
+	},
+	ShortDescription: "disconnects from a peer",
+	Description:      "Closes all connections to a peer or, if --all is set, to all peers. Preferred peers will be reconnected to later.",
+}
+
+func peersDisconnectFn(cliContext guinea.Context) error {
+	configDirectory := cliContext.Arguments[0]
+	all := cliContext.Options[peersDisconnectOptionAll].Bool()
+
+	if all {
+		if len(cliContext.Arguments) > 1 {
+			return errors.New("the feed must not be provided together with --all")
+		}
+		return peersDisconnectAll(configDirectory)
+	}
+
+	if len(cliContext.Arguments) < 2 {
+		return errors.New("provide the feed of the peer or use --all to disconnect from all peers")
+	}
+
+	feed, err := refs.NewFeed(cliContext.Arguments[1])
+	if err != nil {
+		return errors.Wrap(err, "invalid feed")
+	}
+
+	client, err := newAdminClient(configDirectory)
+	if err != nil {
+		return errors.Wrap(err, "error creating the admin client")
+	}
+
+	ctx, cancel := newAdminRequestContext()
+	defer cancel()
+
+	if err := client.Disconnect(ctx, feed); err != nil {
+		return errors.Wrap(err, "error disconnecting from the peer")
+	}
+
+	fmt.Printf("disconnected from %s\n", feed)
+	return nil
+}
+
+func peersDisconnectAll(configDirectory string) error {
+	client, err := newAdminClient(configDirectory)
+	if err != nil {
+		return errors.Wrap(err, "error creating the admin client")
+	}
+
+	ctx, cancel := newAdminRequestContext()
+	defer cancel()
+
+	if err := client.DisconnectAll(ctx); err != nil {
+		return errors.Wrap(err, "error disconnecting from all peers")
+	}
+
+	fmt.Println("disconnected from all peers")
+	return nil
+}
+
+var peersListCommand = guinea.Command{
+	Run:         peersListFn,
+	Subcommands: nil,
+	Options:     nil,
+	Arguments: []guinea.Argument{
+		{
+			Name:        "config_directory",
+			Multiple:    false,
+			Optional:    false,
+			Description: "Path to the directory containing the configuration.",
+		},
+	},
+	ShortDescription: "lists connected peers",
+	Description:      "Lists peers which the pub is connected to.",
+}
+
+func peersListFn(cliContext guinea.Context) error {
+	configDirectory := cliContext.Arguments[0]
+
+	client, err := newAdminClient(configDirectory)
+	if err != nil {
+		return errors.Wrap(err, "error creating the admin client")
+	}
+
+	ctx, cancel := newAdminRequestContext()
+	defer cancel()
+
+	result, err := client.ListPeers(ctx)
+	if err != nil {
+		return errors.Wrap(err, "error listing the peers")
+	}
+
+	if len(result.Peers) == 0 {
+		fmt.Println("no connected peers")
+		return nil
+	}
+
+	return printPeers(os.Stdout, result.Peers, time.Now())
+}
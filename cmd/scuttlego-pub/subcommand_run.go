@@ -2,9 +2,14 @@ package main
 
 import (
 	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
 
 	"github.com/boreq/errors"
 	"github.com/boreq/guinea"
+	"github.com/planetary-social/scuttlego-pub/service"
 	"github.com/planetary-social/scuttlego-pub/service/adapters"
 	"github.com/planetary-social/scuttlego-pub/service/di"
 )
@@ -12,7 +17,9 @@ import (
 var runCommand = guinea.Command{
 	Run:         runFn,
 	Subcommands: nil,
-	Options:     nil,
+	Options: []guinea.Option{
+		passphraseFDOption,
+	},
 	Arguments: []guinea.Argument{
 		{
 			Name:        "config_directory",
@@ -22,7 +29,7 @@ var runCommand = guinea.Command{
 		},
 	},
 	ShortDescription: "runs the pub",
-	Description:      "Runs the pub with the provided configuration.",
+	Description:      "Runs the pub with the provided configuration. If the identity is encrypted the passphrase is read from the provided file descriptor, the environment variable or a prompt. Sending SIGHUP reloads the fields of the configuration which can be changed without restarting the pub. Sending SIGINT or SIGTERM stops the pub gracefully. New connections are no longer accepted and existing ones are given up to the configured drain period to finish replication. The database is closed once everything else stopped. Sending either of them again exits immediately.",
 }
 
 func runFn(cliContext guinea.Context) error {
@@ -31,32 +38,134 @@ func runFn(cliContext guinea.Context) error {
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
-	identityStorage := adapters.NewIdentityStorage(configDirectory)
 	configStorage := adapters.NewConfigStorage(configDirectory)
 
-	iden, err := identityStorage.Load()
-	if err != nil {
-		return errors.Wrap(err, "error loading identity")
-	}
+	reloadConfigOnSighup := handleSighup(ctx, configStorage)
 
 	config, err := configStorage.Load()
 	if err != nil {
 		return errors.Wrap(err, "error loading config")
 	}
 
+	if err := config.Validate(); err != nil {
+		return errors.Wrap(err, "error validating config")
+	}
+
+	identityStorage := newIdentityStorage(configDirectory, config)
+
+	iden, err := loadIdentity(identityStorage, cliContext.Options)
+	if err != nil {
+		return errors.Wrap(err, "error loading identity")
+	}
+
+	stopHandlingShutdownSignals := cancelOnShutdownSignal(cancel)
+	defer stopHandlingShutdownSignals()
+
 	service, cleanup, err := di.BuildService(iden, config)
 	if err != nil {
 		return errors.Wrap(err, "error building the service")
 	}
 	defer cleanup()
 
+	if err := service.StartHTTPServer(ctx); err != nil {
+		return errors.Wrap(err, "error starting the http server")
+	}
+
 	if err := service.RunMigrations(ctx); err != nil {
 		return errors.Wrap(err, "error running migrations")
 	}
 
+	reloadConfigOnSighup(service)
+
 	if err := service.Run(ctx); err != nil {
 		return errors.Wrap(err, "error running the service")
 	}
 
 	return nil
 }
+
+// cancelOnShutdownSignal cancels the context once SIGINT or SIGTERM is
+// received so that the pub can stop gracefully. Receiving one of them again
+// exits immediately. The returned function stops handling the signals.
+func cancelOnShutdownSignal(cancel context.CancelFunc) func() {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGINT, syscall.SIGTERM)
+
+	done := make(chan struct{})
+
+	go func() {
+		select {
+		case sig := <-signals:
+			fmt.Fprintf(os.Stderr, "received %s, stopping the pub, send it again to exit immediately\n", sig)
+			cancel()
+		case <-done:
+			return
+		}
+
+		select {
+		case sig := <-signals:
+			fmt.Fprintf(os.Stderr, "received %s again, exiting immediately\n", sig)
+			os.Exit(1)
+		case <-done:
+			return
+		}
+	}()
+
+	return func() {
+		signal.Stop(signals)
+		close(done)
+	}
+}
+
+// handleSighup starts handling SIGHUP immediately so that receiving it while
+// the pub is starting doesn't kill it. The config is reloaded every time
+// SIGHUP is received once the returned function is called with the started
+// service. Signals received earlier are ignored. SIGHUP is handled until the
+// context is cancelled.
+func handleSighup(ctx context.Context, configStorage *adapters.ConfigStorage) func(svc service.Service) {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGHUP)
+
+	services := make(chan service.Service, 1)
+
+	go func() {
+		defer signal.Stop(signals)
+
+		var svc *service.Service
+
+		for {
+			select {
+			case v := <-services:
+				svc = &v
+			case <-signals:
+				if svc == nil {
+					fmt.Fprintln(os.Stderr, "received SIGHUP while the pub is starting, ignoring it")
+					continue
+				}
+
+				if err := reloadConfig(configStorage, *svc); err != nil {
+					fmt.Fprintf(os.Stderr, "error reloading config: %s\n", err)
+				}
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return func(svc service.Service) {
+		services <- svc
+	}
+}
+
+func reloadConfig(configStorage *adapters.ConfigStorage, svc service.Service) error {
+	config, err := configStorage.Load()
+	if err != nil {
+		return errors.Wrap(err, "error loading config")
+	}
+
+	if err := config.Validate(); err != nil {
+		return errors.Wrap(err, "error validating config")
+	}
+
+	return svc.ReloadConfig(config)
+}
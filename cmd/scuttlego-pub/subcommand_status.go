@@ -0,0 +1,138 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/boreq/errors"
+	"github.com/boreq/guinea"
+	"github.com/planetary-social/scuttlego-pub/service/ports/admin"
+)
+
+var statusCommand = guinea.Command{
+	Run:         statusFn,
+	Subcommands: nil,
+	Options:     nil,
+	Arguments: []guinea.Argument{
+		{
+			Name:        "config_directory",
+			Multiple:    false,
+			Optional:    false,
+			Description: "Path to the directory containing the configuration.",
+		},
+	},
+	ShortDescription: "displays the status of the pub",
+	Description:      "Displays the identity, addresses, uptime, connected peers, stored data and invite counts of the running pub. Invites are counted since the pub was started.",
+}
+
+func statusFn(cliContext guinea.Context) error {
+	configDirectory := cliContext.Arguments[0]
+
+	client, err := newAdminClient(configDirectory)
+	if err != nil {
+		return errors.Wrap(err, "error creating the admin client")
+	}
+
+	ctx, cancel := newAdminRequestContext()
+	defer cancel()
+
+	status, err := client.Status(ctx)
+	if err != nil {
+		return errors.Wrap(err, "error getting the status")
+	}
+
+	return printStatus(os.Stdout, status, time.Now())
+}
+
+func printStatus(w io.Writer, status admin.StatusResult, now time.Time) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+
+	publicAddress := status.PublicAddress
+	if publicAddress == "" {
+		publicAddress = "not configured"
+	}
+
+	fmt.Fprintf(tw, "identity:\t%s\n", status.Identity)
+	fmt.Fprintf(tw, "listen addresses:\t%s\n", strings.Join(status.ListenAddresses, ", "))
+	fmt.Fprintf(tw, "public address:\t%s\n", publicAddress)
+	fmt.Fprintf(tw, "uptime:\t%s\n", formatDuration(now.Sub(status.StartedAt)))
+	fmt.Fprintf(tw, "feeds:\t%d\n", status.NumberOfFeeds)
+	fmt.Fprintf(tw, "messages:\t%d\n", status.NumberOfMessages)
+	fmt.Fprintf(tw, "blob storage:\t%s\n", formatBlobStorageBytes(status.BlobStorageBytes))
+	fmt.Fprintf(tw, "invites:\t%d created, %d redeemed, %d rejected\n", status.Invites.Created, status.Invites.Redeemed, status.Invites.Rejected)
+	fmt.Fprintf(tw, "connected peers:\t%d\n", len(status.Peers))
+
+	if err := tw.Flush(); err != nil {
+		return errors.Wrap(err, "error writing the status")
+	}
+
+	if len(status.Peers) == 0 {
+		return nil
+	}
+
+	fmt.Fprintln(w)
+
+	return printPeers(w, status.Peers, now)
+}
+
+func printPeers(w io.Writer, peers []admin.PeerStatus, now time.Time) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "PEER\tADDRESS\tDIRECTION\tCONNECTED FOR")
+	for _, peer := range peers {
+		address, direction, connectedFor := "unknown", "unknown", "unknown"
+		if peer.Connection != nil {
+			if peer.Connection.Address != "" {
+				address = peer.Connection.Address
+			}
+			direction = formatDirection(peer.Connection.InitiatedByRemote)
+			connectedFor = formatDuration(now.Sub(peer.Connection.ConnectedAt))
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", peer.Identity, address, direction, connectedFor)
+	}
+
+	if err := tw.Flush(); err != nil {
+		return errors.Wrap(err, "error writing the peers")
+	}
+
+	return nil
+}
+
+func formatDirection(initiatedByRemote bool) string {
+	if initiatedByRemote {
+		return "incoming"
+	}
+	return "outgoing"
+}
+
+func formatDuration(d time.Duration) string {
+	return d.Round(time.Second).String()
+}
+
+// formatBlobStorageBytes handles the size of the blob storage not being
+// determined yet.
+func formatBlobStorageBytes(bytes *int64) string {
+	if bytes == nil {
+		return "unknown"
+	}
+	return formatBytes(*bytes)
+}
+
+func formatBytes(bytes int64) string {
+	const unit = 1024
+
+	if bytes < unit {
+		return fmt.Sprintf("%d B", bytes)
+	}
+
+	div, exp := int64(unit), 0
+	for n := bytes / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+
+	return fmt.Sprintf("%.1f %ciB", float64(bytes)/float64(div), "KMGTPE"[exp])
+}
@@ -66,6 +66,10 @@ func SomeDuration() time.Duration {
 }
 
 func SomeMessageWithFeedSequence(feed refs.Feed, sequence message.Sequence) message.Message {
+	return SomeMessageWithFeedSequenceContent(feed, sequence, SomeContent())
+}
+
+func SomeMessageWithFeedSequenceContent(feed refs.Feed, sequence message.Sequence, content message.Content) message.Message {
 	var previous *refs.Message
 	if !sequence.IsFirst() {
 		tmp := SomeRefMessage()
@@ -79,7 +83,7 @@ func SomeMessageWithFeedSequence(feed refs.Feed, sequence message.Sequence) mess
 		SomeRefIdentity(),
 		feed,
 		SomeTime(),
-		SomeContent(),
+		content,
 		SomeRawMessage(),
 	)
 }
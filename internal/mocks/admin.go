@@ -0,0 +1,185 @@
+package mocks
+
+import (
+	"context"
+
+	"sync"
+
+	pubcommands "github.com/planetary-social/scuttlego-pub/service/app/commands"
+	pubqueries "github.com/planetary-social/scuttlego-pub/service/app/queries"
+	"github.com/planetary-social/scuttlego-pub/service/domain"
+	"github.com/planetary-social/scuttlego/service/app/commands"
+)
+
+// Admin handler mocks are called by the admin server from other goroutines so
+// they are safe for concurrent use.
+
+type CreateInviteHandlerMock struct {
+	HandleReturnValue domain.SecretKeySeed
+
+	lock        sync.Mutex
+	handleCalls []pubcommands.CreateInvite
+}
+
+func NewCreateInviteHandlerMock() *CreateInviteHandlerMock {
+	return &CreateInviteHandlerMock{}
+}
+
+func (c *CreateInviteHandlerMock) Handle(cmd pubcommands.CreateInvite) (domain.SecretKeySeed, error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.handleCalls = append(c.handleCalls, cmd)
+	return c.HandleReturnValue, nil
+}
+
+func (c *CreateInviteHandlerMock) HandleCalls() []pubcommands.CreateInvite {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return append([]pubcommands.CreateInvite{}, c.handleCalls...)
+}
+
+type AddToBanListHandlerMock struct {
+	lock        sync.Mutex
+	handleCalls []commands.AddToBanList
+}
+
+func NewAddToBanListHandlerMock() *AddToBanListHandlerMock {
+	return &AddToBanListHandlerMock{}
+}
+
+func (a *AddToBanListHandlerMock) Handle(cmd commands.AddToBanList) error {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+	a.handleCalls = append(a.handleCalls, cmd)
+	return nil
+}
+
+func (a *AddToBanListHandlerMock) HandleCalls() []commands.AddToBanList {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+	return append([]commands.AddToBanList{}, a.handleCalls...)
+}
+
+type RemoveFromBanListHandlerMock struct {
+	lock        sync.Mutex
+	handleCalls []commands.RemoveFromBanList
+}
+
+func NewRemoveFromBanListHandlerMock() *RemoveFromBanListHandlerMock {
+	return &RemoveFromBanListHandlerMock{}
+}
+
+func (r *RemoveFromBanListHandlerMock) Handle(cmd commands.RemoveFromBanList) error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.handleCalls = append(r.handleCalls, cmd)
+	return nil
+}
+
+func (r *RemoveFromBanListHandlerMock) HandleCalls() []commands.RemoveFromBanList {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	return append([]commands.RemoveFromBanList{}, r.handleCalls...)
+}
+
+type StatusHandlerMock struct {
+	HandleReturnValue pubqueries.Status
+}
+
+func NewStatusHandlerMock() *StatusHandlerMock {
+	return &StatusHandlerMock{}
+}
+
+func (s *StatusHandlerMock) Handle() (pubqueries.Status, error) {
+	return s.HandleReturnValue, nil
+}
+
+type ConnectHandlerMock struct {
+	lock        sync.Mutex
+	handleCalls []commands.Connect
+	handleCtxs  []context.Context
+}
+
+func NewConnectHandlerMock() *ConnectHandlerMock {
+	return &ConnectHandlerMock{}
+}
+
+func (c *ConnectHandlerMock) Handle(ctx context.Context, cmd commands.Connect) error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.handleCalls = append(c.handleCalls, cmd)
+	c.handleCtxs = append(c.handleCtxs, ctx)
+	return nil
+}
+
+func (c *ConnectHandlerMock) HandleCtxs() []context.Context {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return append([]context.Context{}, c.handleCtxs...)
+}
+
+func (c *ConnectHandlerMock) HandleCalls() []commands.Connect {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return append([]commands.Connect{}, c.handleCalls...)
+}
+
+type DisconnectHandlerMock struct {
+	HandleReturnValue error
+
+	lock        sync.Mutex
+	handleCalls []pubcommands.Disconnect
+}
+
+func NewDisconnectHandlerMock() *DisconnectHandlerMock {
+	return &DisconnectHandlerMock{}
+}
+
+func (d *DisconnectHandlerMock) Handle(cmd pubcommands.Disconnect) error {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	d.handleCalls = append(d.handleCalls, cmd)
+	return d.HandleReturnValue
+}
+
+func (d *DisconnectHandlerMock) HandleCalls() []pubcommands.Disconnect {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	return append([]pubcommands.Disconnect{}, d.handleCalls...)
+}
+
+type DisconnectAllHandlerMock struct {
+	HandleReturnValue error
+
+	lock        sync.Mutex
+	handleCalls int
+}
+
+func NewDisconnectAllHandlerMock() *DisconnectAllHandlerMock {
+	return &DisconnectAllHandlerMock{}
+}
+
+func (d *DisconnectAllHandlerMock) Handle() error {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	d.handleCalls++
+	return d.HandleReturnValue
+}
+
+func (d *DisconnectAllHandlerMock) HandleCalls() int {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	return d.handleCalls
+}
+
+type ConnectedPeersHandlerMock struct {
+	HandleReturnValue []pubqueries.PeerStatus
+}
+
+func NewConnectedPeersHandlerMock() *ConnectedPeersHandlerMock {
+	return &ConnectedPeersHandlerMock{}
+}
+
+func (c *ConnectedPeersHandlerMock) Handle() []pubqueries.PeerStatus {
+	return c.HandleReturnValue
+}
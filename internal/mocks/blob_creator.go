@@ -0,0 +1,29 @@
+package mocks
+
+import (
+	"io"
+
+	"github.com/boreq/errors"
+	"github.com/planetary-social/scuttlego/service/domain/refs"
+)
+
+type BlobCreatorMock struct {
+	CreateCalls       [][]byte
+	CreateReturnValue refs.Blob
+}
+
+func NewBlobCreatorMock() *BlobCreatorMock {
+	return &BlobCreatorMock{}
+}
+
+func (m *BlobCreatorMock) Create(r io.Reader) (refs.Blob, error) {
+	b, err := io.ReadAll(r)
+	if err != nil {
+		return refs.Blob{}, errors.Wrap(err, "error reading")
+	}
+	m.CreateCalls = append(m.CreateCalls, b)
+	if m.CreateReturnValue.IsZero() {
+		panic("zero value")
+	}
+	return m.CreateReturnValue, nil
+}
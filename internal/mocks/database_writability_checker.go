@@ -0,0 +1,15 @@
+package mocks
+
+type DatabaseWritabilityCheckerMock struct {
+	CheckWritableReturnValue error
+	CheckWritableCalls       int
+}
+
+func NewDatabaseWritabilityCheckerMock() *DatabaseWritabilityCheckerMock {
+	return &DatabaseWritabilityCheckerMock{}
+}
+
+func (d *DatabaseWritabilityCheckerMock) CheckWritable() error {
+	d.CheckWritableCalls++
+	return d.CheckWritableReturnValue
+}
@@ -2,8 +2,11 @@ package mocks
 
 import (
 	"github.com/boreq/errors"
+	"github.com/planetary-social/scuttlego-pub/internal"
 	"github.com/planetary-social/scuttlego/service/app/commands"
+	"github.com/planetary-social/scuttlego/service/app/common"
 	"github.com/planetary-social/scuttlego/service/domain/feeds"
+	"github.com/planetary-social/scuttlego/service/domain/feeds/message"
 	"github.com/planetary-social/scuttlego/service/domain/refs"
 )
 
@@ -11,6 +14,7 @@ type FeedRepositoryMock struct {
 	UpdateFeedResults []FeedRepositoryMockUpdateFeedCall
 
 	feedFormat *FeedFormatMock
+	messages   []message.Message
 }
 
 func NewFeedRepositoryMock(feedFormat *FeedFormatMock) *FeedRepositoryMock {
@@ -31,6 +35,49 @@ func (m *FeedRepositoryMock) UpdateFeed(ref refs.Feed, fn commands.UpdateFeedFn)
 	return nil
 }
 
+func (m *FeedRepositoryMock) MockMessages(msgs []message.Message) {
+	m.messages = append(m.messages, msgs...)
+}
+
+func (m *FeedRepositoryMock) GetMessages(id refs.Feed, seq *message.Sequence, limit *int) ([]message.Message, error) {
+	var result []message.Message
+	for _, msg := range m.messages {
+		if !msg.Feed().Equal(id) {
+			continue
+		}
+
+		if seq != nil && seq.ComesAfter(msg.Sequence()) {
+			continue
+		}
+
+		if limit != nil && len(result) >= *limit {
+			break
+		}
+
+		result = append(result, msg)
+	}
+	return result, nil
+}
+
+func (m *FeedRepositoryMock) GetSequence(ref refs.Feed) (message.Sequence, error) {
+	var result *message.Sequence
+	for _, msg := range m.messages {
+		if !msg.Feed().Equal(ref) {
+			continue
+		}
+
+		if result == nil || msg.Sequence().ComesAfter(*result) {
+			result = internal.Pointer(msg.Sequence())
+		}
+	}
+
+	if result == nil {
+		return message.Sequence{}, common.ErrFeedNotFound
+	}
+
+	return *result, nil
+}
+
 type FeedRepositoryMockUpdateFeedCall struct {
 	Id     refs.Feed
 	Result *feeds.Feed
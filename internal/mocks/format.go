@@ -2,13 +2,18 @@ package mocks
 
 import (
 	"github.com/boreq/errors"
+	"github.com/planetary-social/scuttlego-pub/internal/fixtures"
 	"github.com/planetary-social/scuttlego/service/domain/feeds"
 	"github.com/planetary-social/scuttlego/service/domain/feeds/message"
 	"github.com/planetary-social/scuttlego/service/domain/identity"
 )
 
 type FeedFormatMock struct {
-	SignCalls       []FeedFormatMockSignCall
+	SignCalls []FeedFormatMockSignCall
+
+	// SignReturnValue is returned by Sign. If it isn't set then Sign returns
+	// a message built from the fields of the unsigned message which makes it
+	// possible to create multiple messages in a row.
 	SignReturnValue message.Message
 }
 
@@ -29,7 +34,20 @@ func (f *FeedFormatMock) Sign(unsigned message.UnsignedMessage, private identity
 		Unsigned: unsigned,
 		Private:  private,
 	})
-	return f.SignReturnValue, nil
+	if !f.SignReturnValue.IsZero() {
+		return f.SignReturnValue, nil
+	}
+
+	return message.NewMessage(
+		fixtures.SomeRefMessage(),
+		unsigned.Previous(),
+		unsigned.Sequence(),
+		unsigned.Author(),
+		unsigned.Feed(),
+		unsigned.Timestamp(),
+		message.MustNewContent(unsigned.Content(), nil, nil),
+		fixtures.SomeRawMessage(),
+	)
 }
 
 func (f *FeedFormatMock) Peek(raw message.RawMessage) (feeds.PeekedMessage, error) {
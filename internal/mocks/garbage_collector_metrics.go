@@ -0,0 +1,27 @@
+package mocks
+
+import "sync"
+
+type GarbageCollectorMetricsMock struct {
+	lock sync.Mutex
+	runs []error
+}
+
+func NewGarbageCollectorMetricsMock() *GarbageCollectorMetricsMock {
+	return &GarbageCollectorMetricsMock{}
+}
+
+func (m *GarbageCollectorMetricsMock) BadgerGarbageCollectionRun(err error) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	m.runs = append(m.runs, err)
+}
+
+// Runs returns the errors with which BadgerGarbageCollectionRun was called.
+func (m *GarbageCollectorMetricsMock) Runs() []error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	return append([]error{}, m.runs...)
+}
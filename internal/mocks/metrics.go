@@ -0,0 +1,42 @@
+package mocks
+
+import "sync"
+
+type MetricsMockInvites struct {
+	Created  int
+	Redeemed int
+	Rejected int
+}
+
+type MetricsMock struct {
+	lock    sync.Mutex
+	invites MetricsMockInvites
+}
+
+func NewMetricsMock() *MetricsMock {
+	return &MetricsMock{}
+}
+
+func (m *MetricsMock) InviteCreated() {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	m.invites.Created++
+}
+
+func (m *MetricsMock) InviteRedeemed() {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	m.invites.Redeemed++
+}
+
+func (m *MetricsMock) InviteRejected() {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	m.invites.Rejected++
+}
+
+func (m *MetricsMock) Invites() MetricsMockInvites {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	return m.invites
+}
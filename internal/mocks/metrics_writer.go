@@ -0,0 +1,19 @@
+package mocks
+
+import "io"
+
+type MetricsWriterMock struct {
+	Metrics                 string
+	WriteMetricsReturnValue error
+}
+
+func NewMetricsWriterMock() *MetricsWriterMock {
+	return &MetricsWriterMock{}
+}
+
+func (m *MetricsWriterMock) WriteMetrics(w io.Writer) error {
+	if _, err := io.WriteString(w, m.Metrics); err != nil {
+		return err
+	}
+	return m.WriteMetricsReturnValue
+}
@@ -0,0 +1,36 @@
+package mocks
+
+import (
+	"context"
+	"io"
+
+	"github.com/planetary-social/scuttlego/service/domain/identity"
+	"github.com/planetary-social/scuttlego/service/domain/transport"
+)
+
+type PeerInitializerMock struct {
+	InitializeReturnValue transport.Peer
+	InitializeReturnError error
+
+	// Rwcs contains the connections passed to the initializer.
+	Rwcs []io.ReadWriteCloser
+
+	// Ctxs contains the contexts passed to the initializer.
+	Ctxs []context.Context
+}
+
+func NewPeerInitializerMock() *PeerInitializerMock {
+	return &PeerInitializerMock{}
+}
+
+func (p *PeerInitializerMock) InitializeServerPeer(ctx context.Context, rwc io.ReadWriteCloser) (transport.Peer, error) {
+	p.Rwcs = append(p.Rwcs, rwc)
+	p.Ctxs = append(p.Ctxs, ctx)
+	return p.InitializeReturnValue, p.InitializeReturnError
+}
+
+func (p *PeerInitializerMock) InitializeClientPeer(ctx context.Context, rwc io.ReadWriteCloser, remote identity.Public) (transport.Peer, error) {
+	p.Rwcs = append(p.Rwcs, rwc)
+	p.Ctxs = append(p.Ctxs, ctx)
+	return p.InitializeReturnValue, p.InitializeReturnError
+}
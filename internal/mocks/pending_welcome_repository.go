@@ -0,0 +1,34 @@
+package mocks
+
+import (
+	"github.com/planetary-social/scuttlego-pub/service/domain"
+	"github.com/planetary-social/scuttlego/service/domain/refs"
+)
+
+type PendingWelcomeRepositoryMock struct {
+	welcomes map[string]domain.PendingWelcome
+}
+
+func NewPendingWelcomeRepositoryMock() *PendingWelcomeRepositoryMock {
+	return &PendingWelcomeRepositoryMock{
+		welcomes: make(map[string]domain.PendingWelcome),
+	}
+}
+
+func (p *PendingWelcomeRepositoryMock) Put(welcome domain.PendingWelcome) error {
+	p.welcomes[welcome.Feed().String()] = welcome
+	return nil
+}
+
+func (p *PendingWelcomeRepositoryMock) List() ([]domain.PendingWelcome, error) {
+	var result []domain.PendingWelcome
+	for _, welcome := range p.welcomes {
+		result = append(result, welcome)
+	}
+	return result, nil
+}
+
+func (p *PendingWelcomeRepositoryMock) Delete(feed refs.Feed) error {
+	delete(p.welcomes, feed.String())
+	return nil
+}
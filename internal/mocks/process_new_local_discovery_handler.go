@@ -0,0 +1,20 @@
+package mocks
+
+import (
+	"context"
+
+	"github.com/planetary-social/scuttlego/service/app/commands"
+)
+
+type ProcessNewLocalDiscoveryHandlerMock struct {
+	HandleCalls []commands.ProcessNewLocalDiscovery
+}
+
+func NewProcessNewLocalDiscoveryHandlerMock() *ProcessNewLocalDiscoveryHandlerMock {
+	return &ProcessNewLocalDiscoveryHandlerMock{}
+}
+
+func (p *ProcessNewLocalDiscoveryHandlerMock) Handle(ctx context.Context, cmd commands.ProcessNewLocalDiscovery) error {
+	p.HandleCalls = append(p.HandleCalls, cmd)
+	return nil
+}
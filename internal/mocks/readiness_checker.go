@@ -0,0 +1,13 @@
+package mocks
+
+type ReadinessCheckerMock struct {
+	CheckReturnValue error
+}
+
+func NewReadinessCheckerMock() *ReadinessCheckerMock {
+	return &ReadinessCheckerMock{}
+}
+
+func (r *ReadinessCheckerMock) Check() error {
+	return r.CheckReturnValue
+}
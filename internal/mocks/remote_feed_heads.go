@@ -0,0 +1,25 @@
+package mocks
+
+import (
+	"github.com/planetary-social/scuttlego/service/domain/feeds/message"
+	"github.com/planetary-social/scuttlego/service/domain/refs"
+)
+
+type RemoteFeedHeadsMock struct {
+	heads map[string]message.Sequence
+}
+
+func NewRemoteFeedHeadsMock() *RemoteFeedHeadsMock {
+	return &RemoteFeedHeadsMock{
+		heads: make(map[string]message.Sequence),
+	}
+}
+
+func (m *RemoteFeedHeadsMock) Mock(feed refs.Feed, sequence message.Sequence) {
+	m.heads[feed.String()] = sequence
+}
+
+func (m *RemoteFeedHeadsMock) Get(feed refs.Feed) (message.Sequence, bool) {
+	sequence, ok := m.heads[feed.String()]
+	return sequence, ok
+}
@@ -0,0 +1,57 @@
+package mocks
+
+import (
+	pubqueries "github.com/planetary-social/scuttlego-pub/service/app/queries"
+	"github.com/planetary-social/scuttlego/service/app/queries"
+)
+
+type StatusQueryHandlerMock struct {
+	HandleReturnValue queries.StatusResult
+	HandleCalls       int
+}
+
+func NewStatusQueryHandlerMock() *StatusQueryHandlerMock {
+	return &StatusQueryHandlerMock{}
+}
+
+func (s *StatusQueryHandlerMock) Handle() (queries.StatusResult, error) {
+	s.HandleCalls++
+	return s.HandleReturnValue, nil
+}
+
+type ConnectionTrackerMock struct {
+	ConnectionsReturnValue []pubqueries.Connection
+}
+
+func NewConnectionTrackerMock() *ConnectionTrackerMock {
+	return &ConnectionTrackerMock{}
+}
+
+func (c *ConnectionTrackerMock) Connections() []pubqueries.Connection {
+	return c.ConnectionsReturnValue
+}
+
+type BlobStorageUsageMock struct {
+	SizeReturnValue int64
+	SizeReturnErr   error
+}
+
+func NewBlobStorageUsageMock() *BlobStorageUsageMock {
+	return &BlobStorageUsageMock{}
+}
+
+func (b *BlobStorageUsageMock) Size() (int64, error) {
+	return b.SizeReturnValue, b.SizeReturnErr
+}
+
+type InviteCounterMock struct {
+	InviteCountsReturnValue pubqueries.InviteCounts
+}
+
+func NewInviteCounterMock() *InviteCounterMock {
+	return &InviteCounterMock{}
+}
+
+func (i *InviteCounterMock) InviteCounts() pubqueries.InviteCounts {
+	return i.InviteCountsReturnValue
+}
@@ -15,3 +15,7 @@ func NewMockCommandsTransactionProvider(adapters commands.Adapters) *MockCommand
 func (p *MockCommandsTransactionProvider) Update(f func(adapters commands.Adapters) error) error {
 	return f(p.adapters)
 }
+
+func (p *MockCommandsTransactionProvider) View(f func(adapters commands.Adapters) error) error {
+	return f(p.adapters)
+}
@@ -0,0 +1,251 @@
+// Package prometheus implements the subset of the Prometheus text exposition
+// format needed to expose the metrics of the pub. Each metric can have at most
+// one label.
+package prometheus
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/boreq/errors"
+	"github.com/hashicorp/go-multierror"
+)
+
+const (
+	typeCounter = "counter"
+	typeGauge   = "gauge"
+)
+
+// Registry holds metrics and writes them in the text exposition format. It is
+// safe for concurrent use.
+type Registry struct {
+	lock     sync.Mutex
+	families []family
+}
+
+func NewRegistry() *Registry {
+	return &Registry{}
+}
+
+func (r *Registry) NewCounter(name, help string) *Counter {
+	counter := &Counter{}
+	r.register(name, help, typeCounter, "", func() ([]sample, error) {
+		return []sample{{value: float64(counter.Value())}}, nil
+	})
+	return counter
+}
+
+func (r *Registry) NewCounterVec(name, help, label string) *CounterVec {
+	vec := &CounterVec{counters: make(map[string]*Counter)}
+	r.register(name, help, typeCounter, label, func() ([]sample, error) {
+		return vec.samples(), nil
+	})
+	return vec
+}
+
+// NewCounterVecFunc registers a counter with values returned by the provided
+// function every time the metrics are written. The function returns counter
+// values keyed by the values of the label.
+func (r *Registry) NewCounterVecFunc(name, help, label string, fn func() (map[string]float64, error)) {
+	r.register(name, help, typeCounter, label, func() ([]sample, error) {
+		values, err := fn()
+		if err != nil {
+			return nil, err
+		}
+		return samplesFromMap(values), nil
+	})
+}
+
+func (r *Registry) NewGauge(name, help string) *Gauge {
+	gauge := &Gauge{}
+	r.register(name, help, typeGauge, "", func() ([]sample, error) {
+		return []sample{{value: float64(gauge.Value())}}, nil
+	})
+	return gauge
+}
+
+// NewGaugeFunc registers a gauge with the value returned by the provided
+// function every time the metrics are written.
+func (r *Registry) NewGaugeFunc(name, help string, fn func() (float64, error)) {
+	r.register(name, help, typeGauge, "", func() ([]sample, error) {
+		value, err := fn()
+		if err != nil {
+			return nil, err
+		}
+		return []sample{{value: value}}, nil
+	})
+}
+
+// Write writes all metrics in the registration order. If values of some of
+// the metrics can't be determined they are skipped and an error is returned
+// after writing the remaining ones.
+func (r *Registry) Write(w io.Writer) error {
+	r.lock.Lock()
+	families := make([]family, len(r.families))
+	copy(families, r.families)
+	r.lock.Unlock()
+
+	var result error
+
+	for _, family := range families {
+		samples, err := family.collect()
+		if err != nil {
+			result = multierror.Append(result, errors.Wrapf(err, "error collecting metric '%s'", family.name))
+			continue
+		}
+
+		if err := family.write(w, samples); err != nil {
+			return errors.Wrap(err, "write error")
+		}
+	}
+
+	return result
+}
+
+func (r *Registry) register(name, help, typ, label string, collect func() ([]sample, error)) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	for _, family := range r.families {
+		if family.name == name {
+			panic(fmt.Sprintf("metric '%s' is already registered", name))
+		}
+	}
+
+	r.families = append(r.families, family{
+		name:    name,
+		help:    help,
+		typ:     typ,
+		label:   label,
+		collect: collect,
+	})
+}
+
+// Counter is a value which can only increase.
+type Counter struct {
+	value atomic.Uint64
+}
+
+func (c *Counter) Inc() {
+	c.value.Add(1)
+}
+
+func (c *Counter) Add(delta uint64) {
+	c.value.Add(delta)
+}
+
+func (c *Counter) Value() uint64 {
+	return c.value.Load()
+}
+
+// CounterVec is a group of counters distinguished by the value of a label.
+type CounterVec struct {
+	lock     sync.Mutex
+	counters map[string]*Counter
+}
+
+// WithLabelValue returns the counter for the provided value of the label. The
+// counter is created if it doesn't exist. Counters are only written after
+// they are created, call this function during initialization to write
+// counters which haven't been incremented yet.
+func (c *CounterVec) WithLabelValue(value string) *Counter {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	counter, ok := c.counters[value]
+	if !ok {
+		counter = &Counter{}
+		c.counters[value] = counter
+	}
+	return counter
+}
+
+func (c *CounterVec) samples() []sample {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	values := make(map[string]float64)
+	for labelValue, counter := range c.counters {
+		values[labelValue] = float64(counter.Value())
+	}
+	return samplesFromMap(values)
+}
+
+// Gauge is a value which can increase and decrease.
+type Gauge struct {
+	value atomic.Int64
+}
+
+func (g *Gauge) Inc() {
+	g.value.Add(1)
+}
+
+func (g *Gauge) Dec() {
+	g.value.Add(-1)
+}
+
+func (g *Gauge) Value() int64 {
+	return g.value.Load()
+}
+
+type family struct {
+	name    string
+	help    string
+	typ     string
+	label   string
+	collect func() ([]sample, error)
+}
+
+func (f family) write(w io.Writer, samples []sample) error {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "# HELP %s %s\n", f.name, escapeHelp(f.help))
+	fmt.Fprintf(&b, "# TYPE %s %s\n", f.name, f.typ)
+
+	for _, sample := range samples {
+		b.WriteString(f.name)
+		if f.label != "" {
+			fmt.Fprintf(&b, "{%s=\"%s\"}", f.label, escapeLabelValue(sample.labelValue))
+		}
+		fmt.Fprintf(&b, " %s\n", strconv.FormatFloat(sample.value, 'f', -1, 64))
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+type sample struct {
+	labelValue string
+	value      float64
+}
+
+func samplesFromMap(values map[string]float64) []sample {
+	var samples []sample
+	for labelValue, value := range values {
+		samples = append(samples, sample{labelValue: labelValue, value: value})
+	}
+
+	sort.Slice(samples, func(i, j int) bool {
+		return samples[i].labelValue < samples[j].labelValue
+	})
+
+	return samples
+}
+
+var (
+	helpReplacer       = strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+	labelValueReplacer = strings.NewReplacer(`\`, `\\`, "\n", `\n`, `"`, `\"`)
+)
+
+func escapeHelp(s string) string {
+	return helpReplacer.Replace(s)
+}
+
+func escapeLabelValue(s string) string {
+	return labelValueReplacer.Replace(s)
+}
@@ -0,0 +1,89 @@
+package prometheus_test
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/planetary-social/scuttlego-pub/internal/prometheus"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegistry_Write(t *testing.T) {
+	registry := prometheus.NewRegistry()
+
+	counter := registry.NewCounter("some_counter_total", "Some counter.")
+	counter.Inc()
+	counter.Add(2)
+
+	counterVec := registry.NewCounterVec("some_counter_vec_total", "Some counter vec.", "event")
+	counterVec.WithLabelValue("b").Inc()
+	counterVec.WithLabelValue("a")
+	counterVec.WithLabelValue(`"quoted"`).Inc()
+
+	gauge := registry.NewGauge("some_gauge", "Some gauge.\nWith a new line.")
+	gauge.Inc()
+	gauge.Inc()
+	gauge.Dec()
+
+	registry.NewGaugeFunc("some_gauge_func", "Some gauge func.", func() (float64, error) {
+		return 1.5, nil
+	})
+
+	registry.NewCounterVecFunc("some_counter_vec_func_total", "Some counter vec func.", "runner", func() (map[string]float64, error) {
+		return map[string]float64{"y": 10, "x": 123456789}, nil
+	})
+
+	buf := &bytes.Buffer{}
+	err := registry.Write(buf)
+	require.NoError(t, err)
+
+	require.Equal(t, `# HELP some_counter_total Some counter.
+# TYPE some_counter_total counter
+some_counter_total 3
+# HELP some_counter_vec_total Some counter vec.
+# TYPE some_counter_vec_total counter
+some_counter_vec_total{event="\"quoted\""} 1
+some_counter_vec_total{event="a"} 0
+some_counter_vec_total{event="b"} 1
+# HELP some_gauge Some gauge.\nWith a new line.
+# TYPE some_gauge gauge
+some_gauge 1
+# HELP some_gauge_func Some gauge func.
+# TYPE some_gauge_func gauge
+some_gauge_func 1.5
+# HELP some_counter_vec_func_total Some counter vec func.
+# TYPE some_counter_vec_func_total counter
+some_counter_vec_func_total{runner="x"} 123456789
+some_counter_vec_func_total{runner="y"} 10
+`, buf.String())
+}
+
+func TestRegistry_MetricsWhichCantBeCollectedAreSkipped(t *testing.T) {
+	registry := prometheus.NewRegistry()
+
+	registry.NewGaugeFunc("failing_gauge", "Failing gauge.", func() (float64, error) {
+		return 0, errors.New("some error")
+	})
+
+	registry.NewCounter("some_counter_total", "Some counter.")
+
+	buf := &bytes.Buffer{}
+	err := registry.Write(buf)
+	require.ErrorContains(t, err, "error collecting metric 'failing_gauge': some error")
+
+	require.Equal(t, `# HELP some_counter_total Some counter.
+# TYPE some_counter_total counter
+some_counter_total 0
+`, buf.String())
+}
+
+func TestRegistry_RegisteringMetricsTwicePanics(t *testing.T) {
+	registry := prometheus.NewRegistry()
+
+	registry.NewCounter("some_counter_total", "Some counter.")
+
+	require.Panics(t, func() {
+		registry.NewGauge("some_counter_total", "Some gauge.")
+	})
+}
@@ -0,0 +1,116 @@
+package adapters
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/boreq/errors"
+)
+
+// writeFileAtomically replaces the contents of the file. The contents are
+// first written to a temporary file in the same directory which is then
+// renamed so that the file is never left partially written. The file is only
+// accessible to its owner.
+func writeFileAtomically(path string, b []byte) error {
+	tmp, err := writeTemporaryFile(path, b)
+	if err != nil {
+		return errors.Wrap(err, "error writing a temporary file")
+	}
+	defer os.Remove(tmp) // does nothing once the file is renamed
+
+	if err := os.Rename(tmp, path); err != nil {
+		return errors.Wrap(err, "rename error")
+	}
+
+	if err := syncDirectory(filepath.Dir(path)); err != nil {
+		return errors.Wrap(err, "error syncing the directory")
+	}
+
+	return nil
+}
+
+// createFile creates the file and fails with an error wrapping os.ErrExist if
+// it already exists. The contents are first written to a temporary file in the
+// same directory which is then linked to the target path so that an existing
+// file is never replaced and the file is never left partially written. The
+// file is only accessible to its owner.
+func createFile(path string, b []byte) error {
+	tmp, err := writeTemporaryFile(path, b)
+	if err != nil {
+		return errors.Wrap(err, "error writing a temporary file")
+	}
+	defer os.Remove(tmp)
+
+	if err := os.Link(tmp, path); err != nil {
+		return errors.Wrap(err, "link error")
+	}
+
+	if err := syncDirectory(filepath.Dir(path)); err != nil {
+		return errors.Wrap(err, "error syncing the directory")
+	}
+
+	return nil
+}
+
+// writeTemporaryFile writes the contents to a new temporary file created in
+// the same directory as the provided path and returns its path. The file is
+// only accessible to its owner.
+func writeTemporaryFile(path string, b []byte) (name string, err error) {
+	f, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return "", errors.Wrap(err, "error creating a temporary file")
+	}
+	defer f.Close()
+	defer func() {
+		if err != nil {
+			_ = os.Remove(f.Name())
+		}
+	}()
+
+	if err := f.Chmod(0o600); err != nil {
+		return "", errors.Wrap(err, "chmod error")
+	}
+
+	if _, err := f.Write(b); err != nil {
+		return "", errors.Wrap(err, "write error")
+	}
+
+	if err := f.Sync(); err != nil {
+		return "", errors.Wrap(err, "sync error")
+	}
+
+	if err := f.Close(); err != nil {
+		return "", errors.Wrap(err, "close error")
+	}
+
+	return f.Name(), nil
+}
+
+// syncDirectory makes sure that changes to the entries of the directory, such
+// as created or renamed files, are persisted.
+func syncDirectory(directory string) error {
+	d, err := os.Open(directory)
+	if err != nil {
+		return errors.Wrap(err, "open error")
+	}
+	defer d.Close()
+
+	if err := d.Sync(); err != nil {
+		return errors.Wrap(err, "sync error")
+	}
+
+	return d.Close()
+}
+
+func fileExists(path string) (bool, error) {
+	_, err := os.Stat(path)
+	if err == nil {
+		return true, nil
+	}
+
+	if os.IsNotExist(err) {
+		return false, nil
+	}
+
+	return false, errors.Wrap(err, "stat error")
+}
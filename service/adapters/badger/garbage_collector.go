@@ -0,0 +1,66 @@
+package badger
+
+import (
+	"context"
+	"time"
+
+	"github.com/boreq/errors"
+	"github.com/dgraph-io/badger/v3"
+	"github.com/planetary-social/scuttlego/logging"
+)
+
+const (
+	// garbageCollectionDelay is the time to wait before running the garbage
+	// collection again if the previous run didn't rewrite a file.
+	garbageCollectionDelay = 1 * time.Minute
+
+	// garbageCollectionDiscardRatio is passed to badger.DB.RunValueLogGC.
+	garbageCollectionDiscardRatio = 0.5
+)
+
+type GarbageCollectorMetrics interface {
+	// BadgerGarbageCollectionRun is called with the error returned by
+	// badger.DB.RunValueLogGC.
+	BadgerGarbageCollectionRun(err error)
+}
+
+// GarbageCollector runs the garbage collection of the value log in the same
+// way as the garbage collector from scuttlego and reports the result of every
+// run. It is run again immediately after rewriting a file as more files may
+// need to be rewritten.
+type GarbageCollector struct {
+	db      *badger.DB
+	metrics GarbageCollectorMetrics
+	logger  logging.Logger
+}
+
+func NewGarbageCollector(db *badger.DB, metrics GarbageCollectorMetrics, logger logging.Logger) *GarbageCollector {
+	return &GarbageCollector{
+		db:      db,
+		metrics: metrics,
+		logger:  logger.New("badger_garbage_collector"),
+	}
+}
+
+// Run keeps collecting garbage until the context is cancelled.
+func (g *GarbageCollector) Run(ctx context.Context) error {
+	for {
+		err := g.db.RunValueLogGC(garbageCollectionDiscardRatio)
+		g.metrics.BadgerGarbageCollectionRun(err)
+
+		delay := garbageCollectionDelay
+		switch {
+		case err == nil:
+			g.logger.Debug().Message("garbage collected a file")
+			delay = 0
+		case !errors.Is(err, badger.ErrNoRewrite):
+			g.logger.Debug().WithError(err).Message("error performing garbage collection")
+		}
+
+		select {
+		case <-time.After(delay):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
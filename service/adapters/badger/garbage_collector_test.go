@@ -0,0 +1,64 @@
+package badger_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/dgraph-io/badger/v3"
+	"github.com/planetary-social/scuttlego-pub/internal/fixtures"
+	"github.com/planetary-social/scuttlego-pub/internal/mocks"
+	pubbadger "github.com/planetary-social/scuttlego-pub/service/adapters/badger"
+	"github.com/planetary-social/scuttlego/logging"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGarbageCollector_ReportsRuns(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// garbage collection always fails in the in-memory mode
+	options := badger.DefaultOptions("").WithInMemory(true)
+	options.Logger = nil
+
+	db, err := badger.Open(options)
+	require.NoError(t, err)
+	defer db.Close()
+
+	metrics := mocks.NewGarbageCollectorMetricsMock()
+	collector := pubbadger.NewGarbageCollector(db, metrics, logging.NewDevNullLogger())
+
+	go func() {
+		_ = collector.Run(ctx)
+	}()
+
+	require.Eventually(t, func() bool {
+		runs := metrics.Runs()
+		return len(runs) == 1 && errors.Is(runs[0], badger.ErrGCInMemoryMode)
+	}, time.Second, time.Millisecond)
+}
+
+func TestGarbageCollector_ReportsRunsWhichHadNothingToRewrite(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	options := badger.DefaultOptions(fixtures.Directory(t))
+	options.Logger = nil
+
+	db, err := badger.Open(options)
+	require.NoError(t, err)
+	defer db.Close()
+
+	metrics := mocks.NewGarbageCollectorMetricsMock()
+	collector := pubbadger.NewGarbageCollector(db, metrics, logging.NewDevNullLogger())
+
+	go func() {
+		_ = collector.Run(ctx)
+	}()
+
+	require.Eventually(t, func() bool {
+		runs := metrics.Runs()
+		return len(runs) == 1 && errors.Is(runs[0], badger.ErrNoRewrite)
+	}, time.Second, time.Millisecond)
+}
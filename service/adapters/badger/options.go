@@ -0,0 +1,54 @@
+package badger
+
+import (
+	"fmt"
+
+	"github.com/dgraph-io/badger/v3"
+	"github.com/planetary-social/scuttlego-pub/service"
+)
+
+const megabyte = 1 << 20
+
+// NewOptions returns the options of the database stored in the provided
+// directory. Memory related options are taken from the preset selected in the
+// config unless they are set explicitly.
+func NewOptions(directory string, config service.Config) (badger.Options, error) {
+	options := badger.DefaultOptions(directory)
+
+	switch config.BadgerPreset {
+	case service.BadgerPresetDefault:
+	case service.BadgerPresetLowMemory:
+		options.MemTableSize = 16 * megabyte
+		options.NumMemtables = 2
+		options.BlockCacheSize = 32 * megabyte
+		options.IndexCacheSize = 16 * megabyte
+		options.ValueLogFileSize = 64 * megabyte
+		options.NumCompactors = 2
+	default:
+		return badger.Options{}, fmt.Errorf("unknown preset '%s'", config.BadgerPreset)
+	}
+
+	if v := config.BadgerMemTableSizeMegabytes; v != 0 {
+		options.MemTableSize = int64(v) * megabyte
+	}
+
+	if v := config.BadgerBlockCacheSizeMegabytes; v != 0 {
+		options.BlockCacheSize = int64(v) * megabyte
+	}
+
+	if v := config.BadgerIndexCacheSizeMegabytes; v != 0 {
+		options.IndexCacheSize = int64(v) * megabyte
+	}
+
+	if v := config.BadgerValueLogFileSizeMegabytes; v != 0 {
+		options.ValueLogFileSize = int64(v) * megabyte
+	}
+
+	if v := config.BadgerNumCompactors; v != 0 {
+		options.NumCompactors = v
+	}
+
+	options.SyncWrites = config.BadgerSyncWrites
+
+	return options, nil
+}
@@ -0,0 +1,60 @@
+package badger_test
+
+import (
+	"testing"
+
+	"github.com/dgraph-io/badger/v3"
+	"github.com/planetary-social/scuttlego-pub/service"
+	pubbadger "github.com/planetary-social/scuttlego-pub/service/adapters/badger"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewOptions_DefaultPresetUsesBadgerDefaults(t *testing.T) {
+	config := service.NewDefaultConfig()
+
+	options, err := pubbadger.NewOptions("/some/directory", config)
+	require.NoError(t, err)
+
+	defaultOptions := badger.DefaultOptions("/some/directory")
+	require.Equal(t, defaultOptions.MemTableSize, options.MemTableSize)
+	require.Equal(t, defaultOptions.NumMemtables, options.NumMemtables)
+	require.Equal(t, defaultOptions.BlockCacheSize, options.BlockCacheSize)
+	require.Equal(t, defaultOptions.IndexCacheSize, options.IndexCacheSize)
+	require.Equal(t, defaultOptions.ValueLogFileSize, options.ValueLogFileSize)
+	require.Equal(t, defaultOptions.NumCompactors, options.NumCompactors)
+	require.True(t, options.SyncWrites)
+}
+
+func TestNewOptions_LowMemoryPresetUsesLessMemory(t *testing.T) {
+	config := service.NewDefaultConfig()
+	config.BadgerPreset = service.BadgerPresetLowMemory
+
+	options, err := pubbadger.NewOptions("/some/directory", config)
+	require.NoError(t, err)
+
+	defaultOptions := badger.DefaultOptions("/some/directory")
+	require.Less(t, options.MemTableSize, defaultOptions.MemTableSize)
+	require.Less(t, options.BlockCacheSize, defaultOptions.BlockCacheSize)
+	require.Less(t, options.ValueLogFileSize, defaultOptions.ValueLogFileSize)
+}
+
+func TestNewOptions_ExplicitValuesOverrideThePreset(t *testing.T) {
+	config := service.NewDefaultConfig()
+	config.BadgerPreset = service.BadgerPresetLowMemory
+	config.BadgerMemTableSizeMegabytes = 1
+	config.BadgerBlockCacheSizeMegabytes = 2
+	config.BadgerIndexCacheSizeMegabytes = 3
+	config.BadgerValueLogFileSizeMegabytes = 4
+	config.BadgerNumCompactors = 5
+	config.BadgerSyncWrites = false
+
+	options, err := pubbadger.NewOptions("/some/directory", config)
+	require.NoError(t, err)
+
+	require.Equal(t, int64(1<<20), options.MemTableSize)
+	require.Equal(t, int64(2<<20), options.BlockCacheSize)
+	require.Equal(t, int64(3<<20), options.IndexCacheSize)
+	require.Equal(t, int64(4<<20), options.ValueLogFileSize)
+	require.Equal(t, 5, options.NumCompactors)
+	require.False(t, options.SyncWrites)
+}
@@ -0,0 +1,95 @@
+package badger
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/boreq/errors"
+	"github.com/dgraph-io/badger/v3"
+	"github.com/planetary-social/scuttlego-pub/service/domain"
+	"github.com/planetary-social/scuttlego/service/adapters/badger/utils"
+	"github.com/planetary-social/scuttlego/service/domain/refs"
+)
+
+type PendingWelcomeRepository struct {
+	tx *badger.Txn
+}
+
+func NewPendingWelcomeRepository(tx *badger.Txn) *PendingWelcomeRepository {
+	return &PendingWelcomeRepository{tx: tx}
+}
+
+// Put saves the pending welcome replacing a pending welcome of the same feed
+// if it exists.
+func (r *PendingWelcomeRepository) Put(welcome domain.PendingWelcome) error {
+	value, err := json.Marshal(persistedPendingWelcome{
+		Feed:       welcome.Feed().String(),
+		RedeemedAt: welcome.RedeemedAt(),
+	})
+	if err != nil {
+		return errors.Wrap(err, "error persisting the pending welcome")
+	}
+
+	if err := r.getPendingWelcomesBucket().Set(r.newKey(welcome.Feed()), value); err != nil {
+		return errors.Wrap(err, "set error")
+	}
+
+	return nil
+}
+
+// List returns all pending welcomes in no particular order.
+func (r *PendingWelcomeRepository) List() ([]domain.PendingWelcome, error) {
+	var result []domain.PendingWelcome
+
+	if err := r.getPendingWelcomesBucket().ForEach(func(item utils.Item) error {
+		value, err := item.ValueCopy(nil)
+		if err != nil {
+			return errors.Wrap(err, "error getting value")
+		}
+
+		var v persistedPendingWelcome
+		if err := json.Unmarshal(value, &v); err != nil {
+			return errors.Wrap(err, "error unmarshaling the pending welcome")
+		}
+
+		feed, err := refs.NewFeed(v.Feed)
+		if err != nil {
+			return errors.Wrap(err, "error creating the feed ref")
+		}
+
+		welcome, err := domain.NewPendingWelcome(feed, v.RedeemedAt)
+		if err != nil {
+			return errors.Wrap(err, "error creating the pending welcome")
+		}
+
+		result = append(result, welcome)
+		return nil
+	}); err != nil {
+		return nil, errors.Wrap(err, "error iterating over pending welcomes")
+	}
+
+	return result, nil
+}
+
+// Delete does nothing if a pending welcome of the feed doesn't exist.
+func (r *PendingWelcomeRepository) Delete(feed refs.Feed) error {
+	if err := r.getPendingWelcomesBucket().Delete(r.newKey(feed)); err != nil {
+		return errors.Wrap(err, "delete error")
+	}
+	return nil
+}
+
+func (r *PendingWelcomeRepository) newKey(feed refs.Feed) []byte {
+	return []byte(feed.String())
+}
+
+func (r *PendingWelcomeRepository) getPendingWelcomesBucket() utils.Bucket {
+	return utils.MustNewBucket(r.tx, utils.MustNewKey(
+		utils.MustNewKeyComponent([]byte("pending_welcomes")),
+	))
+}
+
+type persistedPendingWelcome struct {
+	Feed       string    `json:"feed"`
+	RedeemedAt time.Time `json:"redeemed_at"`
+}
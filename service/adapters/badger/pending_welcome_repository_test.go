@@ -0,0 +1,54 @@
+package badger_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/planetary-social/scuttlego-pub/internal/fixtures"
+	"github.com/planetary-social/scuttlego-pub/service/di"
+	"github.com/planetary-social/scuttlego-pub/service/domain"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPendingWelcomeRepository_PutListDelete(t *testing.T) {
+	ts, err := di.BuildBadgerTestAdapters(t)
+	require.NoError(t, err)
+
+	welcome1 := domain.MustNewPendingWelcome(fixtures.SomeRefFeed(), time.Now())
+	welcome2 := domain.MustNewPendingWelcome(fixtures.SomeRefFeed(), time.Now())
+
+	err = ts.TransactionProvider.Update(func(adapters di.TestAdapters) error {
+		if err := adapters.PendingWelcomeRepository.Put(welcome1); err != nil {
+			return err
+		}
+		return adapters.PendingWelcomeRepository.Put(welcome2)
+	})
+	require.NoError(t, err)
+
+	err = ts.TransactionProvider.Update(func(adapters di.TestAdapters) error {
+		welcomes, err := adapters.PendingWelcomeRepository.List()
+		require.NoError(t, err)
+		require.Len(t, welcomes, 2)
+
+		for _, welcome := range welcomes {
+			expected := welcome1
+			if welcome.Feed().Equal(welcome2.Feed()) {
+				expected = welcome2
+			}
+			require.Equal(t, expected.Feed(), welcome.Feed())
+			require.True(t, expected.RedeemedAt().Equal(welcome.RedeemedAt()))
+		}
+
+		return adapters.PendingWelcomeRepository.Delete(welcome1.Feed())
+	})
+	require.NoError(t, err)
+
+	err = ts.TransactionProvider.Update(func(adapters di.TestAdapters) error {
+		welcomes, err := adapters.PendingWelcomeRepository.List()
+		require.NoError(t, err)
+		require.Len(t, welcomes, 1)
+		require.Equal(t, welcome2.Feed(), welcomes[0].Feed())
+		return nil
+	})
+	require.NoError(t, err)
+}
@@ -1,10 +1,22 @@
 package badger
 
 import (
+	"math/rand"
+	"time"
+
 	"github.com/boreq/errors"
 	"github.com/dgraph-io/badger/v3"
 )
 
+const (
+	// maxUpdateAttempts limits the number of times a read-write transaction
+	// is attempted if it keeps conflicting with other transactions.
+	maxUpdateAttempts = 10
+
+	initialConflictBackoff = 5 * time.Millisecond
+	maxConflictBackoff     = 500 * time.Millisecond
+)
+
 type AdaptersFactory[T any] func(tx *badger.Txn) (T, error)
 
 type TransactionProvider[T any] struct {
@@ -16,8 +28,34 @@ func NewTransactionProvider[T any](db *badger.DB, factory AdaptersFactory[T]) *T
 	return &TransactionProvider[T]{db: db, factory: factory}
 }
 
+// Update runs the provided function in a read-write transaction. If the
+// transaction can't be committed because it conflicts with another
+// transaction it is retried with a backoff. This means that the provided
+// function may be called multiple times and must be safe to re-run.
 func (t TransactionProvider[T]) Update(f func(adapters T) error) error {
-	return t.db.Update(func(tx *badger.Txn) error {
+	backoff := initialConflictBackoff
+
+	for attempt := 1; ; attempt++ {
+		err := t.update(f)
+		if !errors.Is(err, badger.ErrConflict) {
+			return err
+		}
+
+		if attempt >= maxUpdateAttempts {
+			return errors.Wrapf(err, "giving up after %d attempts", attempt)
+		}
+
+		time.Sleep(backoff/2 + time.Duration(rand.Int63n(int64(backoff/2)+1)))
+
+		backoff *= 2
+		if backoff > maxConflictBackoff {
+			backoff = maxConflictBackoff
+		}
+	}
+}
+
+func (t TransactionProvider[T]) View(f func(adapters T) error) error {
+	return t.db.View(func(tx *badger.Txn) error {
 		adapters, err := t.factory(tx)
 		if err != nil {
 			return errors.Wrap(err, "failed to build adapters")
@@ -26,8 +64,8 @@ func (t TransactionProvider[T]) Update(f func(adapters T) error) error {
 	})
 }
 
-func (t TransactionProvider[T]) View(f func(adapters T) error) error {
-	return t.db.View(func(tx *badger.Txn) error {
+func (t TransactionProvider[T]) update(f func(adapters T) error) error {
+	return t.db.Update(func(tx *badger.Txn) error {
 		adapters, err := t.factory(tx)
 		if err != nil {
 			return errors.Wrap(err, "failed to build adapters")
@@ -0,0 +1,37 @@
+package badger
+
+import (
+	"encoding/binary"
+	"time"
+
+	"github.com/boreq/errors"
+	"github.com/dgraph-io/badger/v3"
+	"github.com/planetary-social/scuttlego/service/adapters/badger/utils"
+)
+
+// WritabilityChecker checks if the database can be written to by saving the
+// time of the last check.
+type WritabilityChecker struct {
+	db *badger.DB
+}
+
+func NewWritabilityChecker(db *badger.DB) *WritabilityChecker {
+	return &WritabilityChecker{db: db}
+}
+
+func (c *WritabilityChecker) CheckWritable() error {
+	value := make([]byte, 8)
+	binary.BigEndian.PutUint64(value, uint64(time.Now().Unix()))
+
+	return c.db.Update(func(tx *badger.Txn) error {
+		bucket := utils.MustNewBucket(tx, utils.MustNewKey(
+			utils.MustNewKeyComponent([]byte("writability_check")),
+		))
+
+		if err := bucket.Set([]byte("last_check"), value); err != nil {
+			return errors.Wrap(err, "set error")
+		}
+
+		return nil
+	})
+}
@@ -0,0 +1,20 @@
+package badger_test
+
+import (
+	"testing"
+
+	"github.com/planetary-social/scuttlego-pub/internal/fixtures"
+	"github.com/planetary-social/scuttlego-pub/service/adapters/badger"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWritabilityChecker(t *testing.T) {
+	db := fixtures.Badger(t)
+
+	checker := badger.NewWritabilityChecker(db)
+
+	for i := 0; i < 2; i++ {
+		err := checker.CheckWritable()
+		require.NoError(t, err)
+	}
+}
@@ -0,0 +1,164 @@
+package adapters
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/boreq/errors"
+)
+
+// EnvironmentVariablePrefix is the prefix of the names of environment
+// variables which override the fields of the config file. The rest of the
+// name is the name of the field in the config file in upper case, for example
+// hops can be overridden with SCUTTLEGO_PUB_HOPS.
+const EnvironmentVariablePrefix = "SCUTTLEGO_PUB_"
+
+// IdentityPassphraseEnvironmentVariable can be used to provide the passphrase
+// of an encrypted identity. It doesn't override any fields of the config file.
+const IdentityPassphraseEnvironmentVariable = EnvironmentVariablePrefix + "IDENTITY_PASSPHRASE"
+
+// EnvironmentVariableNames returns the names of all environment variables
+// which can be used to override the fields of the config file.
+func EnvironmentVariableNames() []string {
+	var names []string
+	t := reflect.TypeOf(storedConfig{})
+	for i := 0; i < t.NumField(); i++ {
+		if hasEnvironmentVariable(t.Field(i)) {
+			names = append(names, environmentVariableName(t.Field(i)))
+		}
+	}
+	return names
+}
+
+// applyEnvironmentVariables overrides the fields of the config with the values
+// of the environment variables. Environment variables are provided in the
+// format returned by os.Environ. Strings are used as is, integers are parsed
+// as decimal numbers, booleans are parsed with strconv.ParseBool, lists of
+// strings are separated with commas, maps are written as comma separated
+// key=value pairs and byte slices can be encoded either as hex or base64.
+// Unknown environment variables with EnvironmentVariablePrefix are rejected
+// in the same way as unknown fields in the config file.
+func applyEnvironmentVariables(config *storedConfig, environ []string) error {
+	v := reflect.ValueOf(config).Elem()
+
+	fields := make(map[string]reflect.Value)
+	for i := 0; i < v.NumField(); i++ {
+		if hasEnvironmentVariable(v.Type().Field(i)) {
+			fields[environmentVariableName(v.Type().Field(i))] = v.Field(i)
+		}
+	}
+
+	for _, variable := range environ {
+		name, value, _ := strings.Cut(variable, "=")
+		if !strings.HasPrefix(name, EnvironmentVariablePrefix) || name == IdentityPassphraseEnvironmentVariable {
+			continue
+		}
+
+		field, ok := fields[name]
+		if !ok {
+			return fmt.Errorf("unknown environment variable '%s'", name)
+		}
+
+		if err := setFieldFromString(field, value); err != nil {
+			return errors.Wrapf(err, "error setting the value of environment variable '%s'", name)
+		}
+	}
+
+	return nil
+}
+
+// applyEnvironmentVariablesOf works like applyEnvironmentVariables but only
+// the environment variables overriding the listed keys of the config file are
+// applied. Other environment variables are ignored.
+func applyEnvironmentVariablesOf(config *storedConfig, environ []string, keys ...string) error {
+	names := make(map[string]struct{})
+	for _, key := range keys {
+		names[EnvironmentVariablePrefix+strings.ToUpper(key)] = struct{}{}
+	}
+
+	var filtered []string
+	for _, variable := range environ {
+		name, _, _ := strings.Cut(variable, "=")
+		if _, ok := names[name]; ok {
+			filtered = append(filtered, variable)
+		}
+	}
+
+	return applyEnvironmentVariables(config, filtered)
+}
+
+func setFieldFromString(field reflect.Value, value string) error {
+	switch field.Interface().(type) {
+	case string:
+		field.SetString(value)
+	case int:
+		i, err := strconv.Atoi(value)
+		if err != nil {
+			return errors.Wrap(err, "error parsing an integer")
+		}
+		field.SetInt(int64(i))
+	case bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return errors.Wrap(err, "error parsing a boolean")
+		}
+		field.SetBool(b)
+	case []string:
+		field.Set(reflect.ValueOf(strings.Split(value, ",")))
+	case map[string]string:
+		m := make(map[string]string)
+		for _, pair := range strings.Split(value, ",") {
+			k, v, ok := strings.Cut(pair, "=")
+			if !ok {
+				return fmt.Errorf("pair '%s' is not in the key=value format", pair)
+			}
+			m[k] = v
+		}
+		field.Set(reflect.ValueOf(m))
+	case []byte:
+		b, err := DecodeBytes(value)
+		if err != nil {
+			return errors.Wrap(err, "error decoding bytes")
+		}
+		field.SetBytes(b)
+	default:
+		return fmt.Errorf("unsupported type '%s'", field.Type())
+	}
+	return nil
+}
+
+// DecodeBytes decodes byte values provided by the user which can be encoded
+// either as hex or base64.
+func DecodeBytes(value string) ([]byte, error) {
+	if b, err := hex.DecodeString(value); err == nil {
+		return b, nil
+	}
+
+	b, err := base64.StdEncoding.DecodeString(value)
+	if err != nil {
+		return nil, errors.New("value is neither hex nor base64")
+	}
+
+	return b, nil
+}
+
+func hasEnvironmentVariable(field reflect.StructField) bool {
+	return field.Tag.Get("env") != "-"
+}
+
+func environmentVariableName(field reflect.StructField) string {
+	return EnvironmentVariablePrefix + strings.ToUpper(tomlFieldName(field))
+}
+
+func tomlFieldName(field reflect.StructField) string {
+	name, _, _ := strings.Cut(field.Tag.Get("toml"), ",")
+	return name
+}
+
+func isSecretField(field reflect.StructField) bool {
+	return field.Tag.Get("secret") == "true"
+}
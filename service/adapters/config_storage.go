@@ -1,92 +1,509 @@
 package adapters
 
 import (
+	"bytes"
+	"fmt"
+	"io"
 	"os"
 	"path/filepath"
+	"reflect"
+	"time"
 
 	"github.com/boreq/errors"
 	"github.com/pelletier/go-toml/v2"
 	"github.com/planetary-social/scuttlego-pub/service"
+	"github.com/planetary-social/scuttlego-pub/service/domain"
 	"github.com/planetary-social/scuttlego/service/domain/feeds/formats"
 	"github.com/planetary-social/scuttlego/service/domain/graph"
 	"github.com/planetary-social/scuttlego/service/domain/transport/boxstream"
 )
 
+const (
+	configFileName = "config.toml"
+	redactedValue  = "<redacted>"
+)
+
+// pathFields are the keys of the config file loaded by LoadPaths.
+var pathFields = []string{"data_directory", "identity_file", "admin_socket"}
+
 type ConfigStorage struct {
-	directory string
+	directory   string
+	environ     func() []string
+	secretFiles *secretFileReader
 }
 
 func NewConfigStorage(directory string) *ConfigStorage {
-	return &ConfigStorage{directory: directory}
+	return &ConfigStorage{
+		directory:   directory,
+		environ:     os.Environ,
+		secretFiles: newSecretFileReader(),
+	}
 }
 
 func (s *ConfigStorage) Save(config service.Config) error {
-	storedConfig := storedConfig{
-		DataDirectory: config.DataDirectory,
-		ListenAddress: config.ListenAddress,
-		NetworkKey:    config.NetworkKey.Bytes(),
-		MessageHMAC:   config.MessageHMAC.Bytes(),
-		Hops:          config.Hops.Int(),
-	}
+	return s.save(newStoredConfig(config))
+}
 
-	f, err := os.OpenFile(s.configFilePath(), os.O_WRONLY|os.O_CREATE, 0o700)
-	if err != nil {
-		return errors.Wrap(err, "open file error")
-	}
+// Exists checks if the config file exists.
+func (s *ConfigStorage) Exists() (bool, error) {
+	return fileExists(s.configFilePath())
+}
 
-	if err := toml.NewEncoder(f).Encode(storedConfig); err != nil {
+func (s *ConfigStorage) save(storedConfig storedConfig) error {
+	buf := &bytes.Buffer{}
+	if err := toml.NewEncoder(buf).Encode(storedConfig); err != nil {
 		return errors.Wrap(err, "error encoding toml")
 	}
 
+	if err := writeFileAtomically(s.configFilePath(), buf.Bytes()); err != nil {
+		return errors.Wrap(err, "error writing the file")
+	}
+
 	return nil
 }
 
+// Load loads the config file and then overrides its fields with the values
+// of environment variables. See EnvironmentVariableNames. Config files
+// created by older versions of the program are upgraded and rewritten in
+// place, the original file is backed up first. If some of the values are
+// invalid then a *service.ConfigValidationError listing all of them is
+// returned.
 func (s *ConfigStorage) Load() (service.Config, error) {
-	var storedConfig storedConfig
+	return s.load(true)
+}
+
+// LoadWithoutUpgrading works like Load but config files created by older
+// versions of the program are only upgraded in memory. The config file is
+// never modified.
+func (s *ConfigStorage) LoadWithoutUpgrading() (service.Config, error) {
+	return s.load(false)
+}
 
-	f, err := os.Open(s.configFilePath())
+// LoadPaths loads only the fields of the config which describe where the
+// running pub can be found: the data directory, the identity file and the
+// admin socket. It is meant for subcommands which don't run the pub. Config
+// files created by older versions of the program are only upgraded in memory,
+// only the environment variables overriding those fields are applied and the
+// secrets aren't read. Other fields of the returned config are left empty.
+func (s *ConfigStorage) LoadPaths() (service.Config, error) {
+	storedConfig, err := s.loadStoredConfig(false)
 	if err != nil {
-		return service.Config{}, errors.Wrap(err, "open file error")
+		return service.Config{}, errors.Wrap(err, "error loading the config file")
 	}
 
-	if err = toml.NewDecoder(f).DisallowUnknownFields().Decode(&storedConfig); err != nil {
-		return service.Config{}, errors.Wrap(err, "error decoding toml")
+	if err := applyEnvironmentVariablesOf(&storedConfig, s.environ(), pathFields...); err != nil {
+		return service.Config{}, errors.Wrap(err, "error applying environment variables")
 	}
 
-	networkKey, err := boxstream.NewNetworkKey(storedConfig.NetworkKey)
+	return service.Config{
+		DataDirectory: storedConfig.DataDirectory,
+		IdentityFile:  storedConfig.IdentityFile,
+		AdminSocket:   storedConfig.AdminSocket,
+	}, nil
+}
+
+// Check loads the config like LoadWithoutUpgrading and validates it. Instead
+// of stopping at the first problem it returns a
+// *service.ConfigValidationError listing all invalid values and all problems
+// reported by service.Config.Validate. Only errors which prevent the config
+// file from being parsed are returned on their own.
+func (s *ConfigStorage) Check() error {
+	storedConfig, err := s.loadStoredConfig(false)
 	if err != nil {
-		return service.Config{}, errors.Wrap(err, "error creating a network key")
+		return errors.Wrap(err, "error loading the config file")
 	}
 
-	messageHMAC, err := formats.NewMessageHMAC(storedConfig.MessageHMAC)
-	if err != nil {
-		return service.Config{}, errors.Wrap(err, "error creating message HMAC")
+	config, problems := s.newConfig(storedConfig)
+
+	if err := config.Validate(); err != nil {
+		var validationErr *service.ConfigValidationError
+		if !errors.As(err, &validationErr) {
+			return errors.Wrap(err, "error validating the config")
+		}
+		problems = append(problems, validationErr.Problems...)
+	}
+
+	if len(problems) > 0 {
+		return &service.ConfigValidationError{Problems: problems}
 	}
 
-	hops, err := graph.NewHops(storedConfig.Hops)
+	return nil
+}
+
+func (s *ConfigStorage) load(upgradeInPlace bool) (service.Config, error) {
+	storedConfig, err := s.loadStoredConfig(upgradeInPlace)
 	if err != nil {
-		return service.Config{}, errors.Wrap(err, "error creating hops")
+		return service.Config{}, errors.Wrap(err, "error loading the config file")
+	}
+
+	config, problems := s.newConfig(storedConfig)
+	if len(problems) > 0 {
+		return service.Config{}, &service.ConfigValidationError{Problems: problems}
+	}
+
+	return config, nil
+}
+
+// newConfig applies environment variables to the stored config and converts
+// it. Fields with invalid values are set to their default values so that the
+// returned config can still be validated.
+func (s *ConfigStorage) newConfig(storedConfig storedConfig) (service.Config, []error) {
+	var problems []error
+
+	defaults := service.NewDefaultConfig()
+
+	if err := applyEnvironmentVariables(&storedConfig, s.environ()); err != nil {
+		problems = append(problems, errors.Wrap(err, "error applying environment variables"))
+	}
+
+	networkKey := defaults.NetworkKey
+	if networkKeyBytes, err := s.secretBytes(storedConfig.NetworkKey, storedConfig.NetworkKeyFile); err != nil {
+		problems = append(problems, errors.Wrap(err, "error reading the file specified by key 'network_key_file'"))
+	} else if v, err := boxstream.NewNetworkKey(networkKeyBytes); err != nil {
+		problems = append(problems, errors.Wrap(err, "invalid value of key 'network_key'"))
+	} else {
+		networkKey = v
+	}
+
+	messageHMAC := defaults.MessageHMAC
+	if messageHMACBytes, err := s.secretBytes(storedConfig.MessageHMAC, storedConfig.MessageHMACFile); err != nil {
+		problems = append(problems, errors.Wrap(err, "error reading the file specified by key 'message_hmac_file'"))
+	} else if v, err := formats.NewMessageHMAC(messageHMACBytes); err != nil {
+		problems = append(problems, errors.Wrap(err, "invalid value of key 'message_hmac'"))
+	} else {
+		messageHMAC = v
+	}
+
+	hops := defaults.Hops
+	if v, err := graph.NewHops(storedConfig.Hops); err != nil {
+		problems = append(problems, errors.Wrap(err, "invalid value of key 'hops'"))
+	} else {
+		hops = v
+	}
+
+	logLevel := defaults.LogLevel
+	if v, err := service.NewLogLevel(storedConfig.LogLevel); err != nil {
+		problems = append(problems, errors.Wrap(err, "invalid value of key 'log_level'"))
+	} else {
+		logLevel = v
+	}
+
+	logComponentLevels := defaults.LogComponentLevels
+	if v, err := newLogComponentLevels(storedConfig.LogComponentLevels); err != nil {
+		problems = append(problems, errors.Wrap(err, "invalid value of key 'log_component_levels'"))
+	} else {
+		logComponentLevels = v
+	}
+
+	logFormat := defaults.LogFormat
+	if v, err := service.NewLogFormat(storedConfig.LogFormat); err != nil {
+		problems = append(problems, errors.Wrap(err, "invalid value of key 'log_format'"))
+	} else {
+		logFormat = v
+	}
+
+	badgerPreset := defaults.BadgerPreset
+	if v, err := service.NewBadgerPreset(storedConfig.BadgerPreset); err != nil {
+		problems = append(problems, errors.Wrap(err, "invalid value of key 'badger_preset'"))
+	} else {
+		badgerPreset = v
+	}
+
+	var preferredPeers []domain.MultiserverAddress
+	for _, s := range storedConfig.PreferredPeers {
+		preferredPeer, err := domain.NewMultiserverAddress(s)
+		if err != nil {
+			problems = append(problems, errors.Wrapf(err, "invalid value of key 'preferred_peers', address '%s'", s))
+			continue
+		}
+		preferredPeers = append(preferredPeers, preferredPeer)
+	}
+
+	var localNetworkInterfaces []string
+	if len(storedConfig.LocalNetworkInterfaces) > 0 {
+		localNetworkInterfaces = storedConfig.LocalNetworkInterfaces
+	}
+
+	var welcomeMessage domain.WelcomeMessageTemplate
+	if storedConfig.WelcomeMessage != "" {
+		v, err := domain.NewWelcomeMessageTemplate(storedConfig.WelcomeMessage)
+		if err != nil {
+			problems = append(problems, errors.Wrap(err, "invalid value of key 'welcome_message'"))
+		} else {
+			welcomeMessage = v
+		}
 	}
 
 	config := service.Config{
-		DataDirectory: storedConfig.DataDirectory,
-		ListenAddress: storedConfig.ListenAddress,
-		NetworkKey:    networkKey,
-		MessageHMAC:   messageHMAC,
-		Hops:          hops,
+		DataDirectory:                   storedConfig.DataDirectory,
+		ListenAddresses:                 storedConfig.ListenAddresses,
+		PublicAddress:                   storedConfig.PublicAddress,
+		HTTPListenAddress:               storedConfig.HTTPListenAddress,
+		AdminSocket:                     storedConfig.AdminSocket,
+		NetworkKey:                      networkKey,
+		MessageHMAC:                     messageHMAC,
+		IdentityFile:                    storedConfig.IdentityFile,
+		NetworkKeyFile:                  storedConfig.NetworkKeyFile,
+		MessageHMACFile:                 storedConfig.MessageHMACFile,
+		PreferredPeers:                  preferredPeers,
+		LocalAdvertising:                storedConfig.LocalAdvertising,
+		LocalDiscovery:                  storedConfig.LocalDiscovery,
+		LocalNetworkInterfaces:          localNetworkInterfaces,
+		Hops:                            hops,
+		Name:                            storedConfig.Name,
+		Description:                     storedConfig.Description,
+		ImagePath:                       storedConfig.ImagePath,
+		LogLevel:                        logLevel,
+		LogComponentLevels:              logComponentLevels,
+		LogFormat:                       logFormat,
+		LogFile:                         storedConfig.LogFile,
+		LogFileMaxSizeMegabytes:         storedConfig.LogFileMaxSizeMegabytes,
+		LogFileMaxBackups:               storedConfig.LogFileMaxBackups,
+		BadgerPreset:                    badgerPreset,
+		BadgerMemTableSizeMegabytes:     storedConfig.BadgerMemTableSizeMegabytes,
+		BadgerBlockCacheSizeMegabytes:   storedConfig.BadgerBlockCacheSizeMegabytes,
+		BadgerIndexCacheSizeMegabytes:   storedConfig.BadgerIndexCacheSizeMegabytes,
+		BadgerValueLogFileSizeMegabytes: storedConfig.BadgerValueLogFileSizeMegabytes,
+		BadgerNumCompactors:             storedConfig.BadgerNumCompactors,
+		BadgerSyncWrites:                storedConfig.BadgerSyncWrites,
+		ShutdownDrainPeriod:             time.Duration(storedConfig.ShutdownDrainPeriodSeconds) * time.Second,
+		WelcomeMessage:                  welcomeMessage,
+	}
+
+	return config, problems
+}
+
+// secretBytes returns the value of the secret or the contents of the file if
+// it is set. Relative paths are resolved against the config directory.
+func (s *ConfigStorage) secretBytes(value []byte, file string) ([]byte, error) {
+	if file == "" {
+		return value, nil
+	}
+	return s.secretFiles.ReadBytes(ResolveSecretFile(s.directory, file))
+}
+
+// WriteRedactedConfig writes the config to the provided writer in the same
+// format as the config file. Values of secret fields are replaced with a
+// placeholder.
+func WriteRedactedConfig(w io.Writer, config service.Config) error {
+	storedConfig := newStoredConfig(config)
+
+	v := reflect.ValueOf(storedConfig)
+	for i := 0; i < v.NumField(); i++ {
+		field := v.Type().Field(i)
+
+		var value any = v.Field(i).Interface()
+		if isSecretField(field) {
+			value = redactedValue
+		}
+
+		encoder := toml.NewEncoder(w).SetTablesInline(true)
+		if err := encoder.Encode(map[string]any{tomlFieldName(field): value}); err != nil {
+			return errors.Wrapf(err, "error encoding field '%s'", field.Name)
+		}
+	}
+
+	return nil
+}
+
+// loadStoredConfig upgrades config files created by older versions of the
+// program. If upgradeInPlace is set then the upgraded config is saved after
+// backing up the original file.
+func (s *ConfigStorage) loadStoredConfig(upgradeInPlace bool) (storedConfig, error) {
+	b, err := os.ReadFile(s.configFilePath())
+	if err != nil {
+		return storedConfig{}, errors.Wrap(err, "read file error")
+	}
+
+	var raw map[string]any
+	if err := toml.Unmarshal(b, &raw); err != nil {
+		return storedConfig{}, describeDecodeError(err, b, true)
+	}
+
+	version, err := configVersion(raw)
+	if err != nil {
+		return storedConfig{}, errors.Wrap(err, "invalid value of key 'version'")
+	}
+
+	if version > currentConfigVersion {
+		return storedConfig{}, fmt.Errorf("config version %d is newer than version %d supported by this program", version, currentConfigVersion)
+	}
+
+	upgrade := version < currentConfigVersion
+	if upgrade {
+		if upgradeInPlace {
+			if err := s.backup(b, version); err != nil {
+				return storedConfig{}, errors.Wrap(err, "error backing up the config file")
+			}
+		}
+
+		if err := upgradeConfig(raw, version); err != nil {
+			return storedConfig{}, errors.Wrapf(err, "error upgrading the config from version %d", version)
+		}
+
+		b, err = toml.Marshal(raw)
+		if err != nil {
+			return storedConfig{}, errors.Wrap(err, "error encoding the upgraded config")
+		}
+	}
+
+	var config storedConfig
+	if err := toml.NewDecoder(bytes.NewReader(b)).DisallowUnknownFields().Decode(&config); err != nil {
+		return storedConfig{}, describeDecodeError(err, b, !upgrade)
+	}
+
+	if upgrade && upgradeInPlace {
+		if err := s.save(config); err != nil {
+			return storedConfig{}, errors.Wrap(err, "error saving the upgraded config")
+		}
 	}
 
 	return config, nil
 }
 
+// backup copies the config file before it is upgraded. Existing files are
+// never overwritten.
+func (s *ConfigStorage) backup(b []byte, version int) error {
+	name := fmt.Sprintf("%s.v%d.%s.backup", configFileName, version, time.Now().Format("20060102150405"))
+
+	f, err := os.OpenFile(filepath.Join(s.directory, name), os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600)
+	if err != nil {
+		return errors.Wrap(err, "open file error")
+	}
+	defer f.Close()
+
+	if _, err := f.Write(b); err != nil {
+		return errors.Wrap(err, "write error")
+	}
+
+	return f.Close()
+}
+
 func (s *ConfigStorage) configFilePath() string {
-	return filepath.Join(s.directory, "config.toml")
+	return filepath.Join(s.directory, configFileName)
+}
+
+func newStoredConfig(config service.Config) storedConfig {
+	return storedConfig{
+		Version:                         currentConfigVersion,
+		DataDirectory:                   config.DataDirectory,
+		ListenAddresses:                 config.ListenAddresses,
+		PublicAddress:                   config.PublicAddress,
+		HTTPListenAddress:               config.HTTPListenAddress,
+		AdminSocket:                     config.AdminSocket,
+		NetworkKey:                      secretBytesUnlessLoadedFromFile(config.NetworkKey.Bytes(), config.NetworkKeyFile),
+		MessageHMAC:                     secretBytesUnlessLoadedFromFile(config.MessageHMAC.Bytes(), config.MessageHMACFile),
+		IdentityFile:                    config.IdentityFile,
+		NetworkKeyFile:                  config.NetworkKeyFile,
+		MessageHMACFile:                 config.MessageHMACFile,
+		PreferredPeers:                  preferredPeersToStrings(config.PreferredPeers),
+		LocalAdvertising:                config.LocalAdvertising,
+		LocalDiscovery:                  config.LocalDiscovery,
+		LocalNetworkInterfaces:          append([]string{}, config.LocalNetworkInterfaces...),
+		Hops:                            config.Hops.Int(),
+		Name:                            config.Name,
+		Description:                     config.Description,
+		ImagePath:                       config.ImagePath,
+		LogLevel:                        config.LogLevel.String(),
+		LogComponentLevels:              logComponentLevelsToStrings(config.LogComponentLevels),
+		LogFormat:                       config.LogFormat.String(),
+		LogFile:                         config.LogFile,
+		LogFileMaxSizeMegabytes:         config.LogFileMaxSizeMegabytes,
+		LogFileMaxBackups:               config.LogFileMaxBackups,
+		BadgerPreset:                    config.BadgerPreset.String(),
+		BadgerMemTableSizeMegabytes:     config.BadgerMemTableSizeMegabytes,
+		BadgerBlockCacheSizeMegabytes:   config.BadgerBlockCacheSizeMegabytes,
+		BadgerIndexCacheSizeMegabytes:   config.BadgerIndexCacheSizeMegabytes,
+		BadgerValueLogFileSizeMegabytes: config.BadgerValueLogFileSizeMegabytes,
+		BadgerNumCompactors:             config.BadgerNumCompactors,
+		BadgerSyncWrites:                config.BadgerSyncWrites,
+		ShutdownDrainPeriodSeconds:      int(config.ShutdownDrainPeriod / time.Second),
+		WelcomeMessage:                  config.WelcomeMessage.String(),
+	}
+}
+
+// secretBytesUnlessLoadedFromFile prevents secrets loaded from files from
+// being written to the config file.
+func secretBytesUnlessLoadedFromFile(b []byte, file string) []byte {
+	if file != "" {
+		return nil
+	}
+	return b
+}
+
+func preferredPeersToStrings(preferredPeers []domain.MultiserverAddress) []string {
+	result := make([]string, 0, len(preferredPeers))
+	for _, preferredPeer := range preferredPeers {
+		result = append(result, preferredPeer.String())
+	}
+	return result
+}
+
+func newLogComponentLevels(levels map[string]string) (map[service.LogComponent]service.LogLevel, error) {
+	if len(levels) == 0 {
+		return nil, nil
+	}
+
+	result := make(map[service.LogComponent]service.LogLevel)
+	for componentString, levelString := range levels {
+		component, err := service.NewLogComponent(componentString)
+		if err != nil {
+			return nil, errors.Wrap(err, "invalid component")
+		}
+
+		level, err := service.NewLogLevel(levelString)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid level of component '%s'", component)
+		}
+
+		result[component] = level
+	}
+	return result, nil
+}
+
+func logComponentLevelsToStrings(levels map[service.LogComponent]service.LogLevel) map[string]string {
+	result := make(map[string]string)
+	for component, level := range levels {
+		result[component.String()] = level.String()
+	}
+	return result
 }
 
+// storedConfig is the format of the config file. Fields tagged as secret are
+// redacted when the config is displayed.
 type storedConfig struct {
-	DataDirectory string `toml:"data_directory" comment:"Directory for data storage. Can be the same as config directory."`
-	ListenAddress string `toml:"listen_address" comment:"Listen address for the Secure Scuttlebutt RPC TCP listener in the format accepted by the Go programming language standard library."`
-	NetworkKey    []byte `toml:"network_key" comment:"Secure Scuttlebutt network key. Used to create networks separate from the Secure Scuttlebutt mainnet."`
-	MessageHMAC   []byte `toml:"message_hmac" comment:"Secure Scuttlebutt message HMAC. Used mostly for testing to make messages incompatibile with the Secure Scuttlebutt mainnet."`
-	Hops          int    `toml:"hops" comment:"Distance of replicated feeds in the social graph. For example if this is set to 1 then only people followed by the pub are replicated. If it is set to 2 then also people who those people follow are replicated."`
+	Version                         int               `toml:"version" env:"-" comment:"Version of the format of this file used to upgrade files created by older versions of the program. Do not modify."`
+	DataDirectory                   string            `toml:"data_directory" comment:"Directory for data storage. Can be the same as config directory."`
+	ListenAddresses                 []string          `toml:"listen_addresses" comment:"Listen addresses for the Secure Scuttlebutt RPC TCP listeners in the format accepted by the Go programming language standard library. A separate listener is started for each address. Addresses without a host such as ':8008' listen on all IPv4 and IPv6 interfaces and can't be combined with other addresses using the same port. To listen on specific interfaces list their addresses, for example ['192.0.2.1:8008', '[2001:db8::1]:8008']."`
+	PublicAddress                   string            `toml:"public_address" comment:"Address under which other peers can reach the pub in the format host:port. If set the pub will announce it on its feed so that peers can learn how to connect to it."`
+	HTTPListenAddress               string            `toml:"http_listen_address" comment:"Listen address of the HTTP listener serving /healthz which responds as long as the pub is running, /readyz which responds with an error if the pub isn't ready to replicate with peers and /metrics which exposes metrics in the Prometheus format, for example '127.0.0.1:8080'. Optional, by default the HTTP listener isn't started."`
+	AdminSocket                     string            `toml:"admin_socket" comment:"Path of the unix domain socket used by subcommands such as invites to manage the running pub. Only the user running the pub can access it. Optional, by default admin.sock in the data directory is used."`
+	NetworkKey                      []byte            `toml:"network_key" secret:"true" comment:"Secure Scuttlebutt network key. Used to create networks separate from the Secure Scuttlebutt mainnet."`
+	MessageHMAC                     []byte            `toml:"message_hmac" secret:"true" comment:"Secure Scuttlebutt message HMAC. Used mostly for testing to make messages incompatibile with the Secure Scuttlebutt mainnet."`
+	IdentityFile                    string            `toml:"identity_file" comment:"Path to the identity file or a file descriptor in the format fd:N, for example a secret mounted by Docker or Kubernetes. Relative paths are resolved against the config directory. The file must not be accessible to the group or other users. Optional, by default identity.toml in the config directory is used."`
+	NetworkKeyFile                  string            `toml:"network_key_file" comment:"Path to a file containing the network key encoded as hex or base64 or a file descriptor in the format fd:N. Overrides network_key. Relative paths are resolved against the config directory. The file must not be accessible to the group or other users. Optional."`
+	MessageHMACFile                 string            `toml:"message_hmac_file" comment:"Path to a file containing the message HMAC encoded as hex or base64 or a file descriptor in the format fd:N. Overrides message_hmac. Relative paths are resolved against the config directory. The file must not be accessible to the group or other users. Optional."`
+	PreferredPeers                  []string          `toml:"preferred_peers" comment:"Multiserver addresses of peers which the pub tries to remain connected to, for example other pubs which should replicate with this one. Format: net:host:port~shs:base64_public_key. Can be changed without restarting the pub by sending SIGHUP. Optional."`
+	LocalAdvertising                bool              `toml:"local_advertising" comment:"Broadcast the listen addresses over UDP so that clients on the local network can discover the pub. Usually not useful on servers in a data center."`
+	LocalDiscovery                  bool              `toml:"local_discovery" comment:"Listen for UDP broadcasts of peers on the local network and connect to them. Usually not useful on servers in a data center."`
+	LocalNetworkInterfaces          []string          `toml:"local_network_interfaces" comment:"Names of network interfaces to which local advertising and discovery are restricted, for example ['eth1']. Optional, by default all interfaces are used."`
+	Hops                            int               `toml:"hops" comment:"Distance of replicated feeds in the social graph. For example if this is set to 1 then only people followed by the pub are replicated. If it is set to 2 then also people who those people follow are replicated. Can be changed without restarting the pub by sending SIGHUP."`
+	Name                            string            `toml:"name" comment:"Name of the pub displayed by clients. Optional."`
+	Description                     string            `toml:"description" comment:"Description of the pub displayed by clients. Optional."`
+	ImagePath                       string            `toml:"image_path" comment:"Path to an image file used as the avatar of the pub. Optional."`
+	LogLevel                        string            `toml:"log_level" comment:"Most verbose level of displayed log messages. One of: error, debug, trace. Can be changed without restarting the pub by sending SIGHUP."`
+	LogComponentLevels              map[string]string `toml:"log_component_levels,inline" comment:"Log levels of selected components which override log_level, for example { replication = 'debug' }. Components: replication, network, badger, invites. Optional."`
+	LogFormat                       string            `toml:"log_format" comment:"Format of log messages. One of: text, json."`
+	LogFile                         string            `toml:"log_file" comment:"Path to a file to which log messages are written instead of the standard error. Optional."`
+	LogFileMaxSizeMegabytes         int               `toml:"log_file_max_size_megabytes" comment:"Size after which the log file is rotated."`
+	LogFileMaxBackups               int               `toml:"log_file_max_backups" comment:"Number of rotated log files which are kept."`
+	BadgerPreset                    string            `toml:"badger_preset" comment:"Values of the memory related options of the database which aren't set explicitly. One of: default, low-memory. Use low-memory on machines with little RAM."`
+	BadgerMemTableSizeMegabytes     int               `toml:"badger_mem_table_size_megabytes" comment:"Size of a single database memtable. Set to 0 to use the value from the preset."`
+	BadgerBlockCacheSizeMegabytes   int               `toml:"badger_block_cache_size_megabytes" comment:"Size of the database block cache. Set to 0 to use the value from the preset."`
+	BadgerIndexCacheSizeMegabytes   int               `toml:"badger_index_cache_size_megabytes" comment:"Size of the database index cache. Set to 0 to use the value from the preset."`
+	BadgerValueLogFileSizeMegabytes int               `toml:"badger_value_log_file_size_megabytes" comment:"Size of a single database value log file. Must be smaller than 2048. Set to 0 to use the value from the preset."`
+	BadgerNumCompactors             int               `toml:"badger_num_compactors" comment:"Number of database compaction workers. Must be at least 2. Set to 0 to use the value from the preset."`
+	BadgerSyncWrites                bool              `toml:"badger_sync_writes" comment:"Sync database writes to disk before committing transactions. Disabling it improves performance but recent changes may be lost if the machine crashes."`
+	ShutdownDrainPeriodSeconds      int               `toml:"shutdown_drain_period_seconds" comment:"Time given to existing connections to finish replication after receiving SIGINT or SIGTERM, during which new connections aren't accepted. Once it passes the connections are closed. Must be positive."`
+	WelcomeMessage                  string            `toml:"welcome_message" comment:"Text of a post greeting new members published after they redeem an invite. Placeholders {{name}} and {{feed}} are replaced with the name and the feed of the new member, for example: Welcome [@{{name}}]({{feed}})! If the name of the new member isn't known yet the post is published once it is replicated or, after an hour, with their feed in place of the name. Names are shortened to 64 characters and stripped of line breaks and characters used in markdown links. Optional."`
 }
@@ -1,11 +1,22 @@
 package adapters_test
 
 import (
+	"bytes"
+	"encoding/base64"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/planetary-social/scuttlego-pub/internal/fixtures"
 	"github.com/planetary-social/scuttlego-pub/service"
 	"github.com/planetary-social/scuttlego-pub/service/adapters"
+	"github.com/planetary-social/scuttlego-pub/service/domain"
+	"github.com/planetary-social/scuttlego/service/domain/feeds/formats"
+	"github.com/planetary-social/scuttlego/service/domain/graph"
+	"github.com/planetary-social/scuttlego/service/domain/transport/boxstream"
 	"github.com/stretchr/testify/require"
 )
 
@@ -24,3 +35,552 @@ func TestConfigStorage(t *testing.T) {
 
 	require.Equal(t, config, loadedConfig)
 }
+
+func TestConfigStorage_SaveWritesTheFileOnlyAccessibleToTheOwner(t *testing.T) {
+	directory := fixtures.Directory(t)
+
+	storage := adapters.NewConfigStorage(directory)
+
+	exists, err := storage.Exists()
+	require.NoError(t, err)
+	require.False(t, exists)
+
+	err = storage.Save(service.NewDefaultConfig())
+	require.NoError(t, err)
+
+	exists, err = storage.Exists()
+	require.NoError(t, err)
+	require.True(t, exists)
+
+	stat, err := os.Stat(filepath.Join(directory, "config.toml"))
+	require.NoError(t, err)
+	require.Equal(t, os.FileMode(0o600), stat.Mode().Perm())
+}
+
+func TestConfigStorage_WelcomeMessage(t *testing.T) {
+	directory := fixtures.Directory(t)
+
+	storage := adapters.NewConfigStorage(directory)
+
+	config := service.NewDefaultConfig()
+	config.WelcomeMessage = domain.MustNewWelcomeMessageTemplate("Welcome [@{{name}}]({{feed}})!")
+
+	err := storage.Save(config)
+	require.NoError(t, err)
+
+	loadedConfig, err := storage.Load()
+	require.NoError(t, err)
+
+	require.Equal(t, config, loadedConfig)
+}
+
+func TestConfigStorage_PreferredPeers(t *testing.T) {
+	directory := fixtures.Directory(t)
+
+	storage := adapters.NewConfigStorage(directory)
+
+	config := service.NewDefaultConfig()
+	config.PreferredPeers = []domain.MultiserverAddress{
+		domain.MustNewMultiserverAddress("net:example.com:8008~shs:" + base64.StdEncoding.EncodeToString(fixtures.SomePublicIdentity().PublicKey())),
+		domain.MustNewMultiserverAddress("net:192.168.1.10:8008~shs:" + base64.StdEncoding.EncodeToString(fixtures.SomePublicIdentity().PublicKey())),
+	}
+
+	err := storage.Save(config)
+	require.NoError(t, err)
+
+	loadedConfig, err := storage.Load()
+	require.NoError(t, err)
+
+	require.Equal(t, config, loadedConfig)
+}
+
+func TestConfigStorage_LocalNetwork(t *testing.T) {
+	directory := fixtures.Directory(t)
+
+	storage := adapters.NewConfigStorage(directory)
+
+	config := service.NewDefaultConfig()
+	config.LocalAdvertising = false
+	config.LocalDiscovery = false
+	config.LocalNetworkInterfaces = []string{"eth1", "wlan0"}
+
+	err := storage.Save(config)
+	require.NoError(t, err)
+
+	loadedConfig, err := storage.Load()
+	require.NoError(t, err)
+
+	require.Equal(t, config, loadedConfig)
+}
+
+func TestConfigStorage_ShutdownDrainPeriod(t *testing.T) {
+	directory := fixtures.Directory(t)
+
+	storage := adapters.NewConfigStorage(directory)
+
+	config := service.NewDefaultConfig()
+	config.ShutdownDrainPeriod = 30 * time.Second
+
+	err := storage.Save(config)
+	require.NoError(t, err)
+
+	loadedConfig, err := storage.Load()
+	require.NoError(t, err)
+
+	require.Equal(t, config, loadedConfig)
+}
+
+func TestConfigStorage_Logging(t *testing.T) {
+	directory := fixtures.Directory(t)
+
+	storage := adapters.NewConfigStorage(directory)
+
+	config := service.NewDefaultConfig()
+	config.LogComponentLevels = map[service.LogComponent]service.LogLevel{
+		service.LogComponentReplication: service.LogLevelDebug,
+		service.LogComponentBadger:      service.LogLevelTrace,
+	}
+	config.LogFormat = service.LogFormatJSON
+	config.LogFile = filepath.Join(directory, "log")
+
+	err := storage.Save(config)
+	require.NoError(t, err)
+
+	loadedConfig, err := storage.Load()
+	require.NoError(t, err)
+
+	require.Equal(t, config, loadedConfig)
+}
+
+func TestConfigStorage_Badger(t *testing.T) {
+	directory := fixtures.Directory(t)
+
+	storage := adapters.NewConfigStorage(directory)
+
+	config := service.NewDefaultConfig()
+	config.BadgerPreset = service.BadgerPresetLowMemory
+	config.BadgerMemTableSizeMegabytes = 8
+	config.BadgerBlockCacheSizeMegabytes = 16
+	config.BadgerIndexCacheSizeMegabytes = 4
+	config.BadgerValueLogFileSizeMegabytes = 32
+	config.BadgerNumCompactors = 2
+	config.BadgerSyncWrites = false
+
+	err := storage.Save(config)
+	require.NoError(t, err)
+
+	loadedConfig, err := storage.Load()
+	require.NoError(t, err)
+
+	require.Equal(t, config, loadedConfig)
+}
+
+func TestConfigStorage_SecretsAreLoadedFromFiles(t *testing.T) {
+	directory := fixtures.Directory(t)
+
+	networkKey := fixtures.SomeBytesOfLength(boxstream.NetworkKeyLength)
+	networkKeyFile := filepath.Join(directory, "network_key")
+	err := os.WriteFile(networkKeyFile, []byte(hex.EncodeToString(networkKey)+"\n"), 0o600)
+	require.NoError(t, err)
+
+	messageHMAC := fixtures.SomeBytesOfLength(formats.MessageHMACLength)
+	messageHMACFile := filepath.Join(directory, "message_hmac")
+	err = os.WriteFile(messageHMACFile, []byte(base64.StdEncoding.EncodeToString(messageHMAC)), 0o600)
+	require.NoError(t, err)
+
+	storage := adapters.NewConfigStorage(directory)
+
+	config := service.NewDefaultConfig()
+	config.NetworkKeyFile = networkKeyFile
+	config.MessageHMACFile = messageHMACFile
+	config.IdentityFile = filepath.Join(directory, "identity")
+
+	err = storage.Save(config)
+	require.NoError(t, err)
+
+	configFile, err := os.ReadFile(filepath.Join(directory, "config.toml"))
+	require.NoError(t, err)
+	require.NotContains(t, string(configFile), hex.EncodeToString(networkKey))
+
+	loadedConfig, err := storage.Load()
+	require.NoError(t, err)
+
+	expectedConfig := config
+	expectedConfig.NetworkKey = boxstream.MustNewNetworkKey(networkKey)
+	expectedConfig.MessageHMAC = formats.MustNewMessageHMAC(messageHMAC)
+	require.Equal(t, expectedConfig, loadedConfig)
+
+	err = storage.Save(loadedConfig)
+	require.NoError(t, err)
+
+	configFile, err = os.ReadFile(filepath.Join(directory, "config.toml"))
+	require.NoError(t, err)
+	require.Contains(t, string(configFile), "network_key = []\n", "secrets loaded from files shouldn't be written to the config file")
+	require.Contains(t, string(configFile), "message_hmac = []\n", "secrets loaded from files shouldn't be written to the config file")
+}
+
+func TestConfigStorage_RelativeSecretFilesAreResolvedAgainstTheConfigDirectory(t *testing.T) {
+	directory := fixtures.Directory(t)
+
+	networkKey := fixtures.SomeBytesOfLength(boxstream.NetworkKeyLength)
+	err := os.WriteFile(filepath.Join(directory, "network_key"), []byte(hex.EncodeToString(networkKey)), 0o600)
+	require.NoError(t, err)
+
+	storage := adapters.NewConfigStorage(directory)
+
+	config := service.NewDefaultConfig()
+	config.NetworkKeyFile = "network_key"
+
+	err = storage.Save(config)
+	require.NoError(t, err)
+
+	loadedConfig, err := storage.Load()
+	require.NoError(t, err)
+	require.Equal(t, boxstream.MustNewNetworkKey(networkKey), loadedConfig.NetworkKey)
+	require.Equal(t, "network_key", loadedConfig.NetworkKeyFile, "paths should be stored as they were written")
+}
+
+func TestConfigStorage_SecretFilesAccessibleToOtherUsersAreRejected(t *testing.T) {
+	directory := fixtures.Directory(t)
+
+	networkKeyFile := filepath.Join(directory, "network_key")
+	err := os.WriteFile(networkKeyFile, []byte(hex.EncodeToString(fixtures.SomeBytesOfLength(boxstream.NetworkKeyLength))), 0o600)
+	require.NoError(t, err)
+	err = os.Chmod(networkKeyFile, 0o644)
+	require.NoError(t, err)
+
+	storage := adapters.NewConfigStorage(directory)
+
+	config := service.NewDefaultConfig()
+	config.NetworkKeyFile = networkKeyFile
+
+	err = storage.Save(config)
+	require.NoError(t, err)
+
+	_, err = storage.Load()
+	require.ErrorContains(t, err, "error reading the file specified by key 'network_key_file'")
+	require.ErrorContains(t, err, "which allows the group or other users to access it")
+}
+
+func TestConfigStorage_EnvironmentVariablesOverrideConfigFile(t *testing.T) {
+	directory := fixtures.Directory(t)
+
+	storage := adapters.NewConfigStorage(directory)
+
+	err := storage.Save(service.NewDefaultConfig())
+	require.NoError(t, err)
+
+	networkKey := fixtures.SomeBytesOfLength(boxstream.NetworkKeyLength)
+	messageHMAC := fixtures.SomeBytesOfLength(formats.MessageHMACLength)
+
+	t.Setenv("SCUTTLEGO_PUB_LISTEN_ADDRESSES", ":1234,[::1]:1234")
+	t.Setenv("SCUTTLEGO_PUB_HOPS", "3")
+	t.Setenv("SCUTTLEGO_PUB_NETWORK_KEY", hex.EncodeToString(networkKey))
+	t.Setenv("SCUTTLEGO_PUB_MESSAGE_HMAC", base64.StdEncoding.EncodeToString(messageHMAC))
+	t.Setenv("SCUTTLEGO_PUB_WELCOME_MESSAGE", "Welcome {{name}}!")
+	t.Setenv("SCUTTLEGO_PUB_LOG_COMPONENT_LEVELS", "network=trace,invites=debug")
+	t.Setenv("SCUTTLEGO_PUB_BADGER_SYNC_WRITES", "false")
+	t.Setenv(adapters.IdentityPassphraseEnvironmentVariable, "not a config field")
+
+	loadedConfig, err := storage.Load()
+	require.NoError(t, err)
+
+	expectedConfig := service.NewDefaultConfig()
+	expectedConfig.ListenAddresses = []string{":1234", "[::1]:1234"}
+	expectedConfig.Hops = graph.MustNewHops(3)
+	expectedConfig.NetworkKey = boxstream.MustNewNetworkKey(networkKey)
+	expectedConfig.MessageHMAC = formats.MustNewMessageHMAC(messageHMAC)
+	expectedConfig.WelcomeMessage = domain.MustNewWelcomeMessageTemplate("Welcome {{name}}!")
+	expectedConfig.LogComponentLevels = map[service.LogComponent]service.LogLevel{
+		service.LogComponentNetwork: service.LogLevelTrace,
+		service.LogComponentInvites: service.LogLevelDebug,
+	}
+	expectedConfig.BadgerSyncWrites = false
+
+	require.Equal(t, expectedConfig, loadedConfig)
+}
+
+func TestConfigStorage_InvalidEnvironmentVariablesReturnErrors(t *testing.T) {
+	testCases := []struct {
+		Name  string
+		Key   string
+		Value string
+	}{
+		{
+			Name:  "unknown_variable",
+			Key:   "SCUTTLEGO_PUB_UNKNOWN",
+			Value: "value",
+		},
+		{
+			Name:  "invalid_integer",
+			Key:   "SCUTTLEGO_PUB_HOPS",
+			Value: "not a number",
+		},
+		{
+			Name:  "invalid_map",
+			Key:   "SCUTTLEGO_PUB_LOG_COMPONENT_LEVELS",
+			Value: "network",
+		},
+		{
+			Name:  "invalid_bool",
+			Key:   "SCUTTLEGO_PUB_BADGER_SYNC_WRITES",
+			Value: "maybe",
+		},
+		{
+			Name:  "invalid_bytes",
+			Key:   "SCUTTLEGO_PUB_NETWORK_KEY",
+			Value: "not hex or base64!",
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			directory := fixtures.Directory(t)
+
+			storage := adapters.NewConfigStorage(directory)
+
+			err := storage.Save(service.NewDefaultConfig())
+			require.NoError(t, err)
+
+			t.Setenv(testCase.Key, testCase.Value)
+
+			_, err = storage.Load()
+			require.ErrorContains(t, err, testCase.Key)
+		})
+	}
+}
+
+func TestWriteRedactedConfig(t *testing.T) {
+	config := service.NewDefaultConfig()
+	config.Name = "some name"
+
+	buf := &bytes.Buffer{}
+
+	err := adapters.WriteRedactedConfig(buf, config)
+	require.NoError(t, err)
+
+	require.Contains(t, buf.String(), "name = 'some name'\n")
+	require.Contains(t, buf.String(), "network_key = '<redacted>'\n")
+	require.Contains(t, buf.String(), "message_hmac = '<redacted>'\n")
+	require.Contains(t, buf.String(), "listen_addresses = [':8008']\n")
+	require.NotRegexp(t, `(?m)^\[`, buf.String(), "fields shouldn't be written as tables")
+}
+
+func TestConfigStorage_UpgradesConfigWithoutVersion(t *testing.T) {
+	directory := fixtures.Directory(t)
+
+	storage := adapters.NewConfigStorage(directory)
+
+	config := service.NewDefaultConfig()
+
+	err := storage.Save(config)
+	require.NoError(t, err)
+
+	configFilePath := filepath.Join(directory, "config.toml")
+	removeLinesWithPrefix(t, configFilePath, "version =")
+	removeLinesWithPrefix(t, configFilePath, "log_")
+	removeLinesWithPrefix(t, configFilePath, "badger_")
+	removeLinesWithPrefix(t, configFilePath, "local_")
+	removeLinesWithPrefix(t, configFilePath, "shutdown_")
+	removeLinesWithPrefix(t, configFilePath, "listen_addresses =")
+	appendLine(t, configFilePath, "listen_address = ':8008'")
+
+	oldConfigFile, err := os.ReadFile(configFilePath)
+	require.NoError(t, err)
+
+	loadedConfig, err := storage.Load()
+	require.NoError(t, err)
+	require.Equal(t, config, loadedConfig)
+
+	upgradedConfigFile, err := os.ReadFile(configFilePath)
+	require.NoError(t, err)
+	require.Contains(t, string(upgradedConfigFile), "version = 2\n")
+
+	backups, err := filepath.Glob(filepath.Join(directory, "config.toml.v1.*.backup"))
+	require.NoError(t, err)
+	require.Len(t, backups, 1)
+
+	backup, err := os.ReadFile(backups[0])
+	require.NoError(t, err)
+	require.Equal(t, oldConfigFile, backup)
+
+	loadedConfig, err = storage.Load()
+	require.NoError(t, err)
+	require.Equal(t, config, loadedConfig)
+
+	backups, err = filepath.Glob(filepath.Join(directory, "config.toml.*.backup"))
+	require.NoError(t, err)
+	require.Len(t, backups, 1, "up to date config shouldn't be backed up")
+}
+
+func TestConfigStorage_InvalidConfigFilesReturnErrorsNamingTheKey(t *testing.T) {
+	testCases := []struct {
+		Name          string
+		Line          string
+		ExpectedError string
+	}{
+		{
+			Name:          "unknown_key",
+			Line:          `some_unknown_key = 'value'`,
+			ExpectedError: "unknown keys: 'some_unknown_key' on line",
+		},
+		{
+			Name:          "invalid_type",
+			Line:          `hops = 'value'`,
+			ExpectedError: "invalid value of key 'hops'",
+		},
+		{
+			Name:          "newer_version",
+			Line:          `version = 1000`,
+			ExpectedError: "config version 1000 is newer than version 2 supported by this program",
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			directory := fixtures.Directory(t)
+
+			storage := adapters.NewConfigStorage(directory)
+
+			err := storage.Save(service.NewDefaultConfig())
+			require.NoError(t, err)
+
+			configFilePath := filepath.Join(directory, "config.toml")
+			key, _, _ := strings.Cut(testCase.Line, " ")
+			removeLinesWithPrefix(t, configFilePath, key+" =")
+
+			appendLine(t, configFilePath, testCase.Line)
+
+			_, err = storage.Load()
+			require.ErrorContains(t, err, testCase.ExpectedError)
+		})
+	}
+}
+
+func appendLine(t *testing.T, path string, line string) {
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0)
+	require.NoError(t, err)
+	_, err = f.WriteString(line + "\n")
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+}
+
+func removeLinesWithPrefix(t *testing.T, path string, prefix string) {
+	b, err := os.ReadFile(path)
+	require.NoError(t, err)
+
+	var lines []string
+	for _, line := range strings.Split(string(b), "\n") {
+		if !strings.HasPrefix(line, prefix) {
+			lines = append(lines, line)
+		}
+	}
+
+	err = os.WriteFile(path, []byte(strings.Join(lines, "\n")), 0o600)
+	require.NoError(t, err)
+}
+
+func TestConfigStorage_LoadWithoutUpgradingAndCheckDoNotModifyTheConfigFile(t *testing.T) {
+	directory := fixtures.Directory(t)
+
+	storage := adapters.NewConfigStorage(directory)
+
+	config := service.NewDefaultConfig()
+	config.DataDirectory = directory
+
+	err := storage.Save(config)
+	require.NoError(t, err)
+
+	configFilePath := filepath.Join(directory, "config.toml")
+	removeLinesWithPrefix(t, configFilePath, "version =")
+	removeLinesWithPrefix(t, configFilePath, "shutdown_")
+
+	oldConfigFile, err := os.ReadFile(configFilePath)
+	require.NoError(t, err)
+
+	loadedConfig, err := storage.LoadWithoutUpgrading()
+	require.NoError(t, err)
+	require.Equal(t, config, loadedConfig)
+
+	err = storage.Check()
+	require.NoError(t, err)
+
+	configFile, err := os.ReadFile(configFilePath)
+	require.NoError(t, err)
+	require.Equal(t, oldConfigFile, configFile)
+
+	backups, err := filepath.Glob(filepath.Join(directory, "config.toml.*.backup"))
+	require.NoError(t, err)
+	require.Empty(t, backups)
+}
+
+func TestConfigStorage_LoadPathsDoesNotReadSecretsOrModifyTheConfigFile(t *testing.T) {
+	directory := fixtures.Directory(t)
+
+	storage := adapters.NewConfigStorage(directory)
+
+	config := service.NewDefaultConfig()
+	config.DataDirectory = directory
+	config.AdminSocket = "/run/pub/admin.sock"
+	config.NetworkKeyFile = "fd:1000"
+	config.MessageHMACFile = filepath.Join(directory, "missing")
+
+	err := storage.Save(config)
+	require.NoError(t, err)
+
+	configFilePath := filepath.Join(directory, "config.toml")
+	removeLinesWithPrefix(t, configFilePath, "version =")
+
+	oldConfigFile, err := os.ReadFile(configFilePath)
+	require.NoError(t, err)
+
+	t.Setenv("SCUTTLEGO_PUB_ADMIN_SOCKET", "/run/other/admin.sock")
+	t.Setenv("SCUTTLEGO_PUB_HOPS", "invalid")
+
+	loadedConfig, err := storage.LoadPaths()
+	require.NoError(t, err)
+	require.Equal(t,
+		service.Config{
+			DataDirectory: directory,
+			AdminSocket:   "/run/other/admin.sock",
+		},
+		loadedConfig,
+	)
+
+	configFile, err := os.ReadFile(configFilePath)
+	require.NoError(t, err)
+	require.Equal(t, oldConfigFile, configFile)
+}
+
+func TestConfigStorage_CheckReportsAllProblems(t *testing.T) {
+	directory := fixtures.Directory(t)
+
+	storage := adapters.NewConfigStorage(directory)
+
+	config := service.NewDefaultConfig()
+	config.DataDirectory = directory
+
+	err := storage.Save(config)
+	require.NoError(t, err)
+
+	configFilePath := filepath.Join(directory, "config.toml")
+	removeLinesWithPrefix(t, configFilePath, "log_level =")
+	removeLinesWithPrefix(t, configFilePath, "preferred_peers =")
+	removeLinesWithPrefix(t, configFilePath, "shutdown_drain_period_seconds =")
+	appendLine(t, configFilePath, "log_level = 'invalid'")
+	appendLine(t, configFilePath, "preferred_peers = ['invalid']")
+	appendLine(t, configFilePath, "shutdown_drain_period_seconds = 0")
+
+	err = storage.Check()
+
+	var validationErr *service.ConfigValidationError
+	require.ErrorAs(t, err, &validationErr)
+	require.Len(t, validationErr.Problems, 3)
+	require.ErrorContains(t, err, "invalid value of key 'log_level'")
+	require.ErrorContains(t, err, "invalid value of key 'preferred_peers'")
+	require.ErrorContains(t, err, "shutdown drain period")
+
+	_, err = storage.Load()
+	require.ErrorContains(t, err, "invalid value of key 'log_level'")
+	require.ErrorContains(t, err, "invalid value of key 'preferred_peers'")
+}
@@ -0,0 +1,149 @@
+package adapters
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/boreq/errors"
+	"github.com/pelletier/go-toml/v2"
+	"github.com/planetary-social/scuttlego-pub/service"
+)
+
+// configUpgrades contains functions which upgrade the config file from one
+// version to the next one, the function at index i upgrades the config from
+// version i+1 to version i+2. Functions operate on the raw contents of the file
+// as the old format may not be compatible with storedConfig. To change the
+// format of the config file add a new function at the end of the list.
+var configUpgrades = []configUpgrade{
+	upgradeConfigFromVersion1,
+}
+
+var currentConfigVersion = len(configUpgrades) + 1
+
+type configUpgrade func(config map[string]any) error
+
+// upgradeConfigFromVersion1 upgrades config files created before the version
+// field was introduced. The single listen address is replaced with a list of
+// listen addresses and the options added since then are set to their
+// defaults. Previously only errors were logged to the standard error as text,
+// the database used the default options, local advertising and discovery were
+// enabled on all network interfaces and the pub was stopped immediately on
+// signals.
+func upgradeConfigFromVersion1(config map[string]any) error {
+	defaults := service.NewDefaultConfig()
+
+	if address, ok := config["listen_address"]; ok {
+		if _, ok := address.(string); !ok {
+			return errors.New("listen_address must be a string")
+		}
+
+		delete(config, "listen_address")
+		config["listen_addresses"] = []any{address}
+	}
+
+	setIfMissing(config, "log_level", service.LogLevelError.String())
+	setIfMissing(config, "log_component_levels", map[string]any{})
+	setIfMissing(config, "log_format", defaults.LogFormat.String())
+	setIfMissing(config, "log_file_max_size_megabytes", defaults.LogFileMaxSizeMegabytes)
+	setIfMissing(config, "log_file_max_backups", defaults.LogFileMaxBackups)
+	setIfMissing(config, "badger_preset", defaults.BadgerPreset.String())
+	setIfMissing(config, "badger_sync_writes", defaults.BadgerSyncWrites)
+	setIfMissing(config, "local_advertising", defaults.LocalAdvertising)
+	setIfMissing(config, "local_discovery", defaults.LocalDiscovery)
+	setIfMissing(config, "shutdown_drain_period_seconds", int(defaults.ShutdownDrainPeriod/time.Second))
+	return nil
+}
+
+// setIfMissing sets the key to the provided value unless the config already
+// contains it.
+func setIfMissing(config map[string]any, key string, value any) {
+	if _, ok := config[key]; !ok {
+		config[key] = value
+	}
+}
+
+func upgradeConfig(config map[string]any, version int) error {
+	for ; version < currentConfigVersion; version++ {
+		if err := configUpgrades[version-1](config); err != nil {
+			return errors.Wrapf(err, "error upgrading from version %d", version)
+		}
+		config["version"] = version + 1
+	}
+	return nil
+}
+
+// configVersion returns the version of the config file. Files created before
+// the version field was introduced have version 1.
+func configVersion(config map[string]any) (int, error) {
+	v, ok := config["version"]
+	if !ok {
+		return 1, nil
+	}
+
+	version, ok := v.(int64)
+	if !ok {
+		return 0, errors.New("version must be an integer")
+	}
+
+	if version < 1 {
+		return 0, errors.New("version must be positive")
+	}
+
+	return int(version), nil
+}
+
+// describeDecodeError converts errors returned by the toml decoder to errors
+// which name the offending keys. Positions are only included if the decoded
+// document is the original file.
+func describeDecodeError(err error, document []byte, includePositions bool) error {
+	var strictMissingErr *toml.StrictMissingError
+	if errors.As(err, &strictMissingErr) {
+		var keys []string
+		for _, decodeErr := range strictMissingErr.Errors {
+			keys = append(keys, describeDecodeErrorKey(&decodeErr, document, includePositions))
+		}
+		return fmt.Errorf("unknown keys: %s", strings.Join(keys, ", "))
+	}
+
+	var decodeErr *toml.DecodeError
+	if errors.As(err, &decodeErr) {
+		if key := describeDecodeErrorKey(decodeErr, document, includePositions); key != "" {
+			return fmt.Errorf("invalid value of key %s: %w", key, err)
+		}
+		if includePositions {
+			row, column := decodeErr.Position()
+			return fmt.Errorf("syntax error on line %d column %d: %w", row, column, err)
+		}
+	}
+
+	return errors.Wrap(err, "error decoding toml")
+}
+
+// describeDecodeErrorKey returns an empty string if the key can't be
+// determined. The decoder doesn't report keys when values have incorrect
+// types so in that case the key is read from the line on which the error
+// occurred.
+func describeDecodeErrorKey(decodeErr *toml.DecodeError, document []byte, includePositions bool) string {
+	row, _ := decodeErr.Position()
+
+	key := strings.Join(decodeErr.Key(), ".")
+	if key == "" {
+		lines := strings.Split(string(document), "\n")
+		if row < 1 || row > len(lines) {
+			return ""
+		}
+
+		lineKey, _, ok := strings.Cut(lines[row-1], "=")
+		if !ok {
+			return ""
+		}
+
+		key = strings.TrimSpace(lineKey)
+	}
+
+	if !includePositions {
+		return fmt.Sprintf("'%s'", key)
+	}
+	return fmt.Sprintf("'%s' on line %d", key, row)
+}
@@ -0,0 +1,168 @@
+package adapters
+
+import (
+	"context"
+	"io"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/planetary-social/scuttlego-pub/service/app/queries"
+	"github.com/planetary-social/scuttlego/service/domain/identity"
+	"github.com/planetary-social/scuttlego/service/domain/transport"
+	"github.com/planetary-social/scuttlego/service/domain/transport/boxstream"
+)
+
+type PeerInitializer interface {
+	InitializeServerPeer(ctx context.Context, rwc io.ReadWriteCloser) (transport.Peer, error)
+	InitializeClientPeer(ctx context.Context, rwc io.ReadWriteCloser, remote identity.Public) (transport.Peer, error)
+}
+
+// ConnectionTrackingPeerInitializer remembers the remote addresses of
+// initialized connections and when they were established as that information
+// isn't available in transport.Peer. A connection is forgotten once its
+// underlying connection is closed.
+//
+// Connections stay open until CloseAll is called even if the context passed
+// when initializing them is cancelled. This way listeners and other parts of
+// the pub which establish connections can be stopped without closing the
+// connections which are still replicating.
+type ConnectionTrackingPeerInitializer struct {
+	initializer PeerInitializer
+
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	lock        sync.Mutex
+	connections map[*trackedConnection]queries.Connection
+}
+
+func NewConnectionTrackingPeerInitializer(initializer PeerInitializer) *ConnectionTrackingPeerInitializer {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &ConnectionTrackingPeerInitializer{
+		initializer: initializer,
+		ctx:         ctx,
+		cancel:      cancel,
+		connections: make(map[*trackedConnection]queries.Connection),
+	}
+}
+
+func (i *ConnectionTrackingPeerInitializer) InitializeServerPeer(ctx context.Context, rwc io.ReadWriteCloser) (transport.Peer, error) {
+	conn := i.newTrackedConnection(rwc)
+
+	peer, err := i.initializer.InitializeServerPeer(newConnectionContext(ctx, i.ctx), conn)
+	if err != nil {
+		return peer, err
+	}
+
+	i.add(conn, peer)
+	return peer, nil
+}
+
+func (i *ConnectionTrackingPeerInitializer) InitializeClientPeer(ctx context.Context, rwc io.ReadWriteCloser, remote identity.Public) (transport.Peer, error) {
+	conn := i.newTrackedConnection(rwc)
+
+	peer, err := i.initializer.InitializeClientPeer(newConnectionContext(ctx, i.ctx), conn, remote)
+	if err != nil {
+		return peer, err
+	}
+
+	i.add(conn, peer)
+	return peer, nil
+}
+
+// Connections returns the open connections in no particular order.
+func (i *ConnectionTrackingPeerInitializer) Connections() []queries.Connection {
+	i.lock.Lock()
+	defer i.lock.Unlock()
+
+	result := make([]queries.Connection, 0, len(i.connections))
+	for _, connection := range i.connections {
+		result = append(result, connection)
+	}
+	return result
+}
+
+// CloseAll closes all connections including the ones initialized later.
+func (i *ConnectionTrackingPeerInitializer) CloseAll() {
+	i.cancel()
+}
+
+func (i *ConnectionTrackingPeerInitializer) newTrackedConnection(rwc io.ReadWriteCloser) *trackedConnection {
+	conn := &trackedConnection{ReadWriteCloser: rwc}
+	conn.onClose = func() {
+		i.lock.Lock()
+		defer i.lock.Unlock()
+
+		conn.closed = true
+		delete(i.connections, conn)
+	}
+	return conn
+}
+
+func (i *ConnectionTrackingPeerInitializer) add(conn *trackedConnection, peer transport.Peer) {
+	i.lock.Lock()
+	defer i.lock.Unlock()
+
+	if conn.closed {
+		return
+	}
+
+	i.connections[conn] = queries.Connection{
+		Identity:          peer.Identity(),
+		Address:           remoteAddress(conn.ReadWriteCloser),
+		ConnectedAt:       time.Now(),
+		InitiatedByRemote: peer.Conn().WasInitiatedByRemote(),
+	}
+}
+
+// remoteAddress returns an empty string for connections which don't have a
+// network address e.g. connections tunneled through rooms.
+func remoteAddress(rwc io.ReadWriteCloser) string {
+	if v, ok := rwc.(interface{ RemoteAddr() net.Addr }); ok {
+		return v.RemoteAddr().String()
+	}
+	return ""
+}
+
+// connectionContext carries the values of the context passed when initializing
+// a connection but is only cancelled once all connections are closed.
+type connectionContext struct {
+	context.Context
+	values context.Context
+}
+
+func newConnectionContext(values context.Context, lifetime context.Context) connectionContext {
+	return connectionContext{
+		Context: lifetime,
+		values:  values,
+	}
+}
+
+func (c connectionContext) Value(key any) any {
+	return c.values.Value(key)
+}
+
+// trackedConnection calls onClose when it is closed for the first time. The
+// field closed is protected by the lock of ConnectionTrackingPeerInitializer.
+type trackedConnection struct {
+	io.ReadWriteCloser
+
+	closeOnce sync.Once
+	onClose   func()
+	closed    bool
+}
+
+func (c *trackedConnection) Close() error {
+	c.closeOnce.Do(c.onClose)
+	return c.ReadWriteCloser.Close()
+}
+
+// SetDeadline is used by the handshake to time out. It does nothing if the
+// underlying connection doesn't support deadlines.
+func (c *trackedConnection) SetDeadline(t time.Time) error {
+	if v, ok := c.ReadWriteCloser.(boxstream.SetDeadliner); ok {
+		return v.SetDeadline(t)
+	}
+	return nil
+}
@@ -0,0 +1,122 @@
+package adapters_test
+
+import (
+	"context"
+	"errors"
+	"net"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/planetary-social/scuttlego-pub/internal/fixtures"
+	"github.com/planetary-social/scuttlego-pub/internal/mocks"
+	"github.com/planetary-social/scuttlego-pub/service/adapters"
+	domainmocks "github.com/planetary-social/scuttlego/service/domain/mocks"
+	"github.com/planetary-social/scuttlego/service/domain/transport"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConnectionTrackingPeerInitializer_ConnectionsAreForgottenWhenClosed(t *testing.T) {
+	testCases := []struct {
+		Name       string
+		Initialize func(initializer *adapters.ConnectionTrackingPeerInitializer, conn net.Conn) error
+	}{
+		{
+			Name: "server",
+			Initialize: func(initializer *adapters.ConnectionTrackingPeerInitializer, conn net.Conn) error {
+				_, err := initializer.InitializeServerPeer(context.Background(), conn)
+				return err
+			},
+		},
+		{
+			Name: "client",
+			Initialize: func(initializer *adapters.ConnectionTrackingPeerInitializer, conn net.Conn) error {
+				_, err := initializer.InitializeClientPeer(context.Background(), conn, fixtures.SomePublicIdentity())
+				return err
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			peer := transport.MustNewPeer(fixtures.SomePublicIdentity(), domainmocks.NewConnectionMock(context.Background()))
+
+			mock := mocks.NewPeerInitializerMock()
+			mock.InitializeReturnValue = peer
+			initializer := adapters.NewConnectionTrackingPeerInitializer(mock)
+
+			conn, _ := net.Pipe()
+
+			err := testCase.Initialize(initializer, conn)
+			require.NoError(t, err)
+
+			connections := initializer.Connections()
+			require.Len(t, connections, 1)
+			require.Equal(t, peer.Identity(), connections[0].Identity)
+			require.Equal(t, conn.RemoteAddr().String(), connections[0].Address)
+			require.WithinDuration(t, time.Now(), connections[0].ConnectedAt, time.Minute)
+			require.Equal(t, peer.Conn().WasInitiatedByRemote(), connections[0].InitiatedByRemote)
+
+			require.Len(t, mock.Rwcs, 1)
+			require.NoError(t, mock.Rwcs[0].Close())
+			require.Empty(t, initializer.Connections())
+		})
+	}
+}
+
+func TestConnectionTrackingPeerInitializer_ConnectionsWhichFailedToInitializeAreNotTracked(t *testing.T) {
+	mock := mocks.NewPeerInitializerMock()
+	mock.InitializeReturnError = errors.New("handshake failed")
+	initializer := adapters.NewConnectionTrackingPeerInitializer(mock)
+
+	conn, _ := net.Pipe()
+
+	_, err := initializer.InitializeServerPeer(context.Background(), conn)
+	require.Error(t, err)
+
+	require.Empty(t, initializer.Connections())
+}
+
+func TestConnectionTrackingPeerInitializer_ConnectionsStayOpenUntilCloseAllIsCalled(t *testing.T) {
+	mock := mocks.NewPeerInitializerMock()
+	mock.InitializeReturnValue = transport.MustNewPeer(fixtures.SomePublicIdentity(), domainmocks.NewConnectionMock(context.Background()))
+	initializer := adapters.NewConnectionTrackingPeerInitializer(mock)
+
+	type someKey struct{}
+	ctx, cancel := context.WithCancel(context.WithValue(context.Background(), someKey{}, "some value"))
+
+	conn, _ := net.Pipe()
+
+	_, err := initializer.InitializeServerPeer(ctx, conn)
+	require.NoError(t, err)
+
+	require.Len(t, mock.Ctxs, 1)
+	connectionCtx := mock.Ctxs[0]
+	require.Equal(t, "some value", connectionCtx.Value(someKey{}))
+
+	cancel()
+	require.NoError(t, connectionCtx.Err(), "connection shouldn't be closed together with the context used to establish it")
+
+	initializer.CloseAll()
+	require.ErrorIs(t, connectionCtx.Err(), context.Canceled)
+}
+
+func TestConnectionTrackingPeerInitializer_DeadlinesArePassedToTheConnection(t *testing.T) {
+	mock := mocks.NewPeerInitializerMock()
+	mock.InitializeReturnValue = transport.MustNewPeer(fixtures.SomePublicIdentity(), domainmocks.NewConnectionMock(context.Background()))
+	initializer := adapters.NewConnectionTrackingPeerInitializer(mock)
+
+	conn, _ := net.Pipe()
+
+	_, err := initializer.InitializeServerPeer(context.Background(), conn)
+	require.NoError(t, err)
+
+	deadliner, ok := mock.Rwcs[0].(interface{ SetDeadline(time.Time) error })
+	require.True(t, ok)
+
+	err = deadliner.SetDeadline(time.Now().Add(-time.Second))
+	require.NoError(t, err)
+
+	_, err = mock.Rwcs[0].Read(make([]byte, 1))
+	require.ErrorIs(t, err, os.ErrDeadlineExceeded)
+}
@@ -1,49 +1,127 @@
 package adapters
 
 import (
+	"crypto/cipher"
+	"crypto/rand"
+	"fmt"
 	"os"
 	"path/filepath"
 
 	"github.com/boreq/errors"
 	"github.com/pelletier/go-toml/v2"
 	"github.com/planetary-social/scuttlego/service/domain/identity"
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/chacha20poly1305"
 )
 
+// ErrIdentityEncrypted is returned by IdentityStorage.Load if the identity
+// file is encrypted. See IdentityStorage.LoadEncrypted.
+var ErrIdentityEncrypted = errors.New("identity is encrypted with a passphrase")
+
+// ErrIdentityExists is returned by IdentityStorage.Create and
+// IdentityStorage.CreateEncrypted if the identity file already exists.
+var ErrIdentityExists = errors.New("identity already exists")
+
+const (
+	identityKDFArgon2id             = "argon2id"
+	identityCipherXChaCha20Poly1305 = "xchacha20poly1305"
+
+	identityKDFSaltLength      = 16
+	identityKDFTime            = 3
+	identityKDFMemoryKibibytes = 64 * 1024
+	identityKDFThreads         = 4
+
+	// Limits on the parameters read from identity files which prevent a
+	// modified file from making the pub use excessive resources.
+	identityKDFMinSaltLength      = 16
+	identityKDFMaxSaltLength      = 64
+	identityKDFMaxTime            = 16
+	identityKDFMaxMemoryKibibytes = 1024 * 1024
+	identityKDFMaxThreads         = 64
+)
+
+const identityFileName = "identity.toml"
+
 type IdentityStorage struct {
-	directory string
+	file   string
+	reader *secretFileReader
 }
 
+// NewIdentityStorage stores the identity in the provided directory.
 func NewIdentityStorage(directory string) *IdentityStorage {
-	return &IdentityStorage{directory: directory}
+	return NewIdentityStorageWithFile(filepath.Join(directory, identityFileName))
 }
 
+// NewIdentityStorageWithFile stores the identity in the provided file which
+// can also be a file descriptor. See ReadSecretFile. Identities can't be saved
+// to file descriptors.
+func NewIdentityStorageWithFile(file string) *IdentityStorage {
+	return &IdentityStorage{
+		file:   file,
+		reader: newSecretFileReader(),
+	}
+}
+
+// Save replaces the identity file with one storing the private key in
+// plaintext. See Create.
 func (s *IdentityStorage) Save(iden identity.Private) error {
-	storedIden := storedIdentity{
-		PrivateKey: iden.PrivateKey(),
+	return s.save(newPlaintextStoredIdentity(iden), true)
+}
+
+// SaveEncrypted replaces the identity file with one storing the private key
+// encrypted with a key derived from the passphrase using argon2id. See
+// CreateEncrypted.
+func (s *IdentityStorage) SaveEncrypted(iden identity.Private, passphrase []byte) error {
+	storedIden, err := newEncryptedStoredIdentity(iden, passphrase)
+	if err != nil {
+		return errors.Wrap(err, "error encrypting the identity")
 	}
+	return s.save(storedIden, true)
+}
 
-	f, err := os.OpenFile(s.identityFilePath(), os.O_WRONLY|os.O_CREATE, 0o700)
+// Create works like Save but returns ErrIdentityExists instead of replacing
+// the identity file if it already exists.
+func (s *IdentityStorage) Create(iden identity.Private) error {
+	return s.save(newPlaintextStoredIdentity(iden), false)
+}
+
+// CreateEncrypted works like SaveEncrypted but returns ErrIdentityExists
+// instead of replacing the identity file if it already exists.
+func (s *IdentityStorage) CreateEncrypted(iden identity.Private, passphrase []byte) error {
+	storedIden, err := newEncryptedStoredIdentity(iden, passphrase)
 	if err != nil {
-		return errors.Wrap(err, "open file error")
+		return errors.Wrap(err, "error encrypting the identity")
 	}
+	return s.save(storedIden, false)
+}
 
-	if err := toml.NewEncoder(f).Encode(storedIden); err != nil {
-		return errors.Wrap(err, "error encoding toml")
+// Exists checks if the identity file exists. File descriptors always exist.
+func (s *IdentityStorage) Exists() (bool, error) {
+	if IsSecretFileDescriptor(s.file) {
+		return true, nil
 	}
+	return fileExists(s.file)
+}
 
-	return nil
+// IsEncrypted checks if the identity file is encrypted with a passphrase.
+func (s *IdentityStorage) IsEncrypted() (bool, error) {
+	storedIden, err := s.load()
+	if err != nil {
+		return false, errors.Wrap(err, "error loading the identity file")
+	}
+
+	return storedIden.isEncrypted(), nil
 }
 
+// Load returns ErrIdentityEncrypted if the identity file is encrypted.
 func (s *IdentityStorage) Load() (identity.Private, error) {
-	var storedIden storedIdentity
-
-	f, err := os.Open(s.identityFilePath())
+	storedIden, err := s.load()
 	if err != nil {
-		return identity.Private{}, errors.Wrap(err, "open file error")
+		return identity.Private{}, errors.Wrap(err, "error loading the identity file")
 	}
 
-	if err = toml.NewDecoder(f).Decode(&storedIden); err != nil {
-		return identity.Private{}, errors.Wrap(err, "error decoding toml")
+	if storedIden.isEncrypted() {
+		return identity.Private{}, ErrIdentityEncrypted
 	}
 
 	iden, err := identity.NewPrivateFromBytes(storedIden.PrivateKey)
@@ -54,10 +132,175 @@ func (s *IdentityStorage) Load() (identity.Private, error) {
 	return iden, nil
 }
 
-func (s *IdentityStorage) identityFilePath() string {
-	return filepath.Join(s.directory, "identity.toml")
+// LoadEncrypted loads an identity file created with SaveEncrypted.
+func (s *IdentityStorage) LoadEncrypted(passphrase []byte) (identity.Private, error) {
+	storedIden, err := s.load()
+	if err != nil {
+		return identity.Private{}, errors.Wrap(err, "error loading the identity file")
+	}
+
+	if !storedIden.isEncrypted() {
+		return identity.Private{}, errors.New("identity is not encrypted")
+	}
+
+	aead, err := newIdentityAEAD(storedIden, passphrase)
+	if err != nil {
+		return identity.Private{}, errors.Wrap(err, "error creating the cipher")
+	}
+
+	if len(storedIden.EncryptedPrivateKey) < aead.NonceSize() {
+		return identity.Private{}, errors.New("encrypted private key is too short")
+	}
+
+	nonce := storedIden.EncryptedPrivateKey[:aead.NonceSize()]
+	ciphertext := storedIden.EncryptedPrivateKey[aead.NonceSize():]
+
+	privateKey, err := aead.Open(nil, nonce, ciphertext, nil)
+	if err != nil {
+		return identity.Private{}, errors.New("invalid passphrase or corrupted identity file")
+	}
+
+	iden, err := identity.NewPrivateFromBytes(privateKey)
+	if err != nil {
+		return identity.Private{}, errors.Wrap(err, "error creating private identity from bytes")
+	}
+
+	return iden, nil
+}
+
+func (s *IdentityStorage) save(storedIden storedIdentity, overwrite bool) error {
+	if IsSecretFileDescriptor(s.file) {
+		return errors.New("identity can't be saved to a file descriptor")
+	}
+
+	b, err := toml.Marshal(storedIden)
+	if err != nil {
+		return errors.Wrap(err, "error encoding toml")
+	}
+
+	if overwrite {
+		if err := writeFileAtomically(s.file, b); err != nil {
+			return errors.Wrap(err, "error writing the file")
+		}
+		return nil
+	}
+
+	if err := createFile(s.file, b); err != nil {
+		if errors.Is(err, os.ErrExist) {
+			return ErrIdentityExists
+		}
+		return errors.Wrap(err, "error creating the file")
+	}
+
+	return nil
+}
+
+func (s *IdentityStorage) load() (storedIdentity, error) {
+	b, err := s.reader.Read(s.file)
+	if err != nil {
+		return storedIdentity{}, errors.Wrap(err, "error reading the file")
+	}
+
+	var storedIden storedIdentity
+	if err = toml.Unmarshal(b, &storedIden); err != nil {
+		return storedIdentity{}, errors.Wrap(err, "error decoding toml")
+	}
+
+	return storedIden, nil
+}
+
+func newPlaintextStoredIdentity(iden identity.Private) storedIdentity {
+	return storedIdentity{
+		PrivateKey: iden.PrivateKey(),
+	}
+}
+
+func newEncryptedStoredIdentity(iden identity.Private, passphrase []byte) (storedIdentity, error) {
+	if len(passphrase) == 0 {
+		return storedIdentity{}, errors.New("passphrase is empty")
+	}
+
+	salt := make([]byte, identityKDFSaltLength)
+	if _, err := rand.Read(salt); err != nil {
+		return storedIdentity{}, errors.Wrap(err, "error generating the salt")
+	}
+
+	storedIden := storedIdentity{
+		KDF:                identityKDFArgon2id,
+		KDFSalt:            salt,
+		KDFTime:            identityKDFTime,
+		KDFMemoryKibibytes: identityKDFMemoryKibibytes,
+		KDFThreads:         identityKDFThreads,
+		Cipher:             identityCipherXChaCha20Poly1305,
+	}
+
+	aead, err := newIdentityAEAD(storedIden, passphrase)
+	if err != nil {
+		return storedIdentity{}, errors.Wrap(err, "error creating the cipher")
+	}
+
+	nonce := make([]byte, aead.NonceSize(), aead.NonceSize()+len(iden.PrivateKey())+aead.Overhead())
+	if _, err := rand.Read(nonce); err != nil {
+		return storedIdentity{}, errors.Wrap(err, "error generating the nonce")
+	}
+
+	storedIden.EncryptedPrivateKey = aead.Seal(nonce, nonce, iden.PrivateKey(), nil)
+
+	return storedIden, nil
+}
+
+func newIdentityAEAD(storedIden storedIdentity, passphrase []byte) (cipher.AEAD, error) {
+	if storedIden.KDF != identityKDFArgon2id {
+		return nil, fmt.Errorf("unsupported kdf '%s'", storedIden.KDF)
+	}
+
+	if storedIden.Cipher != identityCipherXChaCha20Poly1305 {
+		return nil, fmt.Errorf("unsupported cipher '%s'", storedIden.Cipher)
+	}
+
+	if l := len(storedIden.KDFSalt); l < identityKDFMinSaltLength || l > identityKDFMaxSaltLength {
+		return nil, fmt.Errorf("kdf salt length must be between %d and %d bytes", identityKDFMinSaltLength, identityKDFMaxSaltLength)
+	}
+
+	if storedIden.KDFTime <= 0 || storedIden.KDFTime > identityKDFMaxTime {
+		return nil, fmt.Errorf("kdf time must be between 1 and %d", identityKDFMaxTime)
+	}
+
+	if storedIden.KDFMemoryKibibytes <= 0 || storedIden.KDFMemoryKibibytes > identityKDFMaxMemoryKibibytes {
+		return nil, fmt.Errorf("kdf memory must be between 1 and %d KiB", identityKDFMaxMemoryKibibytes)
+	}
+
+	if storedIden.KDFThreads <= 0 || storedIden.KDFThreads > identityKDFMaxThreads {
+		return nil, fmt.Errorf("kdf threads must be between 1 and %d", identityKDFMaxThreads)
+	}
+
+	key := argon2.IDKey(
+		passphrase,
+		storedIden.KDFSalt,
+		uint32(storedIden.KDFTime),
+		uint32(storedIden.KDFMemoryKibibytes),
+		uint8(storedIden.KDFThreads),
+		chacha20poly1305.KeySize,
+	)
+
+	return chacha20poly1305.NewX(key)
 }
 
+// storedIdentity stores either the private key in plaintext or the private
+// key encrypted with a key derived from a passphrase. The encrypted private
+// key is prefixed with the nonce.
 type storedIdentity struct {
-	PrivateKey []byte `toml:"private_key" comment:"Never share your private key with anyone."`
+	PrivateKey []byte `toml:"private_key,omitempty" comment:"Never share your private key with anyone."`
+
+	EncryptedPrivateKey []byte `toml:"encrypted_private_key,omitempty" comment:"Private key encrypted with a passphrase. Never share it with anyone."`
+	KDF                 string `toml:"kdf,omitempty"`
+	KDFSalt             []byte `toml:"kdf_salt,omitempty"`
+	KDFTime             int    `toml:"kdf_time,omitempty"`
+	KDFMemoryKibibytes  int    `toml:"kdf_memory_kibibytes,omitempty"`
+	KDFThreads          int    `toml:"kdf_threads,omitempty"`
+	Cipher              string `toml:"cipher,omitempty"`
+}
+
+func (s storedIdentity) isEncrypted() bool {
+	return len(s.EncryptedPrivateKey) > 0
 }
@@ -1,6 +1,10 @@
 package adapters_test
 
 import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/planetary-social/scuttlego-pub/internal/fixtures"
@@ -23,3 +27,225 @@ func TestIdentityStorage(t *testing.T) {
 
 	require.Equal(t, iden, loadedIden)
 }
+
+func TestIdentityStorage_SaveReplacesTheFile(t *testing.T) {
+	directory := fixtures.Directory(t)
+
+	identityFilePath := filepath.Join(directory, "identity.toml")
+	err := os.WriteFile(identityFilePath, bytes.Repeat([]byte("#"), 10000), 0o644)
+	require.NoError(t, err)
+
+	storage := adapters.NewIdentityStorage(directory)
+
+	iden := fixtures.SomePrivateIdentity()
+
+	err = storage.Save(iden)
+	require.NoError(t, err)
+
+	loadedIden, err := storage.Load()
+	require.NoError(t, err)
+	require.Equal(t, iden, loadedIden)
+
+	stat, err := os.Stat(identityFilePath)
+	require.NoError(t, err)
+	require.Equal(t, os.FileMode(0o600), stat.Mode().Perm())
+
+	files, err := os.ReadDir(directory)
+	require.NoError(t, err)
+	require.Len(t, files, 1, "temporary files should be removed")
+}
+
+func TestIdentityStorage_CreateDoesNotReplaceTheFile(t *testing.T) {
+	directory := fixtures.Directory(t)
+
+	storage := adapters.NewIdentityStorage(directory)
+
+	iden := fixtures.SomePrivateIdentity()
+
+	err := storage.Create(iden)
+	require.NoError(t, err)
+
+	stat, err := os.Stat(filepath.Join(directory, "identity.toml"))
+	require.NoError(t, err)
+	require.Equal(t, os.FileMode(0o600), stat.Mode().Perm())
+
+	err = storage.Create(fixtures.SomePrivateIdentity())
+	require.ErrorIs(t, err, adapters.ErrIdentityExists)
+
+	err = storage.CreateEncrypted(fixtures.SomePrivateIdentity(), []byte("some passphrase"))
+	require.ErrorIs(t, err, adapters.ErrIdentityExists)
+
+	loadedIden, err := storage.Load()
+	require.NoError(t, err)
+	require.Equal(t, iden, loadedIden)
+
+	entries, err := os.ReadDir(directory)
+	require.NoError(t, err)
+	require.Len(t, entries, 1, "temporary files should be removed")
+}
+
+func TestIdentityStorage_Exists(t *testing.T) {
+	directory := fixtures.Directory(t)
+
+	storage := adapters.NewIdentityStorage(directory)
+
+	exists, err := storage.Exists()
+	require.NoError(t, err)
+	require.False(t, exists)
+
+	err = storage.Save(fixtures.SomePrivateIdentity())
+	require.NoError(t, err)
+
+	exists, err = storage.Exists()
+	require.NoError(t, err)
+	require.True(t, exists)
+}
+
+func TestIdentityStorage_Encrypted(t *testing.T) {
+	directory := fixtures.Directory(t)
+
+	storage := adapters.NewIdentityStorage(directory)
+
+	iden := fixtures.SomePrivateIdentity()
+	passphrase := []byte("some passphrase")
+
+	err := storage.SaveEncrypted(iden, passphrase)
+	require.NoError(t, err)
+
+	encrypted, err := storage.IsEncrypted()
+	require.NoError(t, err)
+	require.True(t, encrypted)
+
+	b, err := os.ReadFile(filepath.Join(directory, "identity.toml"))
+	require.NoError(t, err)
+	require.NotRegexp(t, `(?m)^private_key =`, string(b), "private key shouldn't be stored in plaintext")
+
+	_, err = storage.Load()
+	require.ErrorIs(t, err, adapters.ErrIdentityEncrypted)
+
+	_, err = storage.LoadEncrypted([]byte("invalid passphrase"))
+	require.ErrorContains(t, err, "invalid passphrase")
+
+	loadedIden, err := storage.LoadEncrypted(passphrase)
+	require.NoError(t, err)
+	require.Equal(t, iden, loadedIden)
+}
+
+func TestIdentityStorage_EncryptedWithEmptyPassphraseIsAnError(t *testing.T) {
+	storage := adapters.NewIdentityStorage(fixtures.Directory(t))
+
+	err := storage.SaveEncrypted(fixtures.SomePrivateIdentity(), nil)
+	require.EqualError(t, err, "error encrypting the identity: passphrase is empty")
+}
+
+func TestIdentityStorage_EncryptedWithInvalidKDFParametersIsRejected(t *testing.T) {
+	testCases := []struct {
+		Name          string
+		Parameters    string
+		ExpectedError string
+	}{
+		{
+			Name:          "short_salt",
+			Parameters:    "kdf_salt = [1, 2, 3, 4]\nkdf_time = 3\nkdf_memory_kibibytes = 65536\nkdf_threads = 4",
+			ExpectedError: "kdf salt length must be between 16 and 64 bytes",
+		},
+		{
+			Name:          "excessive_time",
+			Parameters:    "kdf_salt = [1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16]\nkdf_time = 1000000\nkdf_memory_kibibytes = 65536\nkdf_threads = 4",
+			ExpectedError: "kdf time must be between 1 and 16",
+		},
+		{
+			Name:          "excessive_memory",
+			Parameters:    "kdf_salt = [1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16]\nkdf_time = 3\nkdf_memory_kibibytes = 4294967295\nkdf_threads = 4",
+			ExpectedError: "kdf memory must be between 1 and 1048576 KiB",
+		},
+		{
+			Name:          "excessive_threads",
+			Parameters:    "kdf_salt = [1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16]\nkdf_time = 3\nkdf_memory_kibibytes = 65536\nkdf_threads = 255",
+			ExpectedError: "kdf threads must be between 1 and 64",
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			directory := fixtures.Directory(t)
+
+			content := "encrypted_private_key = [1, 2, 3, 4]\nkdf = 'argon2id'\ncipher = 'xchacha20poly1305'\n" + testCase.Parameters + "\n"
+			err := os.WriteFile(filepath.Join(directory, "identity.toml"), []byte(content), 0o600)
+			require.NoError(t, err)
+
+			_, err = adapters.NewIdentityStorage(directory).LoadEncrypted([]byte("some passphrase"))
+			require.ErrorContains(t, err, testCase.ExpectedError)
+		})
+	}
+}
+
+func TestIdentityStorage_PlaintextIsNotEncrypted(t *testing.T) {
+	storage := adapters.NewIdentityStorage(fixtures.Directory(t))
+
+	err := storage.Save(fixtures.SomePrivateIdentity())
+	require.NoError(t, err)
+
+	encrypted, err := storage.IsEncrypted()
+	require.NoError(t, err)
+	require.False(t, encrypted)
+
+	_, err = storage.LoadEncrypted([]byte("some passphrase"))
+	require.EqualError(t, err, "identity is not encrypted")
+}
+
+func TestIdentityStorage_WithFile(t *testing.T) {
+	path := filepath.Join(fixtures.Directory(t), "some-identity-file")
+
+	storage := adapters.NewIdentityStorageWithFile(path)
+
+	iden := fixtures.SomePrivateIdentity()
+
+	err := storage.Save(iden)
+	require.NoError(t, err)
+
+	loadedIden, err := adapters.NewIdentityStorageWithFile(path).Load()
+	require.NoError(t, err)
+	require.Equal(t, iden, loadedIden)
+}
+
+func TestIdentityStorage_WithFileDescriptorCanBeReadMultipleTimes(t *testing.T) {
+	directory := fixtures.Directory(t)
+
+	iden := fixtures.SomePrivateIdentity()
+
+	err := adapters.NewIdentityStorage(directory).Save(iden)
+	require.NoError(t, err)
+
+	f, err := os.Open(filepath.Join(directory, "identity.toml"))
+	require.NoError(t, err)
+	defer f.Close()
+
+	storage := adapters.NewIdentityStorageWithFile(fmt.Sprintf("fd:%d", f.Fd()))
+
+	encrypted, err := storage.IsEncrypted()
+	require.NoError(t, err)
+	require.False(t, encrypted)
+
+	loadedIden, err := storage.Load()
+	require.NoError(t, err)
+	require.Equal(t, iden, loadedIden)
+
+	err = storage.Save(iden)
+	require.EqualError(t, err, "identity can't be saved to a file descriptor")
+}
+
+func TestIdentityStorage_FileAccessibleToOtherUsersIsRejected(t *testing.T) {
+	directory := fixtures.Directory(t)
+
+	storage := adapters.NewIdentityStorage(directory)
+
+	err := storage.Save(fixtures.SomePrivateIdentity())
+	require.NoError(t, err)
+
+	err = os.Chmod(filepath.Join(directory, "identity.toml"), 0o644)
+	require.NoError(t, err)
+
+	_, err = storage.Load()
+	require.ErrorContains(t, err, "which allows the group or other users to access it")
+}
@@ -0,0 +1,130 @@
+package adapters
+
+import (
+	"sync"
+
+	"github.com/boreq/errors"
+	"github.com/planetary-social/scuttlego/logging"
+	"github.com/planetary-social/scuttlego/service/app/commands"
+	"github.com/planetary-social/scuttlego/service/domain/feeds/message"
+	"github.com/planetary-social/scuttlego/service/domain/identity"
+	"github.com/planetary-social/scuttlego/service/domain/refs"
+	"github.com/planetary-social/scuttlego/service/domain/replication"
+)
+
+// LocalFeedHeadTracker remembers the highest sequence of the local feed which
+// was received from other peers. Peers only send messages which are newer
+// than the ones we already have so receiving messages from the local feed
+// means that the local database is behind, most likely because an old backup
+// was restored.
+type LocalFeedHeadTracker struct {
+	localFeed refs.Feed
+	logger    logging.Logger
+
+	lock     sync.Mutex
+	sequence *message.Sequence
+}
+
+func NewLocalFeedHeadTracker(local identity.Public, logger logging.Logger) (*LocalFeedHeadTracker, error) {
+	localRef, err := refs.NewIdentityFromPublic(local)
+	if err != nil {
+		return nil, errors.Wrap(err, "error creating the identity ref")
+	}
+
+	return &LocalFeedHeadTracker{
+		localFeed: localRef.MainFeed(),
+		logger:    logger.New("local_feed_head_tracker"),
+	}, nil
+}
+
+// Report records a verified message received from a peer. Messages which
+// belong to other feeds are ignored.
+func (t *LocalFeedHeadTracker) Report(replicatedFrom identity.Public, msg message.Message) {
+	if !msg.Feed().Equal(t.localFeed) {
+		return
+	}
+
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	if t.sequence != nil && !msg.Sequence().ComesAfter(*t.sequence) {
+		return
+	}
+
+	sequence := msg.Sequence()
+	t.sequence = &sequence
+
+	t.logger.Error().
+		WithField("replicated_from", replicatedFrom.String()).
+		WithField("sequence", sequence.Int()).
+		Message("peer has messages from the local feed which may be missing in the local database, publishing is blocked until they are replicated")
+}
+
+// Get returns the highest sequence of the feed which was received from other
+// peers. Only the local feed is tracked.
+func (t *LocalFeedHeadTracker) Get(feed refs.Feed) (message.Sequence, bool) {
+	if !feed.Equal(t.localFeed) {
+		return message.Sequence{}, false
+	}
+
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	if t.sequence == nil {
+		return message.Sequence{}, false
+	}
+
+	return *t.sequence, true
+}
+
+// LocalFeedHeadTrackingRawMessageHandler reports messages received from other
+// peers to LocalFeedHeadTracker before passing them on.
+type LocalFeedHeadTrackingRawMessageHandler struct {
+	handler    replication.RawMessageHandler
+	identifier commands.RawMessageIdentifier
+	tracker    *LocalFeedHeadTracker
+	logger     logging.Logger
+}
+
+func NewLocalFeedHeadTrackingRawMessageHandler(
+	handler replication.RawMessageHandler,
+	identifier commands.RawMessageIdentifier,
+	tracker *LocalFeedHeadTracker,
+	logger logging.Logger,
+) *LocalFeedHeadTrackingRawMessageHandler {
+	return &LocalFeedHeadTrackingRawMessageHandler{
+		handler:    handler,
+		identifier: identifier,
+		tracker:    tracker,
+		logger:     logger.New("local_feed_head_tracking_raw_message_handler"),
+	}
+}
+
+func (h *LocalFeedHeadTrackingRawMessageHandler) Handle(replicatedFrom identity.Public, rawMsg message.RawMessage) error {
+	if err := h.report(replicatedFrom, rawMsg); err != nil {
+		h.logger.Debug().WithError(err).Message("error reporting the message")
+	}
+
+	return h.handler.Handle(replicatedFrom, rawMsg)
+}
+
+func (h *LocalFeedHeadTrackingRawMessageHandler) report(replicatedFrom identity.Public, rawMsg message.RawMessage) error {
+	peekedMsg, err := h.identifier.PeekRawMessage(rawMsg)
+	if err != nil {
+		return errors.Wrap(err, "error peeking the message")
+	}
+
+	if !peekedMsg.Feed().Equal(h.tracker.localFeed) {
+		return nil
+	}
+
+	// Messages from the local feed are verified as otherwise any peer could
+	// block publishing by sending an invalid message with a high sequence.
+	msg, err := h.identifier.VerifyRawMessage(rawMsg)
+	if err != nil {
+		return errors.Wrap(err, "error verifying the message")
+	}
+
+	h.tracker.Report(replicatedFrom, msg)
+	return nil
+}
@@ -0,0 +1,48 @@
+package adapters_test
+
+import (
+	"testing"
+
+	"github.com/planetary-social/scuttlego-pub/internal/fixtures"
+	"github.com/planetary-social/scuttlego-pub/service/adapters"
+	"github.com/planetary-social/scuttlego/logging"
+	"github.com/planetary-social/scuttlego/service/domain/feeds/message"
+	"github.com/planetary-social/scuttlego/service/domain/refs"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLocalFeedHeadTracker_TracksHighestSequenceOfTheLocalFeed(t *testing.T) {
+	local := fixtures.SomePublicIdentity()
+	localFeed := refs.MustNewIdentityFromPublic(local).MainFeed()
+
+	tracker, err := adapters.NewLocalFeedHeadTracker(local, logging.NewDevNullLogger())
+	require.NoError(t, err)
+
+	_, ok := tracker.Get(localFeed)
+	require.False(t, ok)
+
+	tracker.Report(fixtures.SomePublicIdentity(), fixtures.SomeMessageWithFeedSequence(localFeed, message.MustNewSequence(5)))
+	tracker.Report(fixtures.SomePublicIdentity(), fixtures.SomeMessageWithFeedSequence(localFeed, message.MustNewSequence(3)))
+
+	sequence, ok := tracker.Get(localFeed)
+	require.True(t, ok)
+	require.Equal(t, message.MustNewSequence(5), sequence)
+
+	tracker.Report(fixtures.SomePublicIdentity(), fixtures.SomeMessageWithFeedSequence(localFeed, message.MustNewSequence(10)))
+
+	sequence, ok = tracker.Get(localFeed)
+	require.True(t, ok)
+	require.Equal(t, message.MustNewSequence(10), sequence)
+}
+
+func TestLocalFeedHeadTracker_IgnoresOtherFeeds(t *testing.T) {
+	tracker, err := adapters.NewLocalFeedHeadTracker(fixtures.SomePublicIdentity(), logging.NewDevNullLogger())
+	require.NoError(t, err)
+
+	otherFeed := fixtures.SomeRefFeed()
+
+	tracker.Report(fixtures.SomePublicIdentity(), fixtures.SomeMessageWithFeedSequence(otherFeed, message.MustNewSequence(5)))
+
+	_, ok := tracker.Get(otherFeed)
+	require.False(t, ok)
+}
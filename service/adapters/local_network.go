@@ -0,0 +1,133 @@
+package adapters
+
+import (
+	"context"
+	"net"
+
+	"github.com/boreq/errors"
+	"github.com/planetary-social/scuttlego/service/app/commands"
+	"github.com/planetary-social/scuttlego/service/ports/network"
+)
+
+// LocalAdvertisementAddresses returns the addresses which should be
+// advertised on the local network restricted to the named network interfaces.
+// Listen addresses without a host are replaced with the IPv4 addresses of the
+// interfaces as announcements are broadcast over IPv4. Listen addresses with a
+// host are only advertised if the host belongs to one of the interfaces. If no
+// interfaces are named then the listen addresses are returned unchanged.
+func LocalAdvertisementAddresses(listenAddresses []string, interfaceNames []string) ([]string, error) {
+	if len(interfaceNames) == 0 {
+		return listenAddresses, nil
+	}
+
+	networks, err := localNetworks(interfaceNames)
+	if err != nil {
+		return nil, errors.Wrap(err, "error looking up the networks")
+	}
+
+	var result []string
+	seen := make(map[string]struct{})
+
+	add := func(address string) {
+		if _, ok := seen[address]; !ok {
+			seen[address] = struct{}{}
+			result = append(result, address)
+		}
+	}
+
+	for _, listenAddress := range listenAddresses {
+		host, port, err := net.SplitHostPort(listenAddress)
+		if err != nil {
+			return nil, errors.Wrapf(err, "error splitting address '%s'", listenAddress)
+		}
+
+		ip := net.ParseIP(host)
+		if host == "" || (ip != nil && ip.IsUnspecified()) {
+			for _, network := range networks {
+				if network.IP.To4() != nil {
+					add(net.JoinHostPort(network.IP.String(), port))
+				}
+			}
+			continue
+		}
+
+		if ip != nil && networksContain(networks, ip) {
+			add(listenAddress)
+		}
+	}
+
+	return result, nil
+}
+
+// LocalDiscoveryFilter passes on only those local discoveries which were
+// announced from the networks of the named network interfaces. Addresses of
+// the interfaces are looked up for every discovery as they may change while
+// the pub is running.
+type LocalDiscoveryFilter struct {
+	handler        network.ProcessNewLocalDiscoveryCommandHandler
+	interfaceNames []string
+}
+
+func NewLocalDiscoveryFilter(
+	handler network.ProcessNewLocalDiscoveryCommandHandler,
+	interfaceNames []string,
+) *LocalDiscoveryFilter {
+	return &LocalDiscoveryFilter{
+		handler:        handler,
+		interfaceNames: interfaceNames,
+	}
+}
+
+func (f *LocalDiscoveryFilter) Handle(ctx context.Context, cmd commands.ProcessNewLocalDiscovery) error {
+	host, _, err := net.SplitHostPort(cmd.Address.String())
+	if err != nil {
+		return errors.Wrap(err, "error splitting the address")
+	}
+
+	ip := net.ParseIP(host)
+	if ip == nil {
+		return errors.New("host is not an ip address")
+	}
+
+	networks, err := localNetworks(f.interfaceNames)
+	if err != nil {
+		return errors.Wrap(err, "error looking up the networks")
+	}
+
+	if !networksContain(networks, ip) {
+		return nil
+	}
+
+	return f.handler.Handle(ctx, cmd)
+}
+
+func localNetworks(interfaceNames []string) ([]*net.IPNet, error) {
+	var networks []*net.IPNet
+	for _, name := range interfaceNames {
+		iface, err := net.InterfaceByName(name)
+		if err != nil {
+			return nil, errors.Wrapf(err, "error looking up interface '%s'", name)
+		}
+
+		addrs, err := iface.Addrs()
+		if err != nil {
+			return nil, errors.Wrapf(err, "error getting the addresses of interface '%s'", name)
+		}
+
+		for _, addr := range addrs {
+			if network, ok := addr.(*net.IPNet); ok {
+				networks = append(networks, network)
+			}
+		}
+	}
+	return networks, nil
+}
+
+func networksContain(networks []*net.IPNet, ip net.IP) bool {
+	for _, network := range networks {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,102 @@
+package adapters_test
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"github.com/planetary-social/scuttlego-pub/internal/fixtures"
+	"github.com/planetary-social/scuttlego-pub/internal/mocks"
+	"github.com/planetary-social/scuttlego-pub/service/adapters"
+	"github.com/planetary-social/scuttlego/service/app/commands"
+	"github.com/planetary-social/scuttlego/service/domain/network"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLocalAdvertisementAddresses_WithoutInterfacesReturnsListenAddresses(t *testing.T) {
+	listenAddresses := []string{":8008", "192.0.2.1:8009"}
+
+	addresses, err := adapters.LocalAdvertisementAddresses(listenAddresses, nil)
+	require.NoError(t, err)
+	require.Equal(t, listenAddresses, addresses)
+}
+
+func TestLocalAdvertisementAddresses_RestrictsAddressesToInterfaces(t *testing.T) {
+	loopback := loopbackInterfaceName(t)
+
+	listenAddresses := []string{":8008", "0.0.0.0:8008", "127.0.0.1:8009", "192.0.2.1:8010"}
+
+	addresses, err := adapters.LocalAdvertisementAddresses(listenAddresses, []string{loopback})
+	require.NoError(t, err)
+	require.Equal(t, []string{"127.0.0.1:8008", "127.0.0.1:8009"}, addresses)
+}
+
+func TestLocalAdvertisementAddresses_UnknownInterfaceIsAnError(t *testing.T) {
+	_, err := adapters.LocalAdvertisementAddresses([]string{":8008"}, []string{"unknown-interface"})
+	require.ErrorContains(t, err, "error looking up interface 'unknown-interface'")
+}
+
+func TestLocalDiscoveryFilter(t *testing.T) {
+	loopback := loopbackInterfaceName(t)
+
+	testCases := []struct {
+		Name            string
+		Address         string
+		ShouldBeRelayed bool
+	}{
+		{
+			Name:            "address_in_interface_network",
+			Address:         "127.0.0.2:8008",
+			ShouldBeRelayed: true,
+		},
+		{
+			Name:            "address_outside_of_interface_network",
+			Address:         "192.0.2.1:8008",
+			ShouldBeRelayed: false,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			handler := mocks.NewProcessNewLocalDiscoveryHandlerMock()
+			filter := adapters.NewLocalDiscoveryFilter(handler, []string{loopback})
+
+			cmd := commands.ProcessNewLocalDiscovery{
+				Remote:  fixtures.SomePublicIdentity(),
+				Address: network.NewAddress(testCase.Address),
+			}
+
+			err := filter.Handle(context.Background(), cmd)
+			require.NoError(t, err)
+
+			if testCase.ShouldBeRelayed {
+				require.Equal(t, []commands.ProcessNewLocalDiscovery{cmd}, handler.HandleCalls)
+			} else {
+				require.Empty(t, handler.HandleCalls)
+			}
+		})
+	}
+}
+
+func loopbackInterfaceName(t *testing.T) string {
+	ifaces, err := net.Interfaces()
+	require.NoError(t, err)
+
+	for _, iface := range ifaces {
+		if iface.Flags&net.FlagLoopback == 0 {
+			continue
+		}
+
+		addrs, err := iface.Addrs()
+		require.NoError(t, err)
+
+		for _, addr := range addrs {
+			if ipNet, ok := addr.(*net.IPNet); ok && ipNet.IP.Equal(net.IPv4(127, 0, 0, 1)) {
+				return iface.Name
+			}
+		}
+	}
+
+	t.Skip("loopback interface with address 127.0.0.1 not found")
+	return ""
+}
@@ -0,0 +1,241 @@
+package adapters
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/planetary-social/scuttlego-pub/service"
+	"github.com/planetary-social/scuttlego/logging"
+	"github.com/sirupsen/logrus"
+)
+
+// loggerNameField is the field in which loggers created with logging.Logger
+// store their names, for example "scuttlego.peer_manager".
+const loggerNameField = "name"
+
+// loggerComponents assigns loggers to components based on the segments of
+// their names.
+var loggerComponents = map[string]service.LogComponent{
+	"session":                                      service.LogComponentReplication,
+	"replication_negotiator":                       service.LogComponentReplication,
+	"gossip_replicator":                            service.LogComponentReplication,
+	"manager":                                      service.LogComponentReplication,
+	"history_streams":                              service.LogComponentReplication,
+	"create_history_stream":                        service.LogComponentReplication,
+	"create_history_stream_handler":                service.LogComponentReplication,
+	"raw_message_handler":                          service.LogComponentReplication,
+	"message_buffer":                               service.LogComponentReplication,
+	"wants_process":                                service.LogComponentReplication,
+	"has_handler":                                  service.LogComponentReplication,
+	"downloader":                                   service.LogComponentReplication,
+	"blobs_replication_manager":                    service.LogComponentReplication,
+	"no_tx_feed_want_list_repository":              service.LogComponentReplication,
+	"no_tx_blob_want_list_repository":              service.LogComponentReplication,
+	"local_feed_head_tracker":                      service.LogComponentReplication,
+	"local_feed_head_tracking_raw_message_handler": service.LogComponentReplication,
+
+	"listener":                        service.LogComponentNetwork,
+	"discoverer":                      service.LogComponentNetwork,
+	"transport":                       service.LogComponentNetwork,
+	"connection":                      service.LogComponentNetwork,
+	"raw":                             service.LogComponentNetwork,
+	"response_streams":                service.LogComponentNetwork,
+	"mux":                             service.LogComponentNetwork,
+	"peer_manager":                    service.LogComponentNetwork,
+	"connection_establisher":          service.LogComponentNetwork,
+	"room_scanner":                    service.LogComponentNetwork,
+	"rooms_peer_rpc_adapter":          service.LogComponentNetwork,
+	"room_attendant_event_subscriber": service.LogComponentNetwork,
+
+	"badger":                   service.LogComponentBadger,
+	"badger_garbage_collector": service.LogComponentBadger,
+
+	"redeem_invite":   service.LogComponentInvites,
+	"invite_redeemer": service.LogComponentInvites,
+}
+
+// LoggingSystem writes log messages using logrus. Log levels of selected
+// components can be set separately from the main log level.
+type LoggingSystem struct {
+	logger          *logrus.Logger
+	system          logging.LogrusLoggingSystem
+	componentLevels map[service.LogComponent]logging.Level
+
+	lock  sync.Mutex
+	level logging.Level
+}
+
+func NewLoggingSystem(
+	logger *logrus.Logger,
+	level service.LogLevel,
+	componentLevels map[service.LogComponent]service.LogLevel,
+) (*LoggingSystem, error) {
+	s := &LoggingSystem{
+		logger:          logger,
+		system:          logging.NewLogrusLoggingSystem(logger),
+		componentLevels: make(map[service.LogComponent]logging.Level),
+	}
+
+	for component, componentLevel := range componentLevels {
+		v, err := loggingLevel(componentLevel)
+		if err != nil {
+			return nil, fmt.Errorf("invalid level of component '%s': %w", component, err)
+		}
+		s.componentLevels[component] = v
+	}
+
+	if err := s.SetLevel(level); err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+// SetLevel changes the main log level. Log levels of components are not
+// affected.
+func (s *LoggingSystem) SetLevel(level service.LogLevel) error {
+	v, err := loggingLevel(level)
+	if err != nil {
+		return err
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.level = v
+
+	// logrus has to let through messages of the most verbose level in use,
+	// they are filtered further when they are logged
+	mostVerbose := v
+	for _, componentLevel := range s.componentLevels {
+		if componentLevel < mostVerbose {
+			mostVerbose = componentLevel
+		}
+	}
+	s.logger.SetLevel(logrusLevel(mostVerbose))
+
+	return nil
+}
+
+func (s *LoggingSystem) EnabledLevel() logging.Level {
+	return s.system.EnabledLevel()
+}
+
+func (s *LoggingSystem) Error() logging.LoggingSystemEntry {
+	return newFilteringEntry(s, logging.LevelError, s.system.Error())
+}
+
+func (s *LoggingSystem) Debug() logging.LoggingSystemEntry {
+	return newFilteringEntry(s, logging.LevelDebug, s.system.Debug())
+}
+
+func (s *LoggingSystem) Trace() logging.LoggingSystemEntry {
+	return newFilteringEntry(s, logging.LevelTrace, s.system.Trace())
+}
+
+func (s *LoggingSystem) enabled(level logging.Level, loggerName string) bool {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	enabledLevel := s.level
+	if component, ok := loggerComponent(loggerName); ok {
+		if componentLevel, ok := s.componentLevels[component]; ok {
+			enabledLevel = componentLevel
+		}
+	}
+
+	return level >= enabledLevel
+}
+
+func loggerComponent(loggerName string) (service.LogComponent, bool) {
+	for _, segment := range strings.Split(loggerName, ".") {
+		if component, ok := loggerComponents[segment]; ok {
+			return component, true
+		}
+	}
+	return service.LogComponent{}, false
+}
+
+type filteringEntry struct {
+	system     *LoggingSystem
+	level      logging.Level
+	loggerName string
+	entry      logging.LoggingSystemEntry
+}
+
+func newFilteringEntry(system *LoggingSystem, level logging.Level, entry logging.LoggingSystemEntry) filteringEntry {
+	return filteringEntry{
+		system: system,
+		level:  level,
+		entry:  entry,
+	}
+}
+
+func (e filteringEntry) WithField(key string, v any) logging.LoggingSystemEntry {
+	if key == loggerNameField {
+		if loggerName, ok := v.(string); ok {
+			e.loggerName = loggerName
+		}
+	}
+	e.entry = e.entry.WithField(key, v)
+	return e
+}
+
+func (e filteringEntry) Message(msg string) {
+	if e.system.enabled(e.level, e.loggerName) {
+		e.entry.Message(msg)
+	}
+}
+
+// NamedLoggingSystem adds a logger name to all messages. It is used for
+// libraries which log using logging.LoggingSystem directly so that their
+// messages can be assigned to components.
+type NamedLoggingSystem struct {
+	system logging.LoggingSystem
+	name   string
+}
+
+func NewNamedLoggingSystem(system logging.LoggingSystem, name string) NamedLoggingSystem {
+	return NamedLoggingSystem{system: system, name: name}
+}
+
+func (s NamedLoggingSystem) EnabledLevel() logging.Level {
+	return s.system.EnabledLevel()
+}
+
+func (s NamedLoggingSystem) Error() logging.LoggingSystemEntry {
+	return s.system.Error().WithField(loggerNameField, s.name)
+}
+
+func (s NamedLoggingSystem) Debug() logging.LoggingSystemEntry {
+	return s.system.Debug().WithField(loggerNameField, s.name)
+}
+
+func (s NamedLoggingSystem) Trace() logging.LoggingSystemEntry {
+	return s.system.Trace().WithField(loggerNameField, s.name)
+}
+
+func loggingLevel(level service.LogLevel) (logging.Level, error) {
+	switch level {
+	case service.LogLevelError:
+		return logging.LevelError, nil
+	case service.LogLevelDebug:
+		return logging.LevelDebug, nil
+	case service.LogLevelTrace:
+		return logging.LevelTrace, nil
+	default:
+		return 0, fmt.Errorf("unknown log level '%s'", level)
+	}
+}
+
+func logrusLevel(level logging.Level) logrus.Level {
+	switch level {
+	case logging.LevelTrace:
+		return logrus.TraceLevel
+	case logging.LevelDebug:
+		return logrus.DebugLevel
+	default:
+		return logrus.ErrorLevel
+	}
+}
@@ -0,0 +1,73 @@
+package adapters_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/planetary-social/scuttlego-pub/service"
+	"github.com/planetary-social/scuttlego-pub/service/adapters"
+	"github.com/planetary-social/scuttlego/logging"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoggingSystem_ComponentLevelsOverrideTheMainLevel(t *testing.T) {
+	buf := &bytes.Buffer{}
+
+	logrusLogger := logrus.New()
+	logrusLogger.SetOutput(buf)
+
+	system, err := adapters.NewLoggingSystem(
+		logrusLogger,
+		service.LogLevelError,
+		map[service.LogComponent]service.LogLevel{
+			service.LogComponentReplication: service.LogLevelDebug,
+		},
+	)
+	require.NoError(t, err)
+
+	logger := logging.NewContextLogger(system, "scuttlego")
+
+	logger.New("session").Debug().Message("replication debug")
+	logger.New("peer_manager").Debug().Message("network debug")
+	logger.New("peer_manager").Error().Message("network error")
+	logger.New("session").Trace().Message("replication trace")
+
+	require.Contains(t, buf.String(), "replication debug")
+	require.NotContains(t, buf.String(), "network debug")
+	require.Contains(t, buf.String(), "network error")
+	require.NotContains(t, buf.String(), "replication trace")
+
+	err = system.SetLevel(service.LogLevelDebug)
+	require.NoError(t, err)
+
+	logger.New("peer_manager").Debug().Message("network debug after changing the level")
+	require.Contains(t, buf.String(), "network debug after changing the level")
+}
+
+func TestLoggingSystem_ComponentLevelsCanBeLessVerbose(t *testing.T) {
+	buf := &bytes.Buffer{}
+
+	logrusLogger := logrus.New()
+	logrusLogger.SetOutput(buf)
+
+	system, err := adapters.NewLoggingSystem(
+		logrusLogger,
+		service.LogLevelDebug,
+		map[service.LogComponent]service.LogLevel{
+			service.LogComponentBadger: service.LogLevelError,
+		},
+	)
+	require.NoError(t, err)
+
+	badgerSystem := adapters.NewNamedLoggingSystem(system, "badger")
+	badgerSystem.Debug().Message("badger debug")
+	badgerSystem.Error().Message("badger error")
+
+	logger := logging.NewContextLogger(system, "scuttlego")
+	logger.New("peer_manager").Debug().Message("network debug")
+
+	require.NotContains(t, buf.String(), "badger debug")
+	require.Contains(t, buf.String(), "badger error")
+	require.Contains(t, buf.String(), "network debug")
+}
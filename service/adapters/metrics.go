@@ -0,0 +1,394 @@
+package adapters
+
+import (
+	"context"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/boreq/errors"
+	"github.com/dgraph-io/badger/v3"
+	"github.com/planetary-social/scuttlego-pub/internal/prometheus"
+	"github.com/planetary-social/scuttlego-pub/service"
+	pubqueries "github.com/planetary-social/scuttlego-pub/service/app/queries"
+	"github.com/planetary-social/scuttlego/service/app/queries"
+	"github.com/planetary-social/scuttlego/service/domain/blobs"
+	"github.com/planetary-social/scuttlego/service/domain/transport"
+)
+
+const metricsNamespace = "scuttlego_pub_"
+
+const blobStorageUsageInterval = 1 * time.Minute
+
+const (
+	handshakeDirectionIncoming = "incoming"
+	handshakeDirectionOutgoing = "outgoing"
+
+	inviteEventCreated  = "created"
+	inviteEventRedeemed = "redeemed"
+	inviteEventRejected = "rejected"
+
+	badgerGarbageCollectionResultRewritten        = "rewritten"
+	badgerGarbageCollectionResultNothingToRewrite = "nothing_to_rewrite"
+	badgerGarbageCollectionResultError            = "error"
+)
+
+// Metrics holds the counters which are updated by other components as events
+// occur. Values which can be determined at any time are collected by
+// MetricsExporter when the metrics are exported.
+type Metrics struct {
+	registry *prometheus.Registry
+
+	handshakeFailures        *prometheus.CounterVec
+	ebtSessionsStarted       *prometheus.Counter
+	ebtSessionsActive        *prometheus.Gauge
+	blobsDownloaded          *prometheus.Counter
+	blobsDownloadedBytes     *prometheus.Counter
+	invites                  *prometheus.CounterVec
+	badgerGarbageCollections *prometheus.CounterVec
+}
+
+func NewMetrics() *Metrics {
+	registry := prometheus.NewRegistry()
+
+	m := &Metrics{
+		registry: registry,
+
+		handshakeFailures: registry.NewCounterVec(
+			metricsNamespace+"handshake_failures_total",
+			"Number of connections which were closed because the handshake or the initialization of the connection failed.",
+			"direction",
+		),
+		ebtSessionsStarted: registry.NewCounter(
+			metricsNamespace+"ebt_sessions_started_total",
+			"Number of started epidemic broadcast tree replication sessions.",
+		),
+		ebtSessionsActive: registry.NewGauge(
+			metricsNamespace+"ebt_sessions_active",
+			"Number of running epidemic broadcast tree replication sessions.",
+		),
+		blobsDownloaded: registry.NewCounter(
+			metricsNamespace+"blobs_downloaded_total",
+			"Number of blobs downloaded from other peers.",
+		),
+		blobsDownloadedBytes: registry.NewCounter(
+			metricsNamespace+"blobs_downloaded_bytes_total",
+			"Size of blobs downloaded from other peers.",
+		),
+		invites: registry.NewCounterVec(
+			metricsNamespace+"invites_total",
+			"Number of created invites and attempts to redeem them.",
+			"event",
+		),
+		badgerGarbageCollections: registry.NewCounterVec(
+			metricsNamespace+"badger_garbage_collections_total",
+			"Number of runs of the garbage collection of the database value log.",
+			"result",
+		),
+	}
+
+	for _, direction := range []string{handshakeDirectionIncoming, handshakeDirectionOutgoing} {
+		m.handshakeFailures.WithLabelValue(direction)
+	}
+
+	for _, event := range []string{inviteEventCreated, inviteEventRedeemed, inviteEventRejected} {
+		m.invites.WithLabelValue(event)
+	}
+
+	for _, result := range []string{badgerGarbageCollectionResultRewritten, badgerGarbageCollectionResultNothingToRewrite, badgerGarbageCollectionResultError} {
+		m.badgerGarbageCollections.WithLabelValue(result)
+	}
+
+	return m
+}
+
+func (m *Metrics) IncomingHandshakeFailed() {
+	m.handshakeFailures.WithLabelValue(handshakeDirectionIncoming).Inc()
+}
+
+func (m *Metrics) OutgoingHandshakeFailed() {
+	m.handshakeFailures.WithLabelValue(handshakeDirectionOutgoing).Inc()
+}
+
+func (m *Metrics) EBTSessionStarted() {
+	m.ebtSessionsStarted.Inc()
+	m.ebtSessionsActive.Inc()
+}
+
+func (m *Metrics) EBTSessionEnded() {
+	m.ebtSessionsActive.Dec()
+}
+
+func (m *Metrics) BlobDownloaded(size blobs.Size) {
+	m.blobsDownloaded.Inc()
+	m.blobsDownloadedBytes.Add(uint64(size.InBytes()))
+}
+
+func (m *Metrics) InviteCreated() {
+	m.invites.WithLabelValue(inviteEventCreated).Inc()
+}
+
+func (m *Metrics) InviteRedeemed() {
+	m.invites.WithLabelValue(inviteEventRedeemed).Inc()
+}
+
+func (m *Metrics) InviteRejected() {
+	m.invites.WithLabelValue(inviteEventRejected).Inc()
+}
+
+func (m *Metrics) InviteCounts() pubqueries.InviteCounts {
+	return pubqueries.InviteCounts{
+		Created:  m.invites.WithLabelValue(inviteEventCreated).Value(),
+		Redeemed: m.invites.WithLabelValue(inviteEventRedeemed).Value(),
+		Rejected: m.invites.WithLabelValue(inviteEventRejected).Value(),
+	}
+}
+
+// BadgerGarbageCollectionRun expects the error returned by
+// badger.DB.RunValueLogGC.
+func (m *Metrics) BadgerGarbageCollectionRun(err error) {
+	switch {
+	case err == nil:
+		m.badgerGarbageCollections.WithLabelValue(badgerGarbageCollectionResultRewritten).Inc()
+	case errors.Is(err, badger.ErrNoRewrite):
+		m.badgerGarbageCollections.WithLabelValue(badgerGarbageCollectionResultNothingToRewrite).Inc()
+	default:
+		m.badgerGarbageCollections.WithLabelValue(badgerGarbageCollectionResultError).Inc()
+	}
+}
+
+type MetricsPeerManager interface {
+	// Peers returns the currently connected peers.
+	Peers() []transport.Peer
+}
+
+type MetricsStatusQueryHandler interface {
+	Handle() (queries.StatusResult, error)
+}
+
+// MetricsExporter writes the metrics in the Prometheus text exposition
+// format. Metrics which are collected when the metrics are exported are
+// registered once Run is called.
+type MetricsExporter struct {
+	metrics          *Metrics
+	peerManager      MetricsPeerManager
+	status           MetricsStatusQueryHandler
+	db               *badger.DB
+	supervisor       *service.Supervisor
+	blobStorageUsage *BlobStorageUsage
+
+	register sync.Once
+
+	// writeLock guards the status which is queried once every time the
+	// metrics are written so that all metrics based on it come from the
+	// same snapshot.
+	writeLock    sync.Mutex
+	statusResult queries.StatusResult
+	statusErr    error
+}
+
+func NewMetricsExporter(
+	metrics *Metrics,
+	peerManager MetricsPeerManager,
+	status MetricsStatusQueryHandler,
+	db *badger.DB,
+	supervisor *service.Supervisor,
+	blobStorageUsage *BlobStorageUsage,
+) *MetricsExporter {
+	return &MetricsExporter{
+		metrics:          metrics,
+		peerManager:      peerManager,
+		status:           status,
+		db:               db,
+		supervisor:       supervisor,
+		blobStorageUsage: blobStorageUsage,
+	}
+}
+
+// Run registers the metrics which are collected when the metrics are exported
+// and blocks until the context is cancelled.
+func (e *MetricsExporter) Run(ctx context.Context) error {
+	e.register.Do(e.registerMetrics)
+	<-ctx.Done()
+	return ctx.Err()
+}
+
+// WriteMetrics writes all metrics. If some values can't be determined they
+// are skipped and an error is returned after writing the remaining ones.
+func (e *MetricsExporter) WriteMetrics(w io.Writer) error {
+	e.writeLock.Lock()
+	defer e.writeLock.Unlock()
+
+	e.statusResult, e.statusErr = e.status.Handle()
+
+	return e.metrics.registry.Write(w)
+}
+
+func (e *MetricsExporter) registerMetrics() {
+	registry := e.metrics.registry
+
+	registry.NewGaugeFunc(
+		metricsNamespace+"connected_peers",
+		"Number of connected peers.",
+		func() (float64, error) {
+			return float64(len(e.peerManager.Peers())), nil
+		},
+	)
+
+	registry.NewGaugeFunc(
+		metricsNamespace+"feeds",
+		"Number of stored feeds.",
+		func() (float64, error) {
+			if e.statusErr != nil {
+				return 0, errors.Wrap(e.statusErr, "error getting the status")
+			}
+			return float64(e.statusResult.NumberOfFeeds), nil
+		},
+	)
+
+	registry.NewGaugeFunc(
+		metricsNamespace+"messages",
+		"Number of stored messages.",
+		func() (float64, error) {
+			if e.statusErr != nil {
+				return 0, errors.Wrap(e.statusErr, "error getting the status")
+			}
+			return float64(e.statusResult.NumberOfMessages), nil
+		},
+	)
+
+	registry.NewGaugeFunc(
+		metricsNamespace+"blob_storage_bytes",
+		"Size of stored blobs.",
+		func() (float64, error) {
+			size, err := e.blobStorageUsage.Size()
+			if err != nil {
+				return 0, errors.Wrap(err, "error determining the size of the blob storage")
+			}
+			return float64(size), nil
+		},
+	)
+
+	registry.NewGaugeFunc(
+		metricsNamespace+"badger_lsm_size_bytes",
+		"Size of the LSM tree of the database.",
+		func() (float64, error) {
+			lsm, _ := e.db.Size()
+			return float64(lsm), nil
+		},
+	)
+
+	registry.NewGaugeFunc(
+		metricsNamespace+"badger_vlog_size_bytes",
+		"Size of the value log of the database.",
+		func() (float64, error) {
+			_, vlog := e.db.Size()
+			return float64(vlog), nil
+		},
+	)
+
+	registry.NewCounterVecFunc(
+		metricsNamespace+"runner_restarts_total",
+		"Number of times the runners were restarted after stopping unexpectedly.",
+		"runner",
+		func() (map[string]float64, error) {
+			restarts := make(map[string]float64)
+			for _, status := range e.supervisor.Statuses() {
+				restarts[status.Name] = float64(status.Restarts)
+			}
+			return restarts, nil
+		},
+	)
+}
+
+// BlobStorageUsage determines the size of the stored blobs. Walking the blob
+// storage can take a while so the size is determined periodically by Run
+// instead of every time it is requested.
+type BlobStorageUsage struct {
+	directory string
+	interval  time.Duration
+
+	lock       sync.Mutex
+	size       int64
+	err        error
+	determined bool
+}
+
+func NewBlobStorageUsage(config service.Config) *BlobStorageUsage {
+	return NewBlobStorageUsageWithInterval(config, blobStorageUsageInterval)
+}
+
+// NewBlobStorageUsageWithInterval determines the size of the stored blobs
+// every interval instead of using the default interval.
+func NewBlobStorageUsageWithInterval(config service.Config, interval time.Duration) *BlobStorageUsage {
+	return &BlobStorageUsage{
+		directory: filepath.Join(config.DataDirectory, "blobs"),
+		interval:  interval,
+	}
+}
+
+// Run keeps determining the size of the stored blobs until the context is
+// cancelled.
+func (u *BlobStorageUsage) Run(ctx context.Context) error {
+	for {
+		size, err := directorySize(u.directory)
+
+		u.lock.Lock()
+		u.size = size
+		u.err = err
+		u.determined = true
+		u.lock.Unlock()
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(u.interval):
+		}
+	}
+}
+
+// Size returns the size of the stored blobs in bytes as it was most recently
+// determined by Run. If Run didn't determine it yet
+// pubqueries.ErrBlobStorageSizeUnknown is returned.
+func (u *BlobStorageUsage) Size() (int64, error) {
+	u.lock.Lock()
+	defer u.lock.Unlock()
+
+	if !u.determined {
+		return 0, pubqueries.ErrBlobStorageSizeUnknown
+	}
+
+	return u.size, u.err
+}
+
+// directorySize returns zero if the directory doesn't exist.
+func directorySize(directory string) (int64, error) {
+	var size int64
+
+	err := filepath.WalkDir(directory, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if d.Type().IsRegular() {
+			info, err := d.Info()
+			if err != nil {
+				return errors.Wrap(err, "error getting file info")
+			}
+			size += info.Size()
+		}
+
+		return nil
+	})
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return 0, nil
+		}
+		return 0, err
+	}
+
+	return size, nil
+}
@@ -0,0 +1,90 @@
+package adapters
+
+import (
+	"context"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/planetary-social/scuttlego/service/domain/identity"
+	"github.com/planetary-social/scuttlego/service/domain/replication/ebt"
+	"github.com/planetary-social/scuttlego/service/domain/transport"
+	"github.com/planetary-social/scuttlego/service/domain/transport/rpc"
+)
+
+type HandshakeMetrics interface {
+	IncomingHandshakeFailed()
+	OutgoingHandshakeFailed()
+}
+
+// HandshakeFailureCountingPeerInitializer reports failed initializations of
+// incoming and outgoing connections. Most of them are caused by failed
+// handshakes.
+type HandshakeFailureCountingPeerInitializer struct {
+	initializer *transport.PeerInitializer
+	metrics     HandshakeMetrics
+}
+
+func NewHandshakeFailureCountingPeerInitializer(
+	initializer *transport.PeerInitializer,
+	metrics HandshakeMetrics,
+) *HandshakeFailureCountingPeerInitializer {
+	return &HandshakeFailureCountingPeerInitializer{
+		initializer: initializer,
+		metrics:     metrics,
+	}
+}
+
+func (i *HandshakeFailureCountingPeerInitializer) InitializeServerPeer(ctx context.Context, rwc io.ReadWriteCloser) (transport.Peer, error) {
+	peer, err := i.initializer.InitializeServerPeer(ctx, rwc)
+	if err != nil {
+		i.metrics.IncomingHandshakeFailed()
+	}
+	return peer, err
+}
+
+func (i *HandshakeFailureCountingPeerInitializer) InitializeClientPeer(ctx context.Context, rwc io.ReadWriteCloser, remote identity.Public) (transport.Peer, error) {
+	peer, err := i.initializer.InitializeClientPeer(ctx, rwc, remote)
+	if err != nil {
+		i.metrics.OutgoingHandshakeFailed()
+	}
+	return peer, err
+}
+
+type EBTSessionMetrics interface {
+	EBTSessionStarted()
+	EBTSessionEnded()
+}
+
+// SessionCountingEBTTracker reports started and ended epidemic broadcast tree
+// replication sessions.
+type SessionCountingEBTTracker struct {
+	tracker *ebt.SessionTracker
+	metrics EBTSessionMetrics
+}
+
+func NewSessionCountingEBTTracker(tracker *ebt.SessionTracker, metrics EBTSessionMetrics) *SessionCountingEBTTracker {
+	return &SessionCountingEBTTracker{
+		tracker: tracker,
+		metrics: metrics,
+	}
+}
+
+func (t *SessionCountingEBTTracker) OpenSession(id rpc.ConnectionId) (ebt.SessionEndedFn, error) {
+	sessionEnded, err := t.tracker.OpenSession(id)
+	if err != nil {
+		return nil, err
+	}
+
+	t.metrics.EBTSessionStarted()
+
+	var once sync.Once
+	return func() {
+		sessionEnded()
+		once.Do(t.metrics.EBTSessionEnded)
+	}, nil
+}
+
+func (t *SessionCountingEBTTracker) WaitForSession(ctx context.Context, id rpc.ConnectionId, waitTime time.Duration) (bool, error) {
+	return t.tracker.WaitForSession(ctx, id, waitTime)
+}
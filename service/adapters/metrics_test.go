@@ -0,0 +1,108 @@
+package adapters_test
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/dgraph-io/badger/v3"
+	"github.com/planetary-social/scuttlego-pub/internal/mocks"
+	"github.com/planetary-social/scuttlego-pub/service"
+	"github.com/planetary-social/scuttlego-pub/service/adapters"
+	pubqueries "github.com/planetary-social/scuttlego-pub/service/app/queries"
+	"github.com/planetary-social/scuttlego/logging"
+	scuttlegomocks "github.com/planetary-social/scuttlego/service/domain/mocks"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMetricsExporter_StatusIsQueriedOnceEveryTimeMetricsAreWritten(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	options := badger.DefaultOptions("").WithInMemory(true)
+	options.Logger = nil
+
+	db, err := badger.Open(options)
+	require.NoError(t, err)
+	defer db.Close()
+
+	status := mocks.NewStatusQueryHandlerMock()
+	status.HandleReturnValue.NumberOfFeeds = 10
+	status.HandleReturnValue.NumberOfMessages = 20
+
+	exporter := adapters.NewMetricsExporter(
+		adapters.NewMetrics(),
+		scuttlegomocks.NewPeerManagerMock(),
+		status,
+		db,
+		service.NewSupervisor(logging.NewDevNullLogger()),
+		adapters.NewBlobStorageUsage(service.Config{DataDirectory: t.TempDir()}),
+	)
+
+	go func() {
+		_ = exporter.Run(ctx)
+	}()
+
+	require.Eventually(t, func() bool {
+		buf := &bytes.Buffer{}
+		_ = exporter.WriteMetrics(buf)
+		return bytes.Contains(buf.Bytes(), []byte("scuttlego_pub_feeds 10\n")) &&
+			bytes.Contains(buf.Bytes(), []byte("scuttlego_pub_messages 20\n"))
+	}, time.Second, 10*time.Millisecond)
+
+	status.HandleCalls = 0
+
+	buf := &bytes.Buffer{}
+	_ = exporter.WriteMetrics(buf)
+	require.Equal(t, 1, status.HandleCalls)
+}
+
+func TestBlobStorageUsage_SizeIsDeterminedPeriodically(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	config := service.Config{DataDirectory: t.TempDir()}
+	blobsDirectory := filepath.Join(config.DataDirectory, "blobs")
+	require.NoError(t, os.MkdirAll(blobsDirectory, 0700))
+	require.NoError(t, os.WriteFile(filepath.Join(blobsDirectory, "a"), make([]byte, 10), 0600))
+
+	usage := adapters.NewBlobStorageUsageWithInterval(config, 10*time.Millisecond)
+
+	_, err := usage.Size()
+	require.ErrorIs(t, err, pubqueries.ErrBlobStorageSizeUnknown)
+
+	go func() {
+		_ = usage.Run(ctx)
+	}()
+
+	require.Eventually(t, func() bool {
+		size, err := usage.Size()
+		return err == nil && size == 10
+	}, time.Second, 10*time.Millisecond)
+
+	require.NoError(t, os.WriteFile(filepath.Join(blobsDirectory, "b"), make([]byte, 5), 0600))
+
+	require.Eventually(t, func() bool {
+		size, err := usage.Size()
+		return err == nil && size == 15
+	}, time.Second, 10*time.Millisecond)
+}
+
+func TestBlobStorageUsage_MissingDirectoryIsEmpty(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	usage := adapters.NewBlobStorageUsage(service.Config{DataDirectory: t.TempDir()})
+
+	go func() {
+		_ = usage.Run(ctx)
+	}()
+
+	require.Eventually(t, func() bool {
+		size, err := usage.Size()
+		return err == nil && size == 0
+	}, time.Second, 10*time.Millisecond)
+}
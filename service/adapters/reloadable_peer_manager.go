@@ -0,0 +1,48 @@
+package adapters
+
+import (
+	"context"
+
+	"github.com/boreq/errors"
+	"github.com/hashicorp/go-multierror"
+	"github.com/planetary-social/scuttlego/service/domain"
+)
+
+// CurrentPeerManagerConfig returns the config of the peer manager built from
+// the current config of the pub.
+type CurrentPeerManagerConfig func() domain.PeerManagerConfig
+
+// ReloadablePeerManager is a peer manager which connects to the preferred pubs
+// from the current config instead of the ones with which the wrapped peer
+// manager was created so that they can be changed while the pub is running.
+type ReloadablePeerManager struct {
+	*domain.PeerManager
+	config CurrentPeerManagerConfig
+}
+
+func NewReloadablePeerManager(
+	peerManager *domain.PeerManager,
+	config CurrentPeerManagerConfig,
+) *ReloadablePeerManager {
+	return &ReloadablePeerManager{
+		PeerManager: peerManager,
+		config:      config,
+	}
+}
+
+// EstablishNewConnections tries to establish new connections to the preferred
+// pubs from the current config.
+func (m *ReloadablePeerManager) EstablishNewConnections(ctx context.Context) error {
+	var resultErr *multierror.Error
+
+	for _, pub := range m.config().PreferredPubs {
+		if err := m.Connect(ctx, pub.Identity, pub.Address); err != nil {
+			resultErr = multierror.Append(
+				resultErr,
+				errors.Wrapf(err, "failed to connect to a pub '%s' on '%s'", pub.Identity, pub.Address),
+			)
+		}
+	}
+
+	return resultErr.ErrorOrNil()
+}
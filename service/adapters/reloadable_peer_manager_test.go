@@ -0,0 +1,63 @@
+package adapters_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/boreq/errors"
+	"github.com/planetary-social/scuttlego-pub/internal/fixtures"
+	"github.com/planetary-social/scuttlego-pub/service/adapters"
+	"github.com/planetary-social/scuttlego/logging"
+	"github.com/planetary-social/scuttlego/service/domain"
+	"github.com/planetary-social/scuttlego/service/domain/identity"
+	"github.com/planetary-social/scuttlego/service/domain/network"
+	"github.com/planetary-social/scuttlego/service/domain/transport"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReloadablePeerManager_ConnectsToPreferredPubsFromCurrentConfig(t *testing.T) {
+	dialer := newDialerMock()
+	peerManager := domain.NewPeerManager(domain.PeerManagerConfig{}, dialer, nil, logging.NewDevNullLogger())
+
+	var config domain.PeerManagerConfig
+	reloadablePeerManager := adapters.NewReloadablePeerManager(peerManager, func() domain.PeerManagerConfig {
+		return config
+	})
+
+	err := reloadablePeerManager.EstablishNewConnections(context.Background())
+	require.NoError(t, err)
+	require.Empty(t, dialer.Dialed())
+
+	pub := domain.Pub{
+		Identity: fixtures.SomePublicIdentity(),
+		Address:  network.NewAddress("example.com:8008"),
+	}
+	config = domain.PeerManagerConfig{PreferredPubs: []domain.Pub{pub}}
+
+	err = reloadablePeerManager.EstablishNewConnections(context.Background())
+	require.ErrorContains(t, err, "failed to connect to a pub")
+	require.Equal(t, []identity.Public{pub.Identity}, dialer.Dialed())
+}
+
+type dialerMock struct {
+	lock   sync.Mutex
+	dialed []identity.Public
+}
+
+func newDialerMock() *dialerMock {
+	return &dialerMock{}
+}
+
+func (d *dialerMock) Dial(ctx context.Context, remote identity.Public, address network.Address) (transport.Peer, error) {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	d.dialed = append(d.dialed, remote)
+	return transport.Peer{}, errors.New("dialing is not supported")
+}
+
+func (d *dialerMock) Dialed() []identity.Public {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	return d.dialed
+}
@@ -0,0 +1,112 @@
+package adapters
+
+import (
+	"fmt"
+	"os"
+	"sync"
+
+	"github.com/boreq/errors"
+)
+
+// RotatingFile appends to a file and rotates it once it would grow larger
+// than the max size. Rotated files are renamed by appending a number to their
+// names, the most recent one ends with ".1". Only maxBackups rotated files
+// are kept.
+type RotatingFile struct {
+	path       string
+	maxSize    int64
+	maxBackups int
+
+	lock sync.Mutex
+	file *os.File
+	size int64
+}
+
+func NewRotatingFile(path string, maxSize int64, maxBackups int) (*RotatingFile, error) {
+	if maxSize <= 0 {
+		return nil, errors.New("max size must be positive")
+	}
+
+	if maxBackups < 0 {
+		return nil, errors.New("max backups can't be negative")
+	}
+
+	f := &RotatingFile{
+		path:       path,
+		maxSize:    maxSize,
+		maxBackups: maxBackups,
+	}
+
+	if err := f.open(); err != nil {
+		return nil, errors.Wrap(err, "error opening the file")
+	}
+
+	return f, nil
+}
+
+func (f *RotatingFile) Write(b []byte) (int, error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	if f.size > 0 && f.size+int64(len(b)) > f.maxSize {
+		if err := f.rotate(); err != nil {
+			return 0, errors.Wrap(err, "error rotating the file")
+		}
+	}
+
+	n, err := f.file.Write(b)
+	f.size += int64(n)
+	return n, err
+}
+
+func (f *RotatingFile) Close() error {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	return f.file.Close()
+}
+
+func (f *RotatingFile) rotate() error {
+	if err := f.file.Close(); err != nil {
+		return errors.Wrap(err, "error closing the file")
+	}
+
+	if f.maxBackups == 0 {
+		if err := os.Remove(f.path); err != nil {
+			return errors.Wrap(err, "error removing the file")
+		}
+	} else {
+		for i := f.maxBackups - 1; i > 0; i-- {
+			if err := os.Rename(f.backupPath(i), f.backupPath(i+1)); err != nil && !os.IsNotExist(err) {
+				return errors.Wrap(err, "error renaming a backup")
+			}
+		}
+
+		if err := os.Rename(f.path, f.backupPath(1)); err != nil {
+			return errors.Wrap(err, "error renaming the file")
+		}
+	}
+
+	return f.open()
+}
+
+func (f *RotatingFile) open() error {
+	file, err := os.OpenFile(f.path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0o600)
+	if err != nil {
+		return errors.Wrap(err, "open file error")
+	}
+
+	stat, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return errors.Wrap(err, "stat error")
+	}
+
+	f.file = file
+	f.size = stat.Size()
+	return nil
+}
+
+func (f *RotatingFile) backupPath(i int) string {
+	return fmt.Sprintf("%s.%d", f.path, i)
+}
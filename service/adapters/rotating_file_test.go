@@ -0,0 +1,57 @@
+package adapters_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/planetary-social/scuttlego-pub/internal/fixtures"
+	"github.com/planetary-social/scuttlego-pub/service/adapters"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRotatingFile(t *testing.T) {
+	directory := fixtures.Directory(t)
+	path := filepath.Join(directory, "log")
+
+	f, err := adapters.NewRotatingFile(path, 10, 2)
+	require.NoError(t, err)
+
+	for _, line := range []string{"first\n", "second\n", "third\n", "fourth\n"} {
+		_, err := f.Write([]byte(line))
+		require.NoError(t, err)
+	}
+
+	err = f.Close()
+	require.NoError(t, err)
+
+	requireFileContent(t, path, "fourth\n")
+	requireFileContent(t, path+".1", "third\n")
+	requireFileContent(t, path+".2", "second\n")
+	require.NoFileExists(t, path+".3")
+}
+
+func TestRotatingFile_AppendsToExistingFiles(t *testing.T) {
+	directory := fixtures.Directory(t)
+	path := filepath.Join(directory, "log")
+
+	err := os.WriteFile(path, []byte("existing\n"), 0o600)
+	require.NoError(t, err)
+
+	f, err := adapters.NewRotatingFile(path, 100, 2)
+	require.NoError(t, err)
+
+	_, err = f.Write([]byte("new\n"))
+	require.NoError(t, err)
+
+	err = f.Close()
+	require.NoError(t, err)
+
+	requireFileContent(t, path, "existing\nnew\n")
+}
+
+func requireFileContent(t *testing.T, path string, expectedContent string) {
+	b, err := os.ReadFile(path)
+	require.NoError(t, err)
+	require.Equal(t, expectedContent, string(b))
+}
@@ -0,0 +1,117 @@
+package adapters
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/boreq/errors"
+)
+
+const secretFileDescriptorPrefix = "fd:"
+
+// ReadSecretFile reads a file containing secrets. The file is specified either
+// as a path or as a file descriptor in the format "fd:N", for example "fd:3".
+// Files which can be accessed by the group or other users are rejected.
+func ReadSecretFile(file string) ([]byte, error) {
+	f, err := openSecretFile(file)
+	if err != nil {
+		return nil, errors.Wrap(err, "error opening the file")
+	}
+	defer f.Close()
+
+	stat, err := f.Stat()
+	if err != nil {
+		return nil, errors.Wrap(err, "stat error")
+	}
+
+	// permissions of pipes and sockets passed as file descriptors are
+	// meaningless
+	if stat.Mode().IsRegular() && stat.Mode().Perm()&0o077 != 0 {
+		return nil, fmt.Errorf("permissions of file '%s' are %s which allows the group or other users to access it, change them with chmod 600", file, stat.Mode().Perm())
+	}
+
+	b, err := io.ReadAll(f)
+	if err != nil {
+		return nil, errors.Wrap(err, "read error")
+	}
+
+	return b, nil
+}
+
+// IsSecretFileDescriptor checks if the file passed to ReadSecretFile is a
+// file descriptor. File descriptors can only be read once.
+func IsSecretFileDescriptor(file string) bool {
+	return strings.HasPrefix(file, secretFileDescriptorPrefix)
+}
+
+// ResolveSecretFile resolves a relative path against the provided directory,
+// usually the config directory, so that it doesn't depend on the working
+// directory. Absolute paths and file descriptors are returned unchanged.
+func ResolveSecretFile(directory, file string) string {
+	if file == "" || IsSecretFileDescriptor(file) || filepath.IsAbs(file) {
+		return file
+	}
+	return filepath.Join(directory, file)
+}
+
+func openSecretFile(file string) (*os.File, error) {
+	if !IsSecretFileDescriptor(file) {
+		return os.Open(file)
+	}
+
+	fd, err := strconv.Atoi(strings.TrimPrefix(file, secretFileDescriptorPrefix))
+	if err != nil || fd < 0 {
+		return nil, fmt.Errorf("invalid file descriptor '%s'", file)
+	}
+
+	f := os.NewFile(uintptr(fd), file)
+	if f == nil {
+		return nil, fmt.Errorf("invalid file descriptor '%s'", file)
+	}
+
+	return f, nil
+}
+
+// secretFileReader reads files using ReadSecretFile. Contents of file
+// descriptors are cached as they can only be read once.
+type secretFileReader struct {
+	fileDescriptors map[string][]byte
+}
+
+func newSecretFileReader() *secretFileReader {
+	return &secretFileReader{
+		fileDescriptors: make(map[string][]byte),
+	}
+}
+
+func (r *secretFileReader) Read(file string) ([]byte, error) {
+	if b, ok := r.fileDescriptors[file]; ok {
+		return b, nil
+	}
+
+	b, err := ReadSecretFile(file)
+	if err != nil {
+		return nil, err
+	}
+
+	if IsSecretFileDescriptor(file) {
+		r.fileDescriptors[file] = b
+	}
+
+	return b, nil
+}
+
+// ReadBytes reads a file containing a byte value encoded as hex or base64.
+// See DecodeBytes.
+func (r *secretFileReader) ReadBytes(file string) ([]byte, error) {
+	b, err := r.Read(file)
+	if err != nil {
+		return nil, err
+	}
+
+	return DecodeBytes(strings.TrimSpace(string(b)))
+}
@@ -0,0 +1,94 @@
+package adapters_test
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/planetary-social/scuttlego-pub/internal/fixtures"
+	"github.com/planetary-social/scuttlego-pub/service/adapters"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadSecretFile_ReadsFiles(t *testing.T) {
+	path := filepath.Join(fixtures.Directory(t), "secret")
+
+	err := os.WriteFile(path, []byte("some secret"), 0o600)
+	require.NoError(t, err)
+
+	b, err := adapters.ReadSecretFile(path)
+	require.NoError(t, err)
+	require.Equal(t, []byte("some secret"), b)
+}
+
+func TestReadSecretFile_RejectsFilesAccessibleToOtherUsers(t *testing.T) {
+	for _, mode := range []os.FileMode{0o640, 0o604, 0o660, 0o644} {
+		t.Run(mode.String(), func(t *testing.T) {
+			path := filepath.Join(fixtures.Directory(t), "secret")
+
+			err := os.WriteFile(path, []byte("some secret"), 0o600)
+			require.NoError(t, err)
+
+			err = os.Chmod(path, mode)
+			require.NoError(t, err)
+
+			_, err = adapters.ReadSecretFile(path)
+			require.ErrorContains(t, err, "which allows the group or other users to access it")
+		})
+	}
+}
+
+func TestReadSecretFile_ReadsFileDescriptors(t *testing.T) {
+	r, w, err := os.Pipe()
+	require.NoError(t, err)
+	defer r.Close()
+
+	_, err = w.Write([]byte("some secret"))
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+
+	b, err := adapters.ReadSecretFile(fmt.Sprintf("fd:%d", r.Fd()))
+	require.NoError(t, err)
+	require.Equal(t, []byte("some secret"), b)
+}
+
+func TestReadSecretFile_InvalidFileDescriptorIsAnError(t *testing.T) {
+	_, err := adapters.ReadSecretFile("fd:abc")
+	require.ErrorContains(t, err, "invalid file descriptor 'fd:abc'")
+}
+
+func TestResolveSecretFile(t *testing.T) {
+	testCases := []struct {
+		Name     string
+		File     string
+		Expected string
+	}{
+		{
+			Name:     "empty",
+			File:     "",
+			Expected: "",
+		},
+		{
+			Name:     "relative",
+			File:     "secrets/network_key",
+			Expected: "/config/directory/secrets/network_key",
+		},
+		{
+			Name:     "absolute",
+			File:     "/run/secrets/network_key",
+			Expected: "/run/secrets/network_key",
+		},
+		{
+			Name:     "file_descriptor",
+			File:     "fd:3",
+			Expected: "fd:3",
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			require.Equal(t, testCase.Expected, adapters.ResolveSecretFile("/config/directory", testCase.File))
+		})
+	}
+}
@@ -0,0 +1,136 @@
+package adapters
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/boreq/errors"
+	"github.com/planetary-social/scuttlego/service/domain/identity"
+	"github.com/planetary-social/scuttlego/service/domain/refs"
+)
+
+const (
+	ssbSecretCurve  = "ed25519"
+	ssbSecretSuffix = ".ed25519"
+)
+
+// ReadSSBSecret reads an identity from the secret file used by ssb-server and
+// go-sbot, usually stored in ~/.ssb/secret. The file contains a JSON object
+// surrounded by comments starting with "#".
+func ReadSSBSecret(r io.Reader) (identity.Private, error) {
+	var withoutComments bytes.Buffer
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		if strings.HasPrefix(strings.TrimSpace(scanner.Text()), "#") {
+			continue
+		}
+		withoutComments.WriteString(scanner.Text())
+		withoutComments.WriteString("\n")
+	}
+
+	if err := scanner.Err(); err != nil {
+		return identity.Private{}, errors.Wrap(err, "read error")
+	}
+
+	var secret storedSSBSecret
+	if err := json.Unmarshal(withoutComments.Bytes(), &secret); err != nil {
+		return identity.Private{}, errors.Wrap(err, "error decoding json")
+	}
+
+	if secret.Curve != ssbSecretCurve {
+		return identity.Private{}, fmt.Errorf("unsupported curve '%s'", secret.Curve)
+	}
+
+	privateKeyBytes, err := decodeSSBSecretKey(secret.Private)
+	if err != nil {
+		return identity.Private{}, errors.Wrap(err, "error decoding the private key")
+	}
+	privateKey := ed25519.PrivateKey(privateKeyBytes)
+
+	if len(privateKey) != ed25519.PrivateKeySize {
+		return identity.Private{}, errors.New("invalid private key size")
+	}
+
+	// the private key contains the public key which has to match the seed
+	if !ed25519.NewKeyFromSeed(privateKey.Seed()).Equal(privateKey) {
+		return identity.Private{}, errors.New("private key is corrupted")
+	}
+
+	iden, err := identity.NewPrivateFromBytes(privateKey)
+	if err != nil {
+		return identity.Private{}, errors.Wrap(err, "error creating private identity from bytes")
+	}
+
+	publicKey, err := decodeSSBSecretKey(secret.Public)
+	if err != nil {
+		return identity.Private{}, errors.Wrap(err, "error decoding the public key")
+	}
+
+	if !iden.Public().PublicKey().Equal(ed25519.PublicKey(publicKey)) {
+		return identity.Private{}, errors.New("public key doesn't match the private key")
+	}
+
+	ref, err := refs.NewIdentityFromPublic(iden.Public())
+	if err != nil {
+		return identity.Private{}, errors.Wrap(err, "error creating the identity ref")
+	}
+
+	if secret.ID != "" && secret.ID != ref.String() {
+		return identity.Private{}, errors.New("id doesn't match the private key")
+	}
+
+	return iden, nil
+}
+
+// WriteSSBSecret writes the identity in the format read by ReadSSBSecret.
+func WriteSSBSecret(w io.Writer, iden identity.Private) error {
+	ref, err := refs.NewIdentityFromPublic(iden.Public())
+	if err != nil {
+		return errors.Wrap(err, "error creating the identity ref")
+	}
+
+	secret := storedSSBSecret{
+		Curve:   ssbSecretCurve,
+		Public:  base64.StdEncoding.EncodeToString(iden.Public().PublicKey()) + ssbSecretSuffix,
+		Private: base64.StdEncoding.EncodeToString(iden.PrivateKey()) + ssbSecretSuffix,
+		ID:      ref.String(),
+	}
+
+	b, err := json.MarshalIndent(secret, "", "  ")
+	if err != nil {
+		return errors.Wrap(err, "error encoding json")
+	}
+
+	if _, err := fmt.Fprintf(w, "# This is the secret key of your Secure Scuttlebutt identity.\n# Never share it with anyone.\n\n%s\n\n# Your id is %s\n", b, ref.String()); err != nil {
+		return errors.Wrap(err, "write error")
+	}
+
+	return nil
+}
+
+func decodeSSBSecretKey(s string) ([]byte, error) {
+	if !strings.HasSuffix(s, ssbSecretSuffix) {
+		return nil, fmt.Errorf("missing the '%s' suffix", ssbSecretSuffix)
+	}
+
+	b, err := base64.StdEncoding.DecodeString(strings.TrimSuffix(s, ssbSecretSuffix))
+	if err != nil {
+		return nil, errors.Wrap(err, "error decoding base64")
+	}
+
+	return b, nil
+}
+
+type storedSSBSecret struct {
+	Curve   string `json:"curve"`
+	Public  string `json:"public"`
+	Private string `json:"private"`
+	ID      string `json:"id"`
+}
@@ -0,0 +1,112 @@
+package adapters_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/planetary-social/scuttlego-pub/internal/fixtures"
+	"github.com/planetary-social/scuttlego-pub/service/adapters"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSSBSecret(t *testing.T) {
+	iden := fixtures.SomePrivateIdentity()
+
+	buf := &bytes.Buffer{}
+
+	err := adapters.WriteSSBSecret(buf, iden)
+	require.NoError(t, err)
+
+	loadedIden, err := adapters.ReadSSBSecret(buf)
+	require.NoError(t, err)
+
+	require.Equal(t, iden, loadedIden)
+}
+
+func TestReadSSBSecret_ReadsFilesCreatedBySSBKeys(t *testing.T) {
+	secret := `# WARNING: Never show this to anyone.
+# WARNING: Never edit it or use it on multiple devices at once.
+#
+# This is your SECRET, it gives you magical powers. With your secret you can
+# sign your messages so that your friends can verify that the messages came
+# from you. If anyone learns your secret, they can use it to impersonate you.
+#
+# If you use this secret on more than one device you will create a fork and
+# your friends will stop replicating your content.
+#
+{
+  "curve": "ed25519",
+  "public": "TQ7DoNA/7/+TqbzOKiS0BtRvRK7/H3eH2u2xPKr6QCw=.ed25519",
+  "private": "AAcOFRwjKjE4P0ZNVFtiaXB3foWMk5qhqK+2vcTL0tlNDsOg0D/v/5OpvM4qJLQG1G9Erv8fd4fa7bE8qvpALA==.ed25519",
+  "id": "@TQ7DoNA/7/+TqbzOKiS0BtRvRK7/H3eH2u2xPKr6QCw=.ed25519"
+}
+#
+# The only part of this file that's safe to share is your public name:
+#
+#   @TQ7DoNA/7/+TqbzOKiS0BtRvRK7/H3eH2u2xPKr6QCw=.ed25519
+`
+
+	iden, err := adapters.ReadSSBSecret(strings.NewReader(secret))
+	require.NoError(t, err)
+	require.Equal(t, "TQ7DoNA/7/+TqbzOKiS0BtRvRK7/H3eH2u2xPKr6QCw=", iden.Public().String())
+}
+
+func TestReadSSBSecret_InvalidSecretsReturnErrors(t *testing.T) {
+	testCases := []struct {
+		Name          string
+		Modify        func(secret string) string
+		ExpectedError string
+	}{
+		{
+			Name: "unsupported_curve",
+			Modify: func(secret string) string {
+				return strings.Replace(secret, `"curve": "ed25519"`, `"curve": "k256"`, 1)
+			},
+			ExpectedError: "unsupported curve 'k256'",
+		},
+		{
+			Name: "missing_suffix",
+			Modify: func(secret string) string {
+				return strings.Replace(secret, `==.ed25519"`, `=="`, 1)
+			},
+			ExpectedError: "missing the '.ed25519' suffix",
+		},
+		{
+			Name: "public_key_doesnt_match",
+			Modify: func(secret string) string {
+				other := &bytes.Buffer{}
+				require.NoError(t, adapters.WriteSSBSecret(other, fixtures.SomePrivateIdentity()))
+				return strings.Replace(secret, publicLine(secret), publicLine(other.String()), 1)
+			},
+			ExpectedError: "public key doesn't match the private key",
+		},
+		{
+			Name: "invalid_json",
+			Modify: func(secret string) string {
+				return strings.Replace(secret, "{", "", 1)
+			},
+			ExpectedError: "error decoding json",
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			buf := &bytes.Buffer{}
+			err := adapters.WriteSSBSecret(buf, fixtures.SomePrivateIdentity())
+			require.NoError(t, err)
+
+			_, err = adapters.ReadSSBSecret(strings.NewReader(testCase.Modify(buf.String())))
+			require.ErrorContains(t, err, testCase.ExpectedError)
+		})
+	}
+}
+
+func publicLine(secret string) string {
+	for _, line := range strings.Split(secret, "\n") {
+		if strings.Contains(line, `"public"`) {
+			return line
+		}
+	}
+	return ""
+}
@@ -1,6 +1,9 @@
 package app
 
-import "github.com/planetary-social/scuttlego-pub/service/app/commands"
+import (
+	"github.com/planetary-social/scuttlego-pub/service/app/commands"
+	"github.com/planetary-social/scuttlego-pub/service/app/queries"
+)
 
 type Application struct {
 	Commands Commands
@@ -8,9 +11,17 @@ type Application struct {
 }
 
 type Commands struct {
-	CreateInvite *commands.CreateInviteHandler
-	RedeemInvite *commands.RedeemInviteHandler
+	CreateInvite           *commands.CreateInviteHandler
+	RedeemInvite           *commands.RedeemInviteHandler
+	PublishPendingWelcomes *commands.PublishPendingWelcomesHandler
+	AnnouncePub            *commands.AnnouncePubHandler
+	UpdateProfile          *commands.UpdateProfileHandler
+	Disconnect             *commands.DisconnectHandler
+	DisconnectAll          *commands.DisconnectAllHandler
 }
 
 type Queries struct {
+	PreferredPeersStatus *queries.PreferredPeersStatusHandler
+	Status               *queries.StatusHandler
+	ConnectedPeers       *queries.ConnectedPeersHandler
 }
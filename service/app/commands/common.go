@@ -1,25 +1,39 @@
 package commands
 
 import (
+	"io"
 	"time"
 
+	"github.com/boreq/errors"
+	"github.com/planetary-social/scuttlego-pub/internal"
 	"github.com/planetary-social/scuttlego-pub/service/domain"
 	scuttlegocommands "github.com/planetary-social/scuttlego/service/app/commands"
+	"github.com/planetary-social/scuttlego/service/app/common"
+	"github.com/planetary-social/scuttlego/service/domain/feeds"
 	"github.com/planetary-social/scuttlego/service/domain/feeds/content/known"
 	"github.com/planetary-social/scuttlego/service/domain/feeds/message"
 	"github.com/planetary-social/scuttlego/service/domain/graph"
 	"github.com/planetary-social/scuttlego/service/domain/identity"
 	"github.com/planetary-social/scuttlego/service/domain/refs"
+	"github.com/planetary-social/scuttlego/service/domain/transport"
 )
 
 type TransactionProvider interface {
+	// Update runs the provided function in a transaction. The function may
+	// be called more than once if the transaction has to be retried so it
+	// mustn't have side effects other than the calls to the adapters.
 	Update(func(adapters Adapters) error) error
+
+	// View runs the provided function in a read-only transaction.
+	View(func(adapters Adapters) error) error
 }
 
 type Adapters struct {
 	SocialGraph SocialGraphRepository
 	Invite      InviteRepository
 	Feed        FeedRepository
+
+	PendingWelcome PendingWelcomeRepository
 }
 
 type InviteRepository interface {
@@ -27,6 +41,18 @@ type InviteRepository interface {
 	Update(publicIdentity identity.Public, fn func(invite *domain.Invite) error) error
 }
 
+type PendingWelcomeRepository interface {
+	// Put saves the pending welcome replacing a pending welcome of the same
+	// feed if it exists.
+	Put(welcome domain.PendingWelcome) error
+
+	// List returns all pending welcomes.
+	List() ([]domain.PendingWelcome, error)
+
+	// Delete does nothing if a pending welcome of the feed doesn't exist.
+	Delete(feed refs.Feed) error
+}
+
 type SocialGraphRepository interface {
 	GetSocialGraph() (graph.SocialGraph, error)
 }
@@ -39,8 +65,113 @@ type Marshaler interface {
 	Marshal(content known.KnownMessageContent) (message.RawContent, error)
 }
 
+type BlobCreator interface {
+	Create(r io.Reader) (refs.Blob, error)
+}
+
+type RemoteFeedHeads interface {
+	// Get returns the highest sequence of the feed which was received from
+	// other peers.
+	Get(feed refs.Feed) (message.Sequence, bool)
+}
+
+type Metrics interface {
+	InviteCreated()
+	InviteRedeemed()
+
+	// InviteRejected is called when redeeming an invite fails for any
+	// reason.
+	InviteRejected()
+}
+
+type PeerManager interface {
+	// Peers returns the peers which are currently connected.
+	Peers() []transport.Peer
+}
+
 type FeedRepository interface {
 	// UpdateFeed updates the specified feed by calling the provided function on
 	// it. Feed is never nil.
 	UpdateFeed(ref refs.Feed, fn scuttlegocommands.UpdateFeedFn) error
+
+	// GetMessages returns messages from the specified feed starting with the
+	// provided sequence. Returns an empty slice if the feed doesn't exist.
+	GetMessages(id refs.Feed, seq *message.Sequence, limit *int) ([]message.Message, error)
+
+	// GetSequence returns the sequence of the last message in the specified
+	// feed. Returns common.ErrFeedNotFound if the feed doesn't exist.
+	GetSequence(ref refs.Feed) (message.Sequence, error)
+}
+
+// ErrLocalFeedIsBehind is returned when attempting to publish a message while
+// other peers have more messages from the local feed than the local database.
+var ErrLocalFeedIsBehind = errors.New("local feed is behind the copies held by other peers")
+
+// createMessage creates a new message in the local feed unless other peers
+// have more messages from the local feed than the local database. This
+// happens after an old backup of the database is restored and publishing a
+// message in that case would fork the local feed permanently.
+func createMessage(
+	feed *feeds.Feed,
+	remoteFeedHeads RemoteFeedHeads,
+	localFeed refs.Feed,
+	content message.RawContent,
+	timestamp time.Time,
+	private identity.Private,
+) (refs.Message, error) {
+	if remoteSequence, ok := remoteFeedHeads.Get(localFeed); ok {
+		localSequence, ok := feed.Sequence()
+		if !ok || remoteSequence.ComesAfter(localSequence) {
+			return refs.Message{}, errors.Wrapf(ErrLocalFeedIsBehind, "peers have messages up to sequence %d", remoteSequence.Int())
+		}
+	}
+
+	return feed.CreateMessage(content, timestamp, private)
+}
+
+const getMessagesBatchSize = 100
+
+// findLastMessageContent iterates over the messages in the specified feed
+// starting with the most recent one and returns the content of the first
+// message for which the provided function returns true. Returns nil if there
+// is no such message.
+func findLastMessageContent[T known.KnownMessageContent](repository FeedRepository, feed refs.Feed, fn func(content T) bool) (*T, error) {
+	head, err := repository.GetSequence(feed)
+	if err != nil {
+		if errors.Is(err, common.ErrFeedNotFound) {
+			return nil, nil
+		}
+		return nil, errors.Wrap(err, "error getting the sequence of the feed")
+	}
+
+	for end := head.Int(); end > 0; end -= getMessagesBatchSize {
+		start := end - getMessagesBatchSize + 1
+		if start < 1 {
+			start = 1
+		}
+
+		seq, err := message.NewSequence(start)
+		if err != nil {
+			return nil, errors.Wrap(err, "error creating the sequence")
+		}
+
+		msgs, err := repository.GetMessages(feed, &seq, internal.Pointer(end-start+1))
+		if err != nil {
+			return nil, errors.Wrap(err, "error getting messages")
+		}
+
+		for i := len(msgs) - 1; i >= 0; i-- {
+			knownContent, ok := msgs[i].Content().KnownContent()
+			if !ok {
+				continue
+			}
+
+			content, ok := knownContent.(T)
+			if ok && fn(content) {
+				return internal.Pointer(content), nil
+			}
+		}
+	}
+
+	return nil, nil
 }
@@ -0,0 +1,115 @@
+package commands
+
+import (
+	"github.com/boreq/errors"
+	"github.com/planetary-social/scuttlego/service/domain/feeds"
+	"github.com/planetary-social/scuttlego/service/domain/feeds/content/known"
+	"github.com/planetary-social/scuttlego/service/domain/identity"
+	"github.com/planetary-social/scuttlego/service/domain/refs"
+)
+
+type AnnouncePub struct {
+	host string
+	port int
+}
+
+func NewAnnouncePub(host string, port int) (AnnouncePub, error) {
+	if host == "" {
+		return AnnouncePub{}, errors.New("host is empty")
+	}
+	if port <= 0 || port > 65535 {
+		return AnnouncePub{}, errors.New("invalid port")
+	}
+	return AnnouncePub{host: host, port: port}, nil
+}
+
+func (cmd AnnouncePub) Host() string {
+	return cmd.host
+}
+
+func (cmd AnnouncePub) Port() int {
+	return cmd.port
+}
+
+func (cmd AnnouncePub) IsZero() bool {
+	return cmd.host == ""
+}
+
+type AnnouncePubHandler struct {
+	transaction         TransactionProvider
+	currentTimeProvider CurrentTimeProvider
+	marshaler           Marshaler
+	localIdentity       identity.Private
+	remoteFeedHeads     RemoteFeedHeads
+}
+
+func NewAnnouncePubHandler(
+	transaction TransactionProvider,
+	currentTimeProvider CurrentTimeProvider,
+	marshaler Marshaler,
+	localIdentity identity.Private,
+	remoteFeedHeads RemoteFeedHeads,
+) *AnnouncePubHandler {
+	return &AnnouncePubHandler{
+		transaction:         transaction,
+		currentTimeProvider: currentTimeProvider,
+		marshaler:           marshaler,
+		localIdentity:       localIdentity,
+		remoteFeedHeads:     remoteFeedHeads,
+	}
+}
+
+// Handle publishes a pub message announcing the provided address unless the
+// last pub message published by the pub already contains the same address. It
+// returns true if a new message was published.
+func (h *AnnouncePubHandler) Handle(cmd AnnouncePub) (bool, error) {
+	if cmd.IsZero() {
+		return false, errors.New("zero value of cmd")
+	}
+
+	localIdentityRef, err := refs.NewIdentityFromPublic(h.localIdentity.Public())
+	if err != nil {
+		return false, errors.Wrap(err, "could not create the identity ref")
+	}
+
+	pub, err := known.NewPub(localIdentityRef, cmd.Host(), cmd.Port())
+	if err != nil {
+		return false, errors.Wrap(err, "error creating the pub message")
+	}
+
+	msgToPublish, err := h.marshaler.Marshal(pub)
+	if err != nil {
+		return false, errors.Wrap(err, "error marshaling")
+	}
+
+	var lastAnnouncement *known.Pub
+
+	if err := h.transaction.View(func(adapters Adapters) error {
+		lastAnnouncement, err = findLastMessageContent(adapters.Feed, localIdentityRef.MainFeed(), func(content known.Pub) bool {
+			return content.Key().Equal(localIdentityRef)
+		})
+		if err != nil {
+			return errors.Wrap(err, "error getting the last announcement")
+		}
+		return nil
+	}); err != nil {
+		return false, errors.Wrap(err, "read transaction failed")
+	}
+
+	if lastAnnouncement != nil && lastAnnouncement.Host() == pub.Host() && lastAnnouncement.Port() == pub.Port() {
+		return false, nil
+	}
+
+	if err := h.transaction.Update(func(adapters Adapters) error {
+		return adapters.Feed.UpdateFeed(localIdentityRef.MainFeed(), func(feed *feeds.Feed) error {
+			if _, err := createMessage(feed, h.remoteFeedHeads, localIdentityRef.MainFeed(), msgToPublish, h.currentTimeProvider.Get(), h.localIdentity); err != nil {
+				return errors.Wrap(err, "failed to create a message")
+			}
+			return nil
+		})
+	}); err != nil {
+		return false, errors.Wrap(err, "transaction failed")
+	}
+
+	return true, nil
+}
@@ -0,0 +1,192 @@
+package commands_test
+
+import (
+	"testing"
+
+	"github.com/planetary-social/scuttlego-pub/internal/fixtures"
+	"github.com/planetary-social/scuttlego-pub/internal/mocks"
+	"github.com/planetary-social/scuttlego-pub/service/app/commands"
+	"github.com/planetary-social/scuttlego-pub/service/di"
+	"github.com/planetary-social/scuttlego/service/domain/feeds/content/known"
+	"github.com/planetary-social/scuttlego/service/domain/feeds/message"
+	"github.com/planetary-social/scuttlego/service/domain/refs"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAnnouncePubHandler(t *testing.T) {
+	const (
+		host = "example.com"
+		port = 8008
+	)
+
+	testCases := []struct {
+		Name                    string
+		PreviousAnnouncements   func(local refs.Identity) []known.Pub
+		ShouldPublishNewMessage bool
+	}{
+		{
+			Name: "no_previous_announcements",
+			PreviousAnnouncements: func(local refs.Identity) []known.Pub {
+				return nil
+			},
+			ShouldPublishNewMessage: true,
+		},
+		{
+			Name: "last_announcement_has_the_same_address",
+			PreviousAnnouncements: func(local refs.Identity) []known.Pub {
+				return []known.Pub{
+					known.MustNewPub(local, "old.example.com", port),
+					known.MustNewPub(local, host, port),
+				}
+			},
+			ShouldPublishNewMessage: false,
+		},
+		{
+			Name: "last_announcement_has_a_different_host",
+			PreviousAnnouncements: func(local refs.Identity) []known.Pub {
+				return []known.Pub{
+					known.MustNewPub(local, host, port),
+					known.MustNewPub(local, "old.example.com", port),
+				}
+			},
+			ShouldPublishNewMessage: true,
+		},
+		{
+			Name: "last_announcement_has_a_different_port",
+			PreviousAnnouncements: func(local refs.Identity) []known.Pub {
+				return []known.Pub{
+					known.MustNewPub(local, host, port+1),
+				}
+			},
+			ShouldPublishNewMessage: true,
+		},
+		{
+			Name: "last_announcement_is_followed_by_many_other_messages",
+			PreviousAnnouncements: func(local refs.Identity) []known.Pub {
+				return append(
+					[]known.Pub{
+						known.MustNewPub(local, "old.example.com", port),
+						known.MustNewPub(local, host, port),
+					},
+					someAnnouncementsOfOtherPubs(250)...,
+				)
+			},
+			ShouldPublishNewMessage: false,
+		},
+		{
+			Name: "last_announcement_with_a_different_address_is_followed_by_many_other_messages",
+			PreviousAnnouncements: func(local refs.Identity) []known.Pub {
+				return append(
+					[]known.Pub{
+						known.MustNewPub(local, host, port),
+						known.MustNewPub(local, "new.example.com", port),
+					},
+					someAnnouncementsOfOtherPubs(250)...,
+				)
+			},
+			ShouldPublishNewMessage: true,
+		},
+		{
+			Name: "announcements_of_other_pubs_are_ignored",
+			PreviousAnnouncements: func(local refs.Identity) []known.Pub {
+				return []known.Pub{
+					known.MustNewPub(fixtures.SomeRefIdentity(), host, port),
+				}
+			},
+			ShouldPublishNewMessage: true,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			ts, err := di.BuildTestApplication(t)
+			require.NoError(t, err)
+
+			localIdentity := refs.MustNewIdentityFromPublic(ts.LocalIdentity.Public())
+			localFeed := localIdentity.MainFeed()
+
+			var msgs []message.Message
+			sequence := message.NewFirstSequence()
+			for _, pub := range testCase.PreviousAnnouncements(localIdentity) {
+				msgs = append(msgs, fixtures.SomeMessageWithFeedSequenceContent(localFeed, sequence, message.MustNewContent(fixtures.SomeRawContent(), pub, nil)))
+				sequence = sequence.Next()
+			}
+			ts.FeedRepository.MockMessages(msgs)
+
+			ts.Marshaler.MarshalReturnValue = fixtures.SomeRawContent()
+			ts.FeedFormat.SignReturnValue = fixtures.SomeMessageWithFeedSequence(localFeed, message.NewFirstSequence())
+
+			cmd, err := commands.NewAnnouncePub(host, port)
+			require.NoError(t, err)
+
+			published, err := ts.Commands.AnnouncePub.Handle(cmd)
+			require.NoError(t, err)
+			require.Equal(t, testCase.ShouldPublishNewMessage, published)
+
+			require.Equal(t,
+				[]mocks.MarshalerMockMarshalCall{
+					{
+						Content: known.MustNewPub(localIdentity, host, port),
+					},
+				},
+				ts.Marshaler.MarshalCalls,
+			)
+
+			if testCase.ShouldPublishNewMessage {
+				require.Len(t, ts.FeedRepository.UpdateFeedResults, 1)
+				require.Equal(t, localFeed, ts.FeedRepository.UpdateFeedResults[0].Id)
+			} else {
+				require.Empty(t, ts.FeedRepository.UpdateFeedResults)
+			}
+		})
+	}
+}
+
+func TestAnnouncePubHandler_PublishesOnlyWhenAddressChanges(t *testing.T) {
+	ts, err := di.BuildBadgerTestApplication(t)
+	require.NoError(t, err)
+
+	cmd, err := commands.NewAnnouncePub("example.com", 8008)
+	require.NoError(t, err)
+
+	published, err := ts.Commands.AnnouncePub.Handle(cmd)
+	require.NoError(t, err)
+	require.True(t, published)
+
+	published, err = ts.Commands.AnnouncePub.Handle(cmd)
+	require.NoError(t, err)
+	require.False(t, published)
+
+	cmd, err = commands.NewAnnouncePub("example.com", 8009)
+	require.NoError(t, err)
+
+	published, err = ts.Commands.AnnouncePub.Handle(cmd)
+	require.NoError(t, err)
+	require.True(t, published)
+}
+
+func TestAnnouncePubHandler_RefusesToPublishIfLocalFeedIsBehind(t *testing.T) {
+	ts, err := di.BuildTestApplication(t)
+	require.NoError(t, err)
+
+	localFeed := refs.MustNewIdentityFromPublic(ts.LocalIdentity.Public()).MainFeed()
+	ts.RemoteFeedHeads.Mock(localFeed, fixtures.SomeSequence())
+
+	ts.Marshaler.MarshalReturnValue = fixtures.SomeRawContent()
+
+	cmd, err := commands.NewAnnouncePub("example.com", 8008)
+	require.NoError(t, err)
+
+	_, err = ts.Commands.AnnouncePub.Handle(cmd)
+	require.ErrorIs(t, err, commands.ErrLocalFeedIsBehind)
+
+	require.Empty(t, ts.FeedRepository.UpdateFeedResults)
+}
+
+func someAnnouncementsOfOtherPubs(n int) []known.Pub {
+	var result []known.Pub
+	for i := 0; i < n; i++ {
+		result = append(result, known.MustNewPub(fixtures.SomeRefIdentity(), "example.com", 8008))
+	}
+	return result
+}
@@ -41,10 +41,11 @@ func (c CreateInvite) ValidUntil() *time.Time {
 
 type CreateInviteHandler struct {
 	transaction TransactionProvider
+	metrics     Metrics
 }
 
-func NewCreateInviteHandler(transaction TransactionProvider) *CreateInviteHandler {
-	return &CreateInviteHandler{transaction: transaction}
+func NewCreateInviteHandler(transaction TransactionProvider, metrics Metrics) *CreateInviteHandler {
+	return &CreateInviteHandler{transaction: transaction, metrics: metrics}
 }
 
 func (h *CreateInviteHandler) Handle(cmd CreateInvite) (domain.SecretKeySeed, error) {
@@ -67,5 +68,6 @@ func (h *CreateInviteHandler) Handle(cmd CreateInvite) (domain.SecretKeySeed, er
 		return domain.SecretKeySeed{}, errors.Wrap(err, "transaction failed")
 	}
 
+	h.metrics.InviteCreated()
 	return secretKeySeed, nil
 }
@@ -33,4 +33,6 @@ func TestCreateInviteHandler(t *testing.T) {
 		},
 		ts.InviteRepository.PutCalls,
 	)
+
+	require.Equal(t, mocks.MetricsMockInvites{Created: 1}, ts.Metrics.Invites())
 }
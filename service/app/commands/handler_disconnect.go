@@ -0,0 +1,67 @@
+package commands
+
+import (
+	"github.com/boreq/errors"
+	"github.com/hashicorp/go-multierror"
+	"github.com/planetary-social/scuttlego/service/domain/identity"
+)
+
+// ErrPeerNotConnected is returned when disconnecting from a peer which isn't
+// connected.
+var ErrPeerNotConnected = errors.New("peer isn't connected")
+
+type Disconnect struct {
+	remote identity.Public
+}
+
+func NewDisconnect(remote identity.Public) (Disconnect, error) {
+	if remote.IsZero() {
+		return Disconnect{}, errors.New("zero value of remote")
+	}
+	return Disconnect{remote: remote}, nil
+}
+
+func (cmd Disconnect) Remote() identity.Public {
+	return cmd.remote
+}
+
+func (cmd Disconnect) IsZero() bool {
+	return cmd.remote.IsZero()
+}
+
+type DisconnectHandler struct {
+	peerManager PeerManager
+}
+
+func NewDisconnectHandler(peerManager PeerManager) *DisconnectHandler {
+	return &DisconnectHandler{peerManager: peerManager}
+}
+
+// Handle closes all connections to the specified peer. The peer may reconnect
+// or be reconnected to later e.g. if it is one of the preferred peers.
+func (h *DisconnectHandler) Handle(cmd Disconnect) error {
+	if cmd.IsZero() {
+		return errors.New("zero value of cmd")
+	}
+
+	var found bool
+	var resultErr *multierror.Error
+
+	for _, peer := range h.peerManager.Peers() {
+		if !peer.Identity().Equal(cmd.Remote()) {
+			continue
+		}
+
+		found = true
+
+		if err := peer.Conn().Close(); err != nil {
+			resultErr = multierror.Append(resultErr, err)
+		}
+	}
+
+	if !found {
+		return ErrPeerNotConnected
+	}
+
+	return resultErr.ErrorOrNil()
+}
@@ -0,0 +1,27 @@
+package commands
+
+import (
+	"github.com/hashicorp/go-multierror"
+)
+
+type DisconnectAllHandler struct {
+	peerManager PeerManager
+}
+
+func NewDisconnectAllHandler(peerManager PeerManager) *DisconnectAllHandler {
+	return &DisconnectAllHandler{peerManager: peerManager}
+}
+
+// Handle closes connections to all peers. The peers may reconnect or be
+// reconnected to later e.g. if they are among the preferred peers.
+func (h *DisconnectAllHandler) Handle() error {
+	var resultErr *multierror.Error
+
+	for _, peer := range h.peerManager.Peers() {
+		if err := peer.Conn().Close(); err != nil {
+			resultErr = multierror.Append(resultErr, err)
+		}
+	}
+
+	return resultErr.ErrorOrNil()
+}
@@ -0,0 +1,39 @@
+package commands_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/planetary-social/scuttlego-pub/internal/fixtures"
+	"github.com/planetary-social/scuttlego-pub/service/di"
+	"github.com/planetary-social/scuttlego/service/domain/mocks"
+	"github.com/planetary-social/scuttlego/service/domain/transport"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDisconnectAllHandler_ClosesConnectionsToAllPeers(t *testing.T) {
+	ts, err := di.BuildTestApplication(t)
+	require.NoError(t, err)
+
+	conn1 := mocks.NewConnectionMock(context.Background())
+	conn2 := mocks.NewConnectionMock(context.Background())
+
+	ts.PeerManager.MockPeers([]transport.Peer{
+		transport.MustNewPeer(fixtures.SomePublicIdentity(), conn1),
+		transport.MustNewPeer(fixtures.SomePublicIdentity(), conn2),
+	})
+
+	err = ts.Commands.DisconnectAll.Handle()
+	require.NoError(t, err)
+
+	require.True(t, conn1.IsClosed())
+	require.True(t, conn2.IsClosed())
+}
+
+func TestDisconnectAllHandler_SucceedsIfNoPeersAreConnected(t *testing.T) {
+	ts, err := di.BuildTestApplication(t)
+	require.NoError(t, err)
+
+	err = ts.Commands.DisconnectAll.Handle()
+	require.NoError(t, err)
+}
@@ -0,0 +1,59 @@
+package commands_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/planetary-social/scuttlego-pub/internal/fixtures"
+	"github.com/planetary-social/scuttlego-pub/service/app/commands"
+	"github.com/planetary-social/scuttlego-pub/service/di"
+	"github.com/planetary-social/scuttlego/service/domain/mocks"
+	"github.com/planetary-social/scuttlego/service/domain/transport"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDisconnectHandler_ClosesConnectionsToThePeer(t *testing.T) {
+	ts, err := di.BuildTestApplication(t)
+	require.NoError(t, err)
+
+	remote := fixtures.SomePublicIdentity()
+
+	remoteConn1 := mocks.NewConnectionMock(context.Background())
+	remoteConn2 := mocks.NewConnectionMock(context.Background())
+	otherConn := mocks.NewConnectionMock(context.Background())
+
+	ts.PeerManager.MockPeers([]transport.Peer{
+		transport.MustNewPeer(remote, remoteConn1),
+		transport.MustNewPeer(fixtures.SomePublicIdentity(), otherConn),
+		transport.MustNewPeer(remote, remoteConn2),
+	})
+
+	cmd, err := commands.NewDisconnect(remote)
+	require.NoError(t, err)
+
+	err = ts.Commands.Disconnect.Handle(cmd)
+	require.NoError(t, err)
+
+	require.True(t, remoteConn1.IsClosed())
+	require.True(t, remoteConn2.IsClosed())
+	require.False(t, otherConn.IsClosed())
+}
+
+func TestDisconnectHandler_ReturnsAnErrorIfThePeerIsNotConnected(t *testing.T) {
+	ts, err := di.BuildTestApplication(t)
+	require.NoError(t, err)
+
+	otherConn := mocks.NewConnectionMock(context.Background())
+
+	ts.PeerManager.MockPeers([]transport.Peer{
+		transport.MustNewPeer(fixtures.SomePublicIdentity(), otherConn),
+	})
+
+	cmd, err := commands.NewDisconnect(fixtures.SomePublicIdentity())
+	require.NoError(t, err)
+
+	err = ts.Commands.Disconnect.Handle(cmd)
+	require.ErrorIs(t, err, commands.ErrPeerNotConnected)
+
+	require.False(t, otherConn.IsClosed())
+}
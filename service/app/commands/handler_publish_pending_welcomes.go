@@ -0,0 +1,163 @@
+package commands
+
+import (
+	"time"
+
+	"github.com/boreq/errors"
+	"github.com/planetary-social/scuttlego-pub/service/domain"
+	"github.com/planetary-social/scuttlego/service/domain/feeds"
+	"github.com/planetary-social/scuttlego/service/domain/feeds/message"
+	"github.com/planetary-social/scuttlego/service/domain/identity"
+	"github.com/planetary-social/scuttlego/service/domain/refs"
+)
+
+// pendingWelcomeNameTimeout is how long the pub waits for the name of a new
+// member to be replicated. After that the welcome post refers to the new
+// member using their feed ref.
+const pendingWelcomeNameTimeout = 1 * time.Hour
+
+type PublishPendingWelcomesHandler struct {
+	transaction         TransactionProvider
+	currentTimeProvider CurrentTimeProvider
+	marshaler           Marshaler
+	localIdentity       identity.Private
+	remoteFeedHeads     RemoteFeedHeads
+	welcomeMessage      domain.WelcomeMessageTemplate
+}
+
+// NewPublishPendingWelcomesHandler creates a new handler. Welcome message is
+// optional, if it is a zero value then pending welcomes are discarded.
+func NewPublishPendingWelcomesHandler(
+	transaction TransactionProvider,
+	currentTimeProvider CurrentTimeProvider,
+	marshaler Marshaler,
+	localIdentity identity.Private,
+	remoteFeedHeads RemoteFeedHeads,
+	welcomeMessage domain.WelcomeMessageTemplate,
+) *PublishPendingWelcomesHandler {
+	return &PublishPendingWelcomesHandler{
+		transaction:         transaction,
+		currentTimeProvider: currentTimeProvider,
+		marshaler:           marshaler,
+		localIdentity:       localIdentity,
+		remoteFeedHeads:     remoteFeedHeads,
+		welcomeMessage:      welcomeMessage,
+	}
+}
+
+// Handle publishes the posts greeting new members whose names were replicated
+// since they redeemed an invite. If the name of a new member isn't replicated
+// within pendingWelcomeNameTimeout their feed ref is used instead. It returns
+// the number of published posts. The names are looked up in a read-only
+// transaction as that requires reading the feeds of the new members, a
+// read-write transaction is only used to publish the posts.
+func (h *PublishPendingWelcomesHandler) Handle() (int, error) {
+	localIdentityRef, err := refs.NewIdentityFromPublic(h.localIdentity.Public())
+	if err != nil {
+		return 0, errors.Wrap(err, "could not create the identity ref")
+	}
+
+	now := h.currentTimeProvider.Get()
+
+	var welcomesToProcess []welcomeToProcess
+
+	if err := h.transaction.View(func(adapters Adapters) error {
+		welcomesToProcess = nil
+
+		welcomes, err := adapters.PendingWelcome.List()
+		if err != nil {
+			return errors.Wrap(err, "error listing pending welcomes")
+		}
+
+		for _, welcome := range welcomes {
+			if h.welcomeMessage.IsZero() {
+				welcomesToProcess = append(welcomesToProcess, welcomeToProcess{feed: welcome.Feed()})
+				continue
+			}
+
+			name, ok, err := findMemberName(adapters.Feed, welcome.Feed())
+			if err != nil {
+				return errors.Wrap(err, "error getting the name of the new member")
+			}
+
+			if !ok {
+				if now.Sub(welcome.RedeemedAt()) < pendingWelcomeNameTimeout {
+					continue
+				}
+				name = welcome.Feed().String()
+			}
+
+			content, err := marshalWelcomePost(h.marshaler, h.welcomeMessage, welcome.Feed(), name)
+			if err != nil {
+				return errors.Wrap(err, "error creating the welcome post")
+			}
+
+			welcomesToProcess = append(welcomesToProcess, welcomeToProcess{feed: welcome.Feed(), content: &content})
+		}
+
+		return nil
+	}); err != nil {
+		return 0, errors.Wrap(err, "read transaction failed")
+	}
+
+	if len(welcomesToProcess) == 0 {
+		return 0, nil
+	}
+
+	var published int
+
+	if err := h.transaction.Update(func(adapters Adapters) error {
+		published = 0
+
+		// pending welcomes could have been processed in the meantime
+		welcomes, err := adapters.PendingWelcome.List()
+		if err != nil {
+			return errors.Wrap(err, "error listing pending welcomes")
+		}
+
+		for _, welcome := range welcomesToProcess {
+			if !containsPendingWelcome(welcomes, welcome.feed) {
+				continue
+			}
+
+			if welcome.content != nil {
+				if err := adapters.Feed.UpdateFeed(localIdentityRef.MainFeed(), func(feed *feeds.Feed) error {
+					if _, err := createMessage(feed, h.remoteFeedHeads, localIdentityRef.MainFeed(), *welcome.content, now, h.localIdentity); err != nil {
+						return errors.Wrap(err, "failed to create a message")
+					}
+					return nil
+				}); err != nil {
+					return errors.Wrap(err, "error updating the local feed")
+				}
+
+				published++
+			}
+
+			if err := adapters.PendingWelcome.Delete(welcome.feed); err != nil {
+				return errors.Wrap(err, "error deleting the pending welcome")
+			}
+		}
+
+		return nil
+	}); err != nil {
+		return 0, errors.Wrap(err, "transaction failed")
+	}
+
+	return published, nil
+}
+
+// welcomeToProcess describes a pending welcome which should be deleted after
+// publishing the welcome post if the content of the post isn't nil.
+type welcomeToProcess struct {
+	feed    refs.Feed
+	content *message.RawContent
+}
+
+func containsPendingWelcome(welcomes []domain.PendingWelcome, feed refs.Feed) bool {
+	for _, welcome := range welcomes {
+		if welcome.Feed().Equal(feed) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,166 @@
+package commands_test
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/planetary-social/scuttlego-pub/internal/fixtures"
+	"github.com/planetary-social/scuttlego-pub/internal/mocks"
+	"github.com/planetary-social/scuttlego-pub/service"
+	"github.com/planetary-social/scuttlego-pub/service/di"
+	"github.com/planetary-social/scuttlego-pub/service/domain"
+	known "github.com/planetary-social/scuttlego-pub/service/domain/messages"
+	"github.com/planetary-social/scuttlego/service/domain/refs"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPublishPendingWelcomesHandler(t *testing.T) {
+	testCases := []struct {
+		Name               string
+		HasName            bool
+		WaitingFor         time.Duration
+		ExpectedPublished  bool
+		ExpectedNameIsFeed bool
+	}{
+		{
+			Name:              "name_was_replicated",
+			HasName:           true,
+			WaitingFor:        time.Minute,
+			ExpectedPublished: true,
+		},
+		{
+			Name:              "name_was_not_replicated_yet",
+			HasName:           false,
+			WaitingFor:        time.Minute,
+			ExpectedPublished: false,
+		},
+		{
+			Name:               "name_was_not_replicated_in_time",
+			HasName:            false,
+			WaitingFor:         2 * time.Hour,
+			ExpectedPublished:  true,
+			ExpectedNameIsFeed: true,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			config := service.NewDefaultConfig()
+			config.WelcomeMessage = domain.MustNewWelcomeMessageTemplate("Welcome {{name}}!")
+
+			ts, err := di.BuildTestApplicationWithConfig(t, config)
+			require.NoError(t, err)
+
+			feed := fixtures.SomeRefFeed()
+			feedIdentity := refs.MustNewIdentityFromPublic(feed.Identity())
+			redeemedAt := fixtures.SomeTime()
+
+			err = ts.PendingWelcomes.Put(domain.MustNewPendingWelcome(feed, redeemedAt))
+			require.NoError(t, err)
+
+			if testCase.HasName {
+				ts.FeedRepository.MockMessages(someAboutMessages(feed, []known.About{
+					known.MustNewAbout(feedIdentity, "some name", "", nil),
+				}))
+			}
+
+			ts.Marshaler.MarshalReturnValue = fixtures.SomeRawContent()
+			ts.CurrentTimeProvider.CurrentTime = redeemedAt.Add(testCase.WaitingFor)
+
+			published, err := ts.Commands.PublishPendingWelcomes.Handle()
+			require.NoError(t, err)
+
+			pendingWelcomes, err := ts.PendingWelcomes.List()
+			require.NoError(t, err)
+
+			if !testCase.ExpectedPublished {
+				require.Equal(t, 0, published)
+				require.Empty(t, ts.Marshaler.MarshalCalls)
+				require.Len(t, pendingWelcomes, 1)
+				return
+			}
+
+			expectedName := "some name"
+			if testCase.ExpectedNameIsFeed {
+				expectedName = feed.String()
+			}
+
+			require.Equal(t, 1, published)
+			require.Equal(t,
+				[]mocks.MarshalerMockMarshalCall{
+					{
+						Content: known.MustNewPost(
+							fmt.Sprintf("Welcome %s!", expectedName),
+							[]known.Mention{
+								known.MustNewMention(feed, expectedName),
+							},
+						),
+					},
+				},
+				ts.Marshaler.MarshalCalls,
+			)
+			require.Len(t, ts.FeedRepository.UpdateFeedResults, 1)
+			require.Empty(t, pendingWelcomes)
+		})
+	}
+}
+
+func TestPublishPendingWelcomesHandler_DiscardsPendingWelcomesIfWelcomeMessageIsNotConfigured(t *testing.T) {
+	ts, err := di.BuildTestApplication(t)
+	require.NoError(t, err)
+
+	err = ts.PendingWelcomes.Put(domain.MustNewPendingWelcome(fixtures.SomeRefFeed(), fixtures.SomeTime()))
+	require.NoError(t, err)
+
+	published, err := ts.Commands.PublishPendingWelcomes.Handle()
+	require.NoError(t, err)
+	require.Equal(t, 0, published)
+
+	require.Empty(t, ts.Marshaler.MarshalCalls)
+	require.Empty(t, ts.FeedRepository.UpdateFeedResults)
+
+	pendingWelcomes, err := ts.PendingWelcomes.List()
+	require.NoError(t, err)
+	require.Empty(t, pendingWelcomes)
+}
+
+func TestPublishPendingWelcomesHandler_SanitizesNames(t *testing.T) {
+	config := service.NewDefaultConfig()
+	config.WelcomeMessage = domain.MustNewWelcomeMessageTemplate("Welcome {{name}}!")
+
+	ts, err := di.BuildTestApplicationWithConfig(t, config)
+	require.NoError(t, err)
+
+	feed := fixtures.SomeRefFeed()
+	feedIdentity := refs.MustNewIdentityFromPublic(feed.Identity())
+
+	err = ts.PendingWelcomes.Put(domain.MustNewPendingWelcome(feed, fixtures.SomeTime()))
+	require.NoError(t, err)
+
+	ts.FeedRepository.MockMessages(someAboutMessages(feed, []known.About{
+		known.MustNewAbout(feedIdentity, " [some\nname](http://example.com) ", "", nil),
+		known.MustNewAbout(feedIdentity, "\n[]\n", "", nil),
+	}))
+
+	ts.Marshaler.MarshalReturnValue = fixtures.SomeRawContent()
+	ts.CurrentTimeProvider.CurrentTime = fixtures.SomeTime()
+
+	published, err := ts.Commands.PublishPendingWelcomes.Handle()
+	require.NoError(t, err)
+	require.Equal(t, 1, published)
+
+	require.Equal(t,
+		[]mocks.MarshalerMockMarshalCall{
+			{
+				Content: known.MustNewPost(
+					"Welcome some namehttp://example.com!",
+					[]known.Mention{
+						known.MustNewMention(feed, "some namehttp://example.com"),
+					},
+				),
+			},
+		},
+		ts.Marshaler.MarshalCalls,
+	)
+}
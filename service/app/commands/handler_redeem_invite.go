@@ -42,23 +42,51 @@ type RedeemInviteHandler struct {
 	currentTimeProvider CurrentTimeProvider
 	marshaler           Marshaler
 	localIdentity       identity.Private
+	remoteFeedHeads     RemoteFeedHeads
+	welcomeMessage      domain.WelcomeMessageTemplate
+	metrics             Metrics
 }
 
+// NewRedeemInviteHandler creates a new handler. Welcome message is optional,
+// if it is a zero value then welcome posts aren't published.
 func NewRedeemInviteHandler(
 	transaction TransactionProvider,
 	currentTimeProvider CurrentTimeProvider,
 	marshaler Marshaler,
 	localIdentity identity.Private,
+	remoteFeedHeads RemoteFeedHeads,
+	welcomeMessage domain.WelcomeMessageTemplate,
+	metrics Metrics,
 ) *RedeemInviteHandler {
 	return &RedeemInviteHandler{
 		transaction:         transaction,
 		currentTimeProvider: currentTimeProvider,
 		marshaler:           marshaler,
 		localIdentity:       localIdentity,
+		remoteFeedHeads:     remoteFeedHeads,
+		welcomeMessage:      welcomeMessage,
+		metrics:             metrics,
 	}
 }
 
+// Handle redeems the invite and publishes a follow message. If a welcome
+// message was configured and the name of the new member is already known a
+// post greeting the new member is published right after the follow message.
+// Otherwise the post is published by PublishPendingWelcomesHandler once the
+// name of the new member is replicated. The returned ref points to the follow
+// message.
 func (h *RedeemInviteHandler) Handle(cmd RedeemInvite) (refs.Message, error) {
+	msgId, err := h.handle(cmd)
+	if err != nil {
+		h.metrics.InviteRejected()
+		return refs.Message{}, err
+	}
+
+	h.metrics.InviteRedeemed()
+	return msgId, nil
+}
+
+func (h *RedeemInviteHandler) handle(cmd RedeemInvite) (refs.Message, error) {
 	if cmd.IsZero() {
 		return refs.Message{}, errors.New("zero value of cmd")
 	}
@@ -78,6 +106,11 @@ func (h *RedeemInviteHandler) Handle(cmd RedeemInvite) (refs.Message, error) {
 		return refs.Message{}, errors.Wrap(err, "error creating message to publish")
 	}
 
+	welcomeMsgToPublish, err := h.createWelcomeMessageToPublish(cmd.FeedToFollow())
+	if err != nil {
+		return refs.Message{}, errors.Wrap(err, "error creating the welcome message to publish")
+	}
+
 	var msgId refs.Message
 
 	if err := h.transaction.Update(func(adapters Adapters) error {
@@ -101,12 +134,30 @@ func (h *RedeemInviteHandler) Handle(cmd RedeemInvite) (refs.Message, error) {
 			}
 		}
 
+		if !h.welcomeMessage.IsZero() && welcomeMsgToPublish == nil {
+			welcome, err := domain.NewPendingWelcome(cmd.FeedToFollow(), h.currentTimeProvider.Get())
+			if err != nil {
+				return errors.Wrap(err, "error creating the pending welcome")
+			}
+
+			if err := adapters.PendingWelcome.Put(welcome); err != nil {
+				return errors.Wrap(err, "error saving the pending welcome")
+			}
+		}
+
 		if err := adapters.Feed.UpdateFeed(localIdentityRef.MainFeed(), func(feed *feeds.Feed) error {
 			var err error
-			msgId, err = feed.CreateMessage(msgToPublish, h.currentTimeProvider.Get(), h.localIdentity)
+			msgId, err = createMessage(feed, h.remoteFeedHeads, localIdentityRef.MainFeed(), msgToPublish, h.currentTimeProvider.Get(), h.localIdentity)
 			if err != nil {
 				return errors.Wrap(err, "failed to create a message")
 			}
+
+			if welcomeMsgToPublish != nil {
+				if _, err := createMessage(feed, h.remoteFeedHeads, localIdentityRef.MainFeed(), *welcomeMsgToPublish, h.currentTimeProvider.Get(), h.localIdentity); err != nil {
+					return errors.Wrap(err, "failed to create the welcome message")
+				}
+			}
+
 			return nil
 		}); err != nil {
 			return errors.Wrap(err, "error updating the local feed")
@@ -133,3 +184,81 @@ func (h *RedeemInviteHandler) createMessageToPublish(feedRef refs.Identity) (mes
 
 	return content, nil
 }
+
+// createWelcomeMessageToPublish returns nil if welcome messages are disabled
+// or if the name of the new member isn't known yet in which case a pending
+// welcome should be saved instead. The name is looked up in a read-only
+// transaction as that requires reading the feed of the new member.
+func (h *RedeemInviteHandler) createWelcomeMessageToPublish(feedRef refs.Feed) (*message.RawContent, error) {
+	if h.welcomeMessage.IsZero() {
+		return nil, nil
+	}
+
+	var name string
+	var ok bool
+
+	if err := h.transaction.View(func(adapters Adapters) error {
+		var err error
+		name, ok, err = findMemberName(adapters.Feed, feedRef)
+		if err != nil {
+			return errors.Wrap(err, "error getting the name of the new member")
+		}
+		return nil
+	}); err != nil {
+		return nil, errors.Wrap(err, "read transaction failed")
+	}
+
+	if !ok {
+		return nil, nil
+	}
+
+	content, err := marshalWelcomePost(h.marshaler, h.welcomeMessage, feedRef, name)
+	if err != nil {
+		return nil, errors.Wrap(err, "error creating the welcome post")
+	}
+
+	return &content, nil
+}
+
+// findMemberName returns the name from the last about message which the
+// member published about themselves. The name is sanitized with
+// domain.SanitizeWelcomeMessageName and names which are empty after
+// sanitizing are skipped.
+func findMemberName(repository FeedRepository, feedRef refs.Feed) (string, bool, error) {
+	identityRef, err := refs.NewIdentityFromPublic(feedRef.Identity())
+	if err != nil {
+		return "", false, errors.Wrap(err, "error creating the identity ref")
+	}
+
+	lastAbout, err := findLastMessageContent(repository, feedRef, func(content known.About) bool {
+		return content.About().Equal(identityRef) && domain.SanitizeWelcomeMessageName(content.Name()) != ""
+	})
+	if err != nil {
+		return "", false, errors.Wrap(err, "error getting the last about message")
+	}
+
+	if lastAbout == nil {
+		return "", false, nil
+	}
+
+	return domain.SanitizeWelcomeMessageName(lastAbout.Name()), true, nil
+}
+
+func marshalWelcomePost(marshaler Marshaler, welcomeMessage domain.WelcomeMessageTemplate, feedRef refs.Feed, name string) (message.RawContent, error) {
+	mention, err := known.NewMention(feedRef, name)
+	if err != nil {
+		return message.RawContent{}, errors.Wrap(err, "error creating the mention")
+	}
+
+	post, err := known.NewPost(welcomeMessage.Render(name, feedRef), []known.Mention{mention})
+	if err != nil {
+		return message.RawContent{}, errors.Wrap(err, "error creating the post")
+	}
+
+	content, err := marshaler.Marshal(post)
+	if err != nil {
+		return message.RawContent{}, errors.Wrap(err, "error marshaling")
+	}
+
+	return content, nil
+}
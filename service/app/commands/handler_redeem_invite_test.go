@@ -2,10 +2,13 @@ package commands_test
 
 import (
 	"fmt"
+	"sync"
 	"testing"
 
+	"github.com/planetary-social/scuttlego-pub/internal"
 	"github.com/planetary-social/scuttlego-pub/internal/fixtures"
 	"github.com/planetary-social/scuttlego-pub/internal/mocks"
+	"github.com/planetary-social/scuttlego-pub/service"
 	"github.com/planetary-social/scuttlego-pub/service/app/commands"
 	"github.com/planetary-social/scuttlego-pub/service/di"
 	"github.com/planetary-social/scuttlego-pub/service/domain"
@@ -81,6 +84,168 @@ func TestRedeemInviteHandler_UpdatesAnInviteAndPublishesPubFollow(t *testing.T)
 		},
 		ts.FeedRepository.UpdateFeedResults[0].Result.PopForPersisting(),
 	)
+
+	require.Equal(t, mocks.MetricsMockInvites{Redeemed: 1}, ts.Metrics.Invites())
+}
+
+func TestRedeemInviteHandler_PublishesWelcomePostIfWelcomeMessageIsConfiguredAndNameIsKnown(t *testing.T) {
+	config := service.NewDefaultConfig()
+	config.WelcomeMessage = domain.MustNewWelcomeMessageTemplate("Welcome {{name}} ({{feed}})!")
+
+	ts, err := di.BuildTestApplicationWithConfig(t, config)
+	require.NoError(t, err)
+
+	secretKeySeed := fixtures.SomeSecretKeySeed()
+	invite := domain.MustNewInvite(secretKeySeed, nil, nil)
+	ts.InviteRepository.MockInvite(invite)
+
+	privateIdentity, err := identity.NewPrivateFromSeed(secretKeySeed.Bytes())
+	require.NoError(t, err)
+
+	localFeed := refs.MustNewIdentityFromPublic(ts.LocalIdentity.Public()).MainFeed()
+	feedToFollow := fixtures.SomeRefFeed()
+	feedToFollowIdentity := refs.MustNewIdentityFromPublic(feedToFollow.Identity())
+
+	ts.FeedRepository.MockMessages(someAboutMessages(feedToFollow, []known.About{
+		known.MustNewAbout(feedToFollowIdentity, "old name", "", nil),
+		known.MustNewAbout(feedToFollowIdentity, "new name", "", nil),
+		known.MustNewAbout(feedToFollowIdentity, "", "some description", nil),
+	}))
+
+	ts.Marshaler.MarshalReturnValue = fixtures.SomeRawContent()
+
+	cmd, err := commands.NewRedeemInvite(privateIdentity.Public(), feedToFollow)
+	require.NoError(t, err)
+
+	msgRef, err := ts.Commands.RedeemInvite.Handle(cmd)
+	require.NoError(t, err)
+
+	require.Equal(t,
+		[]mocks.MarshalerMockMarshalCall{
+			{
+				Content: known.MustNewPubFollow(feedToFollowIdentity),
+			},
+			{
+				Content: known.MustNewPost(
+					fmt.Sprintf("Welcome new name (%s)!", feedToFollow.String()),
+					[]known.Mention{
+						known.MustNewMention(feedToFollow, "new name"),
+					},
+				),
+			},
+		},
+		ts.Marshaler.MarshalCalls,
+	)
+
+	require.Len(t, ts.FeedRepository.UpdateFeedResults, 1)
+	require.Equal(t, localFeed, ts.FeedRepository.UpdateFeedResults[0].Id)
+
+	msgsToPersist := ts.FeedRepository.UpdateFeedResults[0].Result.PopForPersisting()
+	require.Len(t, msgsToPersist, 2)
+	require.Equal(t, msgRef, msgsToPersist[0].Message().Id(), "returned ref should point to the follow message")
+
+	pendingWelcomes, err := ts.PendingWelcomes.List()
+	require.NoError(t, err)
+	require.Empty(t, pendingWelcomes)
+}
+
+func TestRedeemInviteHandler_SavesPendingWelcomeIfNameIsNotKnown(t *testing.T) {
+	testCases := []struct {
+		Name   string
+		Abouts func(feedToFollow refs.Identity) []known.About
+	}{
+		{
+			Name: "no_abouts",
+			Abouts: func(feedToFollow refs.Identity) []known.About {
+				return nil
+			},
+		},
+		{
+			Name: "abouts_describing_other_identities_are_ignored",
+			Abouts: func(feedToFollow refs.Identity) []known.About {
+				return []known.About{
+					known.MustNewAbout(fixtures.SomeRefIdentity(), "some name", "", nil),
+				}
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			config := service.NewDefaultConfig()
+			config.WelcomeMessage = domain.MustNewWelcomeMessageTemplate("Welcome {{name}} ({{feed}})!")
+
+			ts, err := di.BuildTestApplicationWithConfig(t, config)
+			require.NoError(t, err)
+
+			secretKeySeed := fixtures.SomeSecretKeySeed()
+			invite := domain.MustNewInvite(secretKeySeed, nil, nil)
+			ts.InviteRepository.MockInvite(invite)
+
+			privateIdentity, err := identity.NewPrivateFromSeed(secretKeySeed.Bytes())
+			require.NoError(t, err)
+
+			feedToFollow := fixtures.SomeRefFeed()
+			feedToFollowIdentity := refs.MustNewIdentityFromPublic(feedToFollow.Identity())
+
+			ts.FeedRepository.MockMessages(someAboutMessages(feedToFollow, testCase.Abouts(feedToFollowIdentity)))
+
+			ts.Marshaler.MarshalReturnValue = fixtures.SomeRawContent()
+			ts.CurrentTimeProvider.CurrentTime = fixtures.SomeTime()
+
+			cmd, err := commands.NewRedeemInvite(privateIdentity.Public(), feedToFollow)
+			require.NoError(t, err)
+
+			_, err = ts.Commands.RedeemInvite.Handle(cmd)
+			require.NoError(t, err)
+
+			require.Equal(t,
+				[]mocks.MarshalerMockMarshalCall{
+					{
+						Content: known.MustNewPubFollow(feedToFollowIdentity),
+					},
+				},
+				ts.Marshaler.MarshalCalls,
+			)
+
+			pendingWelcomes, err := ts.PendingWelcomes.List()
+			require.NoError(t, err)
+			require.Equal(t,
+				[]domain.PendingWelcome{
+					domain.MustNewPendingWelcome(feedToFollow, ts.CurrentTimeProvider.CurrentTime),
+				},
+				pendingWelcomes,
+			)
+		})
+	}
+}
+
+func TestRedeemInviteHandler_RefusesToPublishIfLocalFeedIsBehind(t *testing.T) {
+	ts, err := di.BuildTestApplication(t)
+	require.NoError(t, err)
+
+	secretKeySeed := fixtures.SomeSecretKeySeed()
+	invite := domain.MustNewInvite(secretKeySeed, nil, nil)
+	ts.InviteRepository.MockInvite(invite)
+
+	privateIdentity, err := identity.NewPrivateFromSeed(secretKeySeed.Bytes())
+	require.NoError(t, err)
+
+	localFeed := refs.MustNewIdentityFromPublic(ts.LocalIdentity.Public()).MainFeed()
+	ts.RemoteFeedHeads.Mock(localFeed, fixtures.SomeSequence())
+
+	ts.Marshaler.MarshalReturnValue = fixtures.SomeRawContent()
+
+	cmd, err := commands.NewRedeemInvite(privateIdentity.Public(), fixtures.SomeRefFeed())
+	require.NoError(t, err)
+
+	_, err = ts.Commands.RedeemInvite.Handle(cmd)
+	require.ErrorIs(t, err, commands.ErrLocalFeedIsBehind)
+
+	require.Empty(t, ts.FeedRepository.UpdateFeedResults)
+	require.Empty(t, ts.FeedFormat.SignCalls)
+
+	require.Equal(t, mocks.MetricsMockInvites{Rejected: 1}, ts.Metrics.Invites())
 }
 
 func TestRedeemInviteHandler_ReturnsAnErrorIfTheUserIsAlreadyBeingFollowed(t *testing.T) {
@@ -114,6 +279,8 @@ func TestRedeemInviteHandler_ReturnsAnErrorIfTheUserIsAlreadyBeingFollowed(t *te
 
 	_, err = ts.Commands.RedeemInvite.Handle(cmd)
 	require.EqualError(t, err, "transaction failed: already following this user")
+
+	require.Equal(t, mocks.MetricsMockInvites{Rejected: 1}, ts.Metrics.Invites())
 }
 
 func TestRedeemInviteHandler_UsesProvidedIdentityToGetAnInvite(t *testing.T) {
@@ -134,3 +301,93 @@ func TestRedeemInviteHandler_UsesProvidedIdentityToGetAnInvite(t *testing.T) {
 	)
 
 }
+
+func TestRedeemInviteHandler_ConcurrentRedemptions(t *testing.T) {
+	testCases := []struct {
+		Name                string
+		NumberOfUses        int
+		NumberOfRedemptions int
+	}{
+		{
+			Name:                "redemptions_of_the_same_invite_do_not_fail",
+			NumberOfUses:        10,
+			NumberOfRedemptions: 10,
+		},
+		{
+			Name:                "redemptions_do_not_exceed_number_of_uses",
+			NumberOfUses:        3,
+			NumberOfRedemptions: 10,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			ts, err := di.BuildBadgerTestApplication(t)
+			require.NoError(t, err)
+
+			invite := domain.MustNewInvite(fixtures.SomeSecretKeySeed(), internal.Pointer(testCase.NumberOfUses), nil)
+
+			err = ts.TransactionProvider.Update(func(adapters di.TestAdapters) error {
+				return adapters.InviteRepository.Put(invite)
+			})
+			require.NoError(t, err)
+
+			var succeeded int
+			for _, err := range redeemConcurrently(t, ts, invite, testCase.NumberOfRedemptions) {
+				if err == nil {
+					succeeded++
+					continue
+				}
+				require.ErrorContains(t, err, "invite has no remaining uses")
+			}
+
+			require.Equal(t, testCase.NumberOfUses, succeeded)
+
+			err = ts.TransactionProvider.Update(func(adapters di.TestAdapters) error {
+				return adapters.InviteRepository.Update(invite.Identity().Public(), func(invite *domain.Invite) error {
+					remainingUses, ok := invite.RemainingUses()
+					require.True(t, ok)
+					require.Equal(t, 0, remainingUses)
+					return nil
+				})
+			})
+			require.NoError(t, err)
+		})
+	}
+}
+
+func redeemConcurrently(t *testing.T, ts di.BadgerTestApplication, invite *domain.Invite, numberOfRedemptions int) []error {
+	var wg sync.WaitGroup
+	errCh := make(chan error, numberOfRedemptions)
+
+	for i := 0; i < numberOfRedemptions; i++ {
+		cmd, err := commands.NewRedeemInvite(invite.Identity().Public(), fixtures.SomeRefFeed())
+		require.NoError(t, err)
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := ts.Commands.RedeemInvite.Handle(cmd)
+			errCh <- err
+		}()
+	}
+
+	wg.Wait()
+	close(errCh)
+
+	var errs []error
+	for err := range errCh {
+		errs = append(errs, err)
+	}
+	return errs
+}
+
+func someAboutMessages(feed refs.Feed, abouts []known.About) []message.Message {
+	var msgs []message.Message
+	sequence := message.NewFirstSequence()
+	for _, about := range abouts {
+		msgs = append(msgs, fixtures.SomeMessageWithFeedSequenceContent(feed, sequence, message.MustNewContent(fixtures.SomeRawContent(), about, nil)))
+		sequence = sequence.Next()
+	}
+	return msgs
+}
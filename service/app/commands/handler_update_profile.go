@@ -0,0 +1,130 @@
+package commands
+
+import (
+	"io"
+
+	"github.com/boreq/errors"
+	known "github.com/planetary-social/scuttlego-pub/service/domain/messages"
+	"github.com/planetary-social/scuttlego/service/domain/feeds"
+	"github.com/planetary-social/scuttlego/service/domain/identity"
+	"github.com/planetary-social/scuttlego/service/domain/refs"
+)
+
+type UpdateProfile struct {
+	name        string
+	description string
+	image       io.Reader
+}
+
+// NewUpdateProfile creates a new command. All fields are optional, image can
+// be nil. Fields which are empty clear the previously published values.
+func NewUpdateProfile(name string, description string, image io.Reader) (UpdateProfile, error) {
+	return UpdateProfile{name: name, description: description, image: image}, nil
+}
+
+func (cmd UpdateProfile) Name() string {
+	return cmd.name
+}
+
+func (cmd UpdateProfile) Description() string {
+	return cmd.description
+}
+
+func (cmd UpdateProfile) Image() (io.Reader, bool) {
+	return cmd.image, cmd.image != nil
+}
+
+type UpdateProfileHandler struct {
+	transaction         TransactionProvider
+	blobCreator         BlobCreator
+	currentTimeProvider CurrentTimeProvider
+	marshaler           Marshaler
+	localIdentity       identity.Private
+	remoteFeedHeads     RemoteFeedHeads
+}
+
+func NewUpdateProfileHandler(
+	transaction TransactionProvider,
+	blobCreator BlobCreator,
+	currentTimeProvider CurrentTimeProvider,
+	marshaler Marshaler,
+	localIdentity identity.Private,
+	remoteFeedHeads RemoteFeedHeads,
+) *UpdateProfileHandler {
+	return &UpdateProfileHandler{
+		transaction:         transaction,
+		blobCreator:         blobCreator,
+		currentTimeProvider: currentTimeProvider,
+		marshaler:           marshaler,
+		localIdentity:       localIdentity,
+		remoteFeedHeads:     remoteFeedHeads,
+	}
+}
+
+// Handle stores the image as a blob and publishes an about message describing
+// the pub unless the last about message published by the pub is identical.
+// Fields which were set in the last about message but are now empty are
+// explicitly cleared. It returns true if a new message was published.
+func (h *UpdateProfileHandler) Handle(cmd UpdateProfile) (bool, error) {
+	localIdentityRef, err := refs.NewIdentityFromPublic(h.localIdentity.Public())
+	if err != nil {
+		return false, errors.Wrap(err, "could not create the identity ref")
+	}
+
+	var imageRef *refs.Blob
+	if image, ok := cmd.Image(); ok {
+		blob, err := h.blobCreator.Create(image)
+		if err != nil {
+			return false, errors.Wrap(err, "error creating the image blob")
+		}
+		imageRef = &blob
+	}
+
+	about, err := known.NewAbout(localIdentityRef, cmd.Name(), cmd.Description(), imageRef)
+	if err != nil {
+		return false, errors.Wrap(err, "error creating the about message")
+	}
+
+	var lastAbout *known.About
+
+	if err := h.transaction.View(func(adapters Adapters) error {
+		lastAbout, err = findLastMessageContent(adapters.Feed, localIdentityRef.MainFeed(), func(content known.About) bool {
+			return content.About().Equal(localIdentityRef)
+		})
+		if err != nil {
+			return errors.Wrap(err, "error getting the last about message")
+		}
+		return nil
+	}); err != nil {
+		return false, errors.Wrap(err, "read transaction failed")
+	}
+
+	if lastAbout == nil {
+		if about.IsEmpty() {
+			return false, nil
+		}
+	} else {
+		if lastAbout.Equal(about) {
+			return false, nil
+		}
+		about = about.WithClearedFields(*lastAbout)
+	}
+
+	msgToPublish, err := h.marshaler.Marshal(about)
+	if err != nil {
+		return false, errors.Wrap(err, "error marshaling")
+	}
+
+	if err := h.transaction.Update(func(adapters Adapters) error {
+		return adapters.Feed.UpdateFeed(localIdentityRef.MainFeed(), func(feed *feeds.Feed) error {
+			if _, err := createMessage(feed, h.remoteFeedHeads, localIdentityRef.MainFeed(), msgToPublish, h.currentTimeProvider.Get(), h.localIdentity); err != nil {
+				return errors.Wrap(err, "failed to create a message")
+			}
+			return nil
+		})
+	}); err != nil {
+		return false, errors.Wrap(err, "transaction failed")
+	}
+
+	return true, nil
+}
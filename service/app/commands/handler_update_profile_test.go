@@ -0,0 +1,269 @@
+package commands_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/planetary-social/scuttlego-pub/internal"
+	"github.com/planetary-social/scuttlego-pub/internal/fixtures"
+	"github.com/planetary-social/scuttlego-pub/internal/mocks"
+	"github.com/planetary-social/scuttlego-pub/service/app/commands"
+	"github.com/planetary-social/scuttlego-pub/service/di"
+	known "github.com/planetary-social/scuttlego-pub/service/domain/messages"
+	"github.com/planetary-social/scuttlego/service/domain/feeds/message"
+	"github.com/planetary-social/scuttlego/service/domain/refs"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUpdateProfileHandler(t *testing.T) {
+	const (
+		name        = "some name"
+		description = "some description"
+	)
+
+	image := refs.MustNewBlob("&uaGieSQDJcHfUp6hjIcIq55GoZh4Ug7tNmgaohoxrpw=.sha256")
+
+	testCases := []struct {
+		Name                    string
+		PreviousAbouts          func(local refs.Identity) []known.About
+		ShouldPublishNewMessage bool
+	}{
+		{
+			Name: "no_previous_abouts",
+			PreviousAbouts: func(local refs.Identity) []known.About {
+				return nil
+			},
+			ShouldPublishNewMessage: true,
+		},
+		{
+			Name: "last_about_is_the_same",
+			PreviousAbouts: func(local refs.Identity) []known.About {
+				return []known.About{
+					known.MustNewAbout(local, "old name", description, nil),
+					known.MustNewAbout(local, name, description, &image),
+				}
+			},
+			ShouldPublishNewMessage: false,
+		},
+		{
+			Name: "last_about_is_different",
+			PreviousAbouts: func(local refs.Identity) []known.About {
+				return []known.About{
+					known.MustNewAbout(local, name, description, &image),
+					known.MustNewAbout(local, name, "old description", &image),
+				}
+			},
+			ShouldPublishNewMessage: true,
+		},
+		{
+			Name: "last_about_has_no_image",
+			PreviousAbouts: func(local refs.Identity) []known.About {
+				return []known.About{
+					known.MustNewAbout(local, name, description, nil),
+				}
+			},
+			ShouldPublishNewMessage: true,
+		},
+		{
+			Name: "abouts_describing_other_identities_are_ignored",
+			PreviousAbouts: func(local refs.Identity) []known.About {
+				return []known.About{
+					known.MustNewAbout(fixtures.SomeRefIdentity(), name, description, &image),
+				}
+			},
+			ShouldPublishNewMessage: true,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			ts, err := di.BuildTestApplication(t)
+			require.NoError(t, err)
+
+			localIdentity := refs.MustNewIdentityFromPublic(ts.LocalIdentity.Public())
+			localFeed := localIdentity.MainFeed()
+
+			var msgs []message.Message
+			sequence := message.NewFirstSequence()
+			for _, about := range testCase.PreviousAbouts(localIdentity) {
+				msgs = append(msgs, fixtures.SomeMessageWithFeedSequenceContent(localFeed, sequence, message.MustNewContent(fixtures.SomeRawContent(), about, nil)))
+				sequence = sequence.Next()
+			}
+			ts.FeedRepository.MockMessages(msgs)
+
+			ts.BlobCreator.CreateReturnValue = image
+			ts.Marshaler.MarshalReturnValue = fixtures.SomeRawContent()
+			ts.FeedFormat.SignReturnValue = fixtures.SomeMessageWithFeedSequence(localFeed, message.NewFirstSequence())
+
+			imageBytes := fixtures.SomeBytesOfLength(100)
+
+			cmd, err := commands.NewUpdateProfile(name, description, bytes.NewReader(imageBytes))
+			require.NoError(t, err)
+
+			published, err := ts.Commands.UpdateProfile.Handle(cmd)
+			require.NoError(t, err)
+			require.Equal(t, testCase.ShouldPublishNewMessage, published)
+
+			require.Equal(t, [][]byte{imageBytes}, ts.BlobCreator.CreateCalls)
+
+			if testCase.ShouldPublishNewMessage {
+				require.Equal(t,
+					[]mocks.MarshalerMockMarshalCall{
+						{
+							Content: known.MustNewAbout(localIdentity, name, description, internal.Pointer(image)),
+						},
+					},
+					ts.Marshaler.MarshalCalls,
+				)
+				require.Len(t, ts.FeedRepository.UpdateFeedResults, 1)
+				require.Equal(t, localFeed, ts.FeedRepository.UpdateFeedResults[0].Id)
+			} else {
+				require.Empty(t, ts.Marshaler.MarshalCalls)
+				require.Empty(t, ts.FeedRepository.UpdateFeedResults)
+			}
+		})
+	}
+}
+
+func TestUpdateProfileHandler_ClearsFieldsRemovedFromTheProfile(t *testing.T) {
+	image := refs.MustNewBlob("&uaGieSQDJcHfUp6hjIcIq55GoZh4Ug7tNmgaohoxrpw=.sha256")
+
+	testCases := []struct {
+		Name                    string
+		PreviousAbouts          func(local refs.Identity) []known.About
+		NewName                 string
+		ExpectedContent         func(local refs.Identity) known.About
+		ShouldPublishNewMessage bool
+	}{
+		{
+			Name: "cleared_fields_are_published_as_empty_values",
+			PreviousAbouts: func(local refs.Identity) []known.About {
+				return []known.About{
+					known.MustNewAbout(local, "some name", "some description", &image),
+				}
+			},
+			NewName: "some name",
+			ExpectedContent: func(local refs.Identity) known.About {
+				about := known.MustNewAbout(local, "some name", "", nil).WithClearedFields(
+					known.MustNewAbout(local, "some name", "some description", &image),
+				)
+				require.False(t, about.ClearsName())
+				require.True(t, about.ClearsDescription())
+				require.True(t, about.ClearsImage())
+				return about
+			},
+			ShouldPublishNewMessage: true,
+		},
+		{
+			Name: "all_fields_can_be_cleared",
+			PreviousAbouts: func(local refs.Identity) []known.About {
+				return []known.About{
+					known.MustNewAbout(local, "some name", "", nil),
+				}
+			},
+			NewName: "",
+			ExpectedContent: func(local refs.Identity) known.About {
+				return known.MustNewAbout(local, "", "", nil).WithClearedFields(
+					known.MustNewAbout(local, "some name", "", nil),
+				)
+			},
+			ShouldPublishNewMessage: true,
+		},
+		{
+			Name: "cleared_fields_are_not_cleared_again",
+			PreviousAbouts: func(local refs.Identity) []known.About {
+				return []known.About{
+					known.MustNewAbout(local, "some name", "some description", &image),
+					known.MustNewAbout(local, "some name", "", nil),
+				}
+			},
+			NewName:                 "some name",
+			ShouldPublishNewMessage: false,
+		},
+		{
+			Name: "empty_profile_is_not_published_if_there_was_no_profile",
+			PreviousAbouts: func(local refs.Identity) []known.About {
+				return nil
+			},
+			NewName:                 "",
+			ShouldPublishNewMessage: false,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			ts, err := di.BuildTestApplication(t)
+			require.NoError(t, err)
+
+			localIdentity := refs.MustNewIdentityFromPublic(ts.LocalIdentity.Public())
+			localFeed := localIdentity.MainFeed()
+
+			var msgs []message.Message
+			sequence := message.NewFirstSequence()
+			for _, about := range testCase.PreviousAbouts(localIdentity) {
+				msgs = append(msgs, fixtures.SomeMessageWithFeedSequenceContent(localFeed, sequence, message.MustNewContent(fixtures.SomeRawContent(), about, nil)))
+				sequence = sequence.Next()
+			}
+			ts.FeedRepository.MockMessages(msgs)
+
+			ts.Marshaler.MarshalReturnValue = fixtures.SomeRawContent()
+			ts.FeedFormat.SignReturnValue = fixtures.SomeMessageWithFeedSequence(localFeed, message.NewFirstSequence())
+
+			cmd, err := commands.NewUpdateProfile(testCase.NewName, "", nil)
+			require.NoError(t, err)
+
+			published, err := ts.Commands.UpdateProfile.Handle(cmd)
+			require.NoError(t, err)
+			require.Equal(t, testCase.ShouldPublishNewMessage, published)
+
+			if testCase.ShouldPublishNewMessage {
+				require.Equal(t,
+					[]mocks.MarshalerMockMarshalCall{
+						{
+							Content: testCase.ExpectedContent(localIdentity),
+						},
+					},
+					ts.Marshaler.MarshalCalls,
+				)
+				require.Len(t, ts.FeedRepository.UpdateFeedResults, 1)
+			} else {
+				require.Empty(t, ts.Marshaler.MarshalCalls)
+				require.Empty(t, ts.FeedRepository.UpdateFeedResults)
+			}
+		})
+	}
+}
+
+func TestUpdateProfileHandler_PublishesOnlyWhenProfileChanges(t *testing.T) {
+	ts, err := di.BuildBadgerTestApplication(t)
+	require.NoError(t, err)
+
+	cmd, err := commands.NewUpdateProfile("some name", "some description", nil)
+	require.NoError(t, err)
+
+	published, err := ts.Commands.UpdateProfile.Handle(cmd)
+	require.NoError(t, err)
+	require.True(t, published)
+
+	published, err = ts.Commands.UpdateProfile.Handle(cmd)
+	require.NoError(t, err)
+	require.False(t, published)
+
+	cmd, err = commands.NewUpdateProfile("some other name", "some description", nil)
+	require.NoError(t, err)
+
+	published, err = ts.Commands.UpdateProfile.Handle(cmd)
+	require.NoError(t, err)
+	require.True(t, published)
+
+	cmd, err = commands.NewUpdateProfile("some other name", "", nil)
+	require.NoError(t, err)
+
+	published, err = ts.Commands.UpdateProfile.Handle(cmd)
+	require.NoError(t, err)
+	require.True(t, published)
+
+	published, err = ts.Commands.UpdateProfile.Handle(cmd)
+	require.NoError(t, err)
+	require.False(t, published)
+}
@@ -0,0 +1,52 @@
+package queries
+
+import (
+	"github.com/planetary-social/scuttlego/service/domain/identity"
+)
+
+type ConnectedPeersHandler struct {
+	peerManager       PeerManager
+	connectionTracker ConnectionTracker
+}
+
+func NewConnectedPeersHandler(
+	peerManager PeerManager,
+	connectionTracker ConnectionTracker,
+) *ConnectedPeersHandler {
+	return &ConnectedPeersHandler{
+		peerManager:       peerManager,
+		connectionTracker: connectionTracker,
+	}
+}
+
+// Handle returns the connected peers in the order in which they are returned
+// by the peer manager.
+func (h *ConnectedPeersHandler) Handle() []PeerStatus {
+	var identities []identity.Public
+	for _, peer := range h.peerManager.Peers() {
+		identities = append(identities, peer.Identity())
+	}
+	return newPeerStatuses(identities, h.connectionTracker.Connections())
+}
+
+// newPeerStatuses adds the details of tracked connections to the provided
+// peers. If there is more than one connection to a peer then the details of
+// one of them are used.
+func newPeerStatuses(peers []identity.Public, connections []Connection) []PeerStatus {
+	connectionsByIdentity := make(map[string]Connection)
+	for _, connection := range connections {
+		connectionsByIdentity[connection.Identity.String()] = connection
+	}
+
+	var result []PeerStatus
+	for _, peer := range peers {
+		peerStatus := PeerStatus{
+			Identity: peer,
+		}
+		if connection, ok := connectionsByIdentity[peer.String()]; ok {
+			peerStatus.Connection = &connection
+		}
+		result = append(result, peerStatus)
+	}
+	return result
+}
@@ -0,0 +1,52 @@
+package queries_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/planetary-social/scuttlego-pub/internal/fixtures"
+	"github.com/planetary-social/scuttlego-pub/internal/mocks"
+	"github.com/planetary-social/scuttlego-pub/service/app/queries"
+	domainmocks "github.com/planetary-social/scuttlego/service/domain/mocks"
+	"github.com/planetary-social/scuttlego/service/domain/transport"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConnectedPeersHandler(t *testing.T) {
+	trackedPeer := fixtures.SomePublicIdentity()
+	untrackedPeer := fixtures.SomePublicIdentity()
+
+	connection := queries.Connection{
+		Identity:          trackedPeer,
+		Address:           "192.0.2.1:8008",
+		ConnectedAt:       time.Date(2030, 1, 2, 3, 4, 5, 0, time.UTC),
+		InitiatedByRemote: true,
+	}
+
+	peerManager := domainmocks.NewPeerManagerMock()
+	peerManager.MockPeers([]transport.Peer{
+		transport.MustNewPeer(trackedPeer, domainmocks.NewConnectionMock(context.Background())),
+		transport.MustNewPeer(untrackedPeer, domainmocks.NewConnectionMock(context.Background())),
+	})
+
+	connectionTracker := mocks.NewConnectionTrackerMock()
+	connectionTracker.ConnectionsReturnValue = []queries.Connection{
+		connection,
+	}
+
+	handler := queries.NewConnectedPeersHandler(peerManager, connectionTracker)
+
+	require.Equal(t,
+		[]queries.PeerStatus{
+			{
+				Identity:   trackedPeer,
+				Connection: &connection,
+			},
+			{
+				Identity: untrackedPeer,
+			},
+		},
+		handler.Handle(),
+	)
+}
@@ -0,0 +1,53 @@
+package queries
+
+import (
+	"github.com/planetary-social/scuttlego-pub/service/domain"
+	"github.com/planetary-social/scuttlego/service/domain/transport"
+)
+
+type PeerManager interface {
+	// Peers returns the peers which are currently connected.
+	Peers() []transport.Peer
+}
+
+// CurrentPreferredPeers returns the preferred peers from the current config.
+type CurrentPreferredPeers func() []domain.MultiserverAddress
+
+type PreferredPeerStatus struct {
+	Peer      domain.MultiserverAddress
+	Connected bool
+}
+
+type PreferredPeersStatusHandler struct {
+	preferredPeers CurrentPreferredPeers
+	peerManager    PeerManager
+}
+
+func NewPreferredPeersStatusHandler(
+	preferredPeers CurrentPreferredPeers,
+	peerManager PeerManager,
+) *PreferredPeersStatusHandler {
+	return &PreferredPeersStatusHandler{
+		preferredPeers: preferredPeers,
+		peerManager:    peerManager,
+	}
+}
+
+// Handle returns the status of every preferred peer in the order in which
+// they appear in the config.
+func (h *PreferredPeersStatusHandler) Handle() []PreferredPeerStatus {
+	connected := make(map[string]struct{})
+	for _, peer := range h.peerManager.Peers() {
+		connected[peer.Identity().String()] = struct{}{}
+	}
+
+	var result []PreferredPeerStatus
+	for _, preferredPeer := range h.preferredPeers() {
+		_, ok := connected[preferredPeer.Identity().String()]
+		result = append(result, PreferredPeerStatus{
+			Peer:      preferredPeer,
+			Connected: ok,
+		})
+	}
+	return result
+}
@@ -0,0 +1,50 @@
+package queries_test
+
+import (
+	"context"
+	"encoding/base64"
+	"testing"
+
+	"github.com/planetary-social/scuttlego-pub/internal/fixtures"
+	"github.com/planetary-social/scuttlego-pub/service/app/queries"
+	"github.com/planetary-social/scuttlego-pub/service/domain"
+	"github.com/planetary-social/scuttlego/service/domain/mocks"
+	"github.com/planetary-social/scuttlego/service/domain/transport"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPreferredPeersStatusHandler(t *testing.T) {
+	connectedPeer := somePreferredPeer()
+	disconnectedPeer := somePreferredPeer()
+
+	peerManager := mocks.NewPeerManagerMock()
+	peerManager.MockPeers([]transport.Peer{
+		transport.MustNewPeer(connectedPeer.Identity(), mocks.NewConnectionMock(context.Background())),
+		transport.MustNewPeer(fixtures.SomePublicIdentity(), mocks.NewConnectionMock(context.Background())),
+	})
+
+	handler := queries.NewPreferredPeersStatusHandler(
+		func() []domain.MultiserverAddress {
+			return []domain.MultiserverAddress{disconnectedPeer, connectedPeer}
+		},
+		peerManager,
+	)
+
+	require.Equal(t,
+		[]queries.PreferredPeerStatus{
+			{
+				Peer:      disconnectedPeer,
+				Connected: false,
+			},
+			{
+				Peer:      connectedPeer,
+				Connected: true,
+			},
+		},
+		handler.Handle(),
+	)
+}
+
+func somePreferredPeer() domain.MultiserverAddress {
+	return domain.MustNewMultiserverAddress("net:example.com:8008~shs:" + base64.StdEncoding.EncodeToString(fixtures.SomePublicIdentity().PublicKey()))
+}
@@ -0,0 +1,140 @@
+package queries
+
+import (
+	"time"
+
+	"github.com/boreq/errors"
+	"github.com/planetary-social/scuttlego/service/app/queries"
+	"github.com/planetary-social/scuttlego/service/domain/identity"
+)
+
+// ErrBlobStorageSizeUnknown is returned by BlobStorageUsage if the size of the
+// blob storage hasn't been determined yet.
+var ErrBlobStorageSizeUnknown = errors.New("the size of the blob storage hasn't been determined yet")
+
+type StatusQueryHandler interface {
+	Handle() (queries.StatusResult, error)
+}
+
+type ConnectionTracker interface {
+	// Connections returns the open connections.
+	Connections() []Connection
+}
+
+type BlobStorageUsage interface {
+	// Size returns the size of the stored blobs in bytes or
+	// ErrBlobStorageSizeUnknown.
+	Size() (int64, error)
+}
+
+type InviteCounter interface {
+	// InviteCounts returns the number of invite events which occurred since
+	// the pub was started.
+	InviteCounts() InviteCounts
+}
+
+type CurrentTimeProvider interface {
+	Get() time.Time
+}
+
+type Connection struct {
+	Identity identity.Public
+
+	// Address is empty if the connection doesn't have a network address e.g.
+	// because it was tunneled through a room.
+	Address string
+
+	ConnectedAt       time.Time
+	InitiatedByRemote bool
+}
+
+type InviteCounts struct {
+	Created  uint64
+	Redeemed uint64
+	Rejected uint64
+}
+
+type Status struct {
+	StartedAt        time.Time
+	Peers            []PeerStatus
+	NumberOfFeeds    int
+	NumberOfMessages int
+
+	// BlobStorageBytes is nil if the size of the blob storage hasn't been
+	// determined yet.
+	BlobStorageBytes *int64
+
+	Invites InviteCounts
+}
+
+type PeerStatus struct {
+	Identity identity.Public
+
+	// Connection is nil if the connection wasn't tracked.
+	Connection *Connection
+}
+
+type StatusHandler struct {
+	status            StatusQueryHandler
+	connectionTracker ConnectionTracker
+	blobStorageUsage  BlobStorageUsage
+	inviteCounter     InviteCounter
+	startedAt         time.Time
+}
+
+// NewStatusHandler should be called when the pub is started as the current
+// time is recorded as the time at which the pub was started.
+func NewStatusHandler(
+	status StatusQueryHandler,
+	connectionTracker ConnectionTracker,
+	blobStorageUsage BlobStorageUsage,
+	inviteCounter InviteCounter,
+	currentTimeProvider CurrentTimeProvider,
+) *StatusHandler {
+	return &StatusHandler{
+		status:            status,
+		connectionTracker: connectionTracker,
+		blobStorageUsage:  blobStorageUsage,
+		inviteCounter:     inviteCounter,
+		startedAt:         currentTimeProvider.Get(),
+	}
+}
+
+// Handle returns the current status of the pub. The connected peers are
+// listed in the order in which they are returned by the peer manager.
+func (h *StatusHandler) Handle() (Status, error) {
+	status, err := h.status.Handle()
+	if err != nil {
+		return Status{}, errors.Wrap(err, "error getting the status")
+	}
+
+	blobStorageBytes, err := h.blobStorageSize()
+	if err != nil {
+		return Status{}, errors.Wrap(err, "error getting the size of the blob storage")
+	}
+
+	var identities []identity.Public
+	for _, peer := range status.Peers {
+		identities = append(identities, peer.Identity)
+	}
+
+	return Status{
+		StartedAt:        h.startedAt,
+		Peers:            newPeerStatuses(identities, h.connectionTracker.Connections()),
+		NumberOfFeeds:    status.NumberOfFeeds,
+		NumberOfMessages: status.NumberOfMessages,
+		BlobStorageBytes: blobStorageBytes,
+		Invites:          h.inviteCounter.InviteCounts(),
+	}, nil
+}
+
+func (h *StatusHandler) blobStorageSize() (*int64, error) {
+	size, err := h.blobStorageUsage.Size()
+	if err != nil {
+		if errors.Is(err, ErrBlobStorageSizeUnknown) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &size, nil
+}
@@ -0,0 +1,120 @@
+package queries_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/boreq/errors"
+	"github.com/planetary-social/scuttlego-pub/internal/fixtures"
+	"github.com/planetary-social/scuttlego-pub/internal/mocks"
+	"github.com/planetary-social/scuttlego-pub/service/app/queries"
+	scuttlegoqueries "github.com/planetary-social/scuttlego/service/app/queries"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStatusHandler(t *testing.T) {
+	startedAt := time.Date(2030, 1, 2, 3, 4, 5, 0, time.UTC)
+	trackedPeer := fixtures.SomePublicIdentity()
+	untrackedPeer := fixtures.SomePublicIdentity()
+	blobStorageBytes := int64(30)
+
+	connection := queries.Connection{
+		Identity:          trackedPeer,
+		Address:           "192.0.2.1:8008",
+		ConnectedAt:       startedAt.Add(time.Hour),
+		InitiatedByRemote: true,
+	}
+
+	status := mocks.NewStatusQueryHandlerMock()
+	status.HandleReturnValue = scuttlegoqueries.StatusResult{
+		NumberOfMessages: 20,
+		NumberOfFeeds:    10,
+		Peers: []scuttlegoqueries.Peer{
+			{Identity: untrackedPeer},
+			{Identity: trackedPeer},
+		},
+	}
+
+	connectionTracker := mocks.NewConnectionTrackerMock()
+	connectionTracker.ConnectionsReturnValue = []queries.Connection{
+		connection,
+		{
+			Identity: fixtures.SomePublicIdentity(),
+		},
+	}
+
+	blobStorageUsage := mocks.NewBlobStorageUsageMock()
+	blobStorageUsage.SizeReturnValue = blobStorageBytes
+
+	inviteCounter := mocks.NewInviteCounterMock()
+	inviteCounter.InviteCountsReturnValue = queries.InviteCounts{
+		Created:  1,
+		Redeemed: 2,
+		Rejected: 3,
+	}
+
+	currentTimeProvider := mocks.NewCurrentTimeProviderMock()
+	currentTimeProvider.CurrentTime = startedAt
+
+	handler := queries.NewStatusHandler(status, connectionTracker, blobStorageUsage, inviteCounter, currentTimeProvider)
+
+	result, err := handler.Handle()
+	require.NoError(t, err)
+
+	require.Equal(t,
+		queries.Status{
+			StartedAt: startedAt,
+			Peers: []queries.PeerStatus{
+				{
+					Identity: untrackedPeer,
+				},
+				{
+					Identity:   trackedPeer,
+					Connection: &connection,
+				},
+			},
+			NumberOfFeeds:    10,
+			NumberOfMessages: 20,
+			BlobStorageBytes: &blobStorageBytes,
+			Invites: queries.InviteCounts{
+				Created:  1,
+				Redeemed: 2,
+				Rejected: 3,
+			},
+		},
+		result,
+	)
+}
+
+func TestStatusHandler_SizeOfTheBlobStorageIsNilIfItIsUnknown(t *testing.T) {
+	blobStorageUsage := mocks.NewBlobStorageUsageMock()
+	blobStorageUsage.SizeReturnErr = queries.ErrBlobStorageSizeUnknown
+
+	handler := queries.NewStatusHandler(
+		mocks.NewStatusQueryHandlerMock(),
+		mocks.NewConnectionTrackerMock(),
+		blobStorageUsage,
+		mocks.NewInviteCounterMock(),
+		mocks.NewCurrentTimeProviderMock(),
+	)
+
+	result, err := handler.Handle()
+	require.NoError(t, err)
+	require.Nil(t, result.BlobStorageBytes)
+}
+
+func TestStatusHandler_ReturnsOtherErrorsOfTheBlobStorageUsage(t *testing.T) {
+	blobStorageUsage := mocks.NewBlobStorageUsageMock()
+	blobStorageUsage.SizeReturnErr = errors.New("some error")
+
+	handler := queries.NewStatusHandler(
+		mocks.NewStatusQueryHandlerMock(),
+		mocks.NewConnectionTrackerMock(),
+		blobStorageUsage,
+		mocks.NewInviteCounterMock(),
+		mocks.NewCurrentTimeProviderMock(),
+	)
+
+	_, err := handler.Handle()
+	require.Error(t, err)
+}
@@ -0,0 +1,41 @@
+package service
+
+import (
+	"fmt"
+)
+
+var (
+	BadgerPresetDefault   = BadgerPreset{"default"}
+	BadgerPresetLowMemory = BadgerPreset{"low-memory"}
+)
+
+// BadgerPreset is a set of values of the memory related options of the
+// database which are used unless they are set explicitly.
+type BadgerPreset struct {
+	s string
+}
+
+func NewBadgerPreset(s string) (BadgerPreset, error) {
+	for _, preset := range []BadgerPreset{BadgerPresetDefault, BadgerPresetLowMemory} {
+		if preset.s == s {
+			return preset, nil
+		}
+	}
+	return BadgerPreset{}, fmt.Errorf("unknown badger preset '%s', valid presets are 'default' and 'low-memory'", s)
+}
+
+func MustNewBadgerPreset(s string) BadgerPreset {
+	v, err := NewBadgerPreset(s)
+	if err != nil {
+		panic(err)
+	}
+	return v
+}
+
+func (p BadgerPreset) String() string {
+	return p.s
+}
+
+func (p BadgerPreset) IsZero() bool {
+	return p == BadgerPreset{}
+}
@@ -1,6 +1,16 @@
 package service
 
 import (
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/boreq/errors"
+	"github.com/planetary-social/scuttlego-pub/service/domain"
 	"github.com/planetary-social/scuttlego/service/domain/feeds/formats"
 	"github.com/planetary-social/scuttlego/service/domain/graph"
 	"github.com/planetary-social/scuttlego/service/domain/transport/boxstream"
@@ -11,10 +21,34 @@ type Config struct {
 	// will be stored.
 	DataDirectory string
 
-	// ListenAddress for the TCP listener in the format accepted by the
-	// standard library.
-	// Optional, defaults to ":8008".
-	ListenAddress string
+	// ListenAddresses for the TCP listeners in the format accepted by the
+	// standard library. A separate listener is started for each address
+	// which makes it possible to for example bind to several specific IPv4
+	// and IPv6 addresses. Wildcard addresses such as ":8008", "0.0.0.0:8008"
+	// and "[::]:8008" listen on all IPv4 and IPv6 interfaces so they can't
+	// be combined with other addresses using the same port. All of them are
+	// advertised on the local network if LocalAdvertising is enabled.
+	// Optional, defaults to a single address ":8008".
+	ListenAddresses []string
+
+	// PublicAddress under which other peers can reach this pub in the
+	// format "host:port". It is announced on the pub's feed using a pub
+	// message.
+	// Optional, if it isn't set then the pub doesn't announce itself.
+	PublicAddress string
+
+	// HTTPListenAddress is the address of the HTTP listener serving the
+	// /healthz and /readyz endpoints used by orchestrators to check if the
+	// pub is alive and ready and the /metrics endpoint exposing metrics in
+	// the Prometheus format.
+	// Optional, if it isn't set then the HTTP listener isn't started.
+	HTTPListenAddress string
+
+	// AdminSocket is the path of the unix domain socket used by the CLI to
+	// manage the running pub. It is accessible only to the user running the
+	// pub.
+	// Optional, defaults to admin.sock in the data directory.
+	AdminSocket string
 
 	// Setting NetworkKey is mainly useful for test networks.
 	// Optional, defaults to boxstream.NewDefaultNetworkKey().
@@ -24,18 +58,529 @@ type Config struct {
 	// Optional, defaults to formats.NewDefaultMessageHMAC().
 	MessageHMAC formats.MessageHMAC
 
+	// IdentityFile is the path to the identity file or a file descriptor in
+	// the format "fd:N". Relative paths are resolved against the config
+	// directory. The file must not be accessible to the group or other
+	// users.
+	// Optional, defaults to the identity file in the config directory.
+	IdentityFile string
+
+	// NetworkKeyFile is the path to a file containing the network key
+	// encoded as hex or base64 or a file descriptor in the format "fd:N". If
+	// it is set then NetworkKey is loaded from it. Relative paths are
+	// resolved against the config directory. The file must not be accessible
+	// to the group or other users.
+	// Optional.
+	NetworkKeyFile string
+
+	// MessageHMACFile is the path to a file containing the message HMAC
+	// encoded as hex or base64 or a file descriptor in the format "fd:N". If
+	// it is set then MessageHMAC is loaded from it. Relative paths are
+	// resolved against the config directory. The file must not be accessible
+	// to the group or other users.
+	// Optional.
+	MessageHMACFile string
+
+	// PreferredPeers are peers which the pub tries to remain connected to,
+	// for example other pubs which should replicate with this one.
+	// Optional.
+	PreferredPeers []domain.MultiserverAddress
+
+	// LocalAdvertising specifies if the listen addresses are broadcast over
+	// UDP so that clients on the local network can discover the pub.
+	// Optional, defaults to true.
+	LocalAdvertising bool
+
+	// LocalDiscovery specifies if the pub listens for UDP broadcasts of other
+	// peers on the local network and connects to them.
+	// Optional, defaults to true.
+	LocalDiscovery bool
+
+	// LocalNetworkInterfaces restricts local advertising and discovery to the
+	// named network interfaces.
+	// Optional, if it isn't set then all interfaces are used.
+	LocalNetworkInterfaces []string
+
 	// Hops specifies how far away the feeds which are automatically replicated
 	// based on contact messages can be in the social graph.
 	// Optional, defaults to 1 (people the pub followed).
 	Hops graph.Hops
+
+	// Name of the pub published in an about message.
+	// Optional.
+	Name string
+
+	// Description of the pub published in an about message.
+	// Optional.
+	Description string
+
+	// ImagePath points to an image file which is stored as a blob and
+	// published in an about message as the pub's avatar.
+	// Optional.
+	//
+	// Removing the name, description or image path clears the previously
+	// published value when the pub is started.
+	ImagePath string
+
+	// LogLevel specifies the most verbose level of log messages which are
+	// displayed.
+	// Optional, defaults to LogLevelError.
+	LogLevel LogLevel
+
+	// LogComponentLevels override LogLevel for the selected components.
+	// Optional.
+	LogComponentLevels map[LogComponent]LogLevel
+
+	// LogFormat specifies how log messages are formatted.
+	// Optional, defaults to LogFormatText.
+	LogFormat LogFormat
+
+	// LogFile is the path to a file to which log messages are written
+	// instead of the standard error. The file is rotated once it grows
+	// larger than LogFileMaxSizeMegabytes.
+	// Optional.
+	LogFile string
+
+	// LogFileMaxSizeMegabytes is the size after which the log file is
+	// rotated.
+	// Optional, defaults to 100.
+	LogFileMaxSizeMegabytes int
+
+	// LogFileMaxBackups is the number of rotated log files which are kept.
+	// Optional, defaults to 5.
+	LogFileMaxBackups int
+
+	// BadgerPreset provides the values of the memory related options of the
+	// database which aren't set explicitly.
+	// Optional, defaults to BadgerPresetDefault.
+	BadgerPreset BadgerPreset
+
+	// BadgerMemTableSizeMegabytes overrides the size of a single memtable.
+	// Optional, if it is zero then the value from the preset is used.
+	BadgerMemTableSizeMegabytes int
+
+	// BadgerBlockCacheSizeMegabytes overrides the size of the block cache.
+	// Optional, if it is zero then the value from the preset is used.
+	BadgerBlockCacheSizeMegabytes int
+
+	// BadgerIndexCacheSizeMegabytes overrides the size of the index cache.
+	// Optional, if it is zero then the value from the preset is used.
+	BadgerIndexCacheSizeMegabytes int
+
+	// BadgerValueLogFileSizeMegabytes overrides the size of a single value
+	// log file.
+	// Optional, if it is zero then the value from the preset is used.
+	BadgerValueLogFileSizeMegabytes int
+
+	// BadgerNumCompactors overrides the number of compaction workers.
+	// Optional, if it is zero then the value from the preset is used.
+	BadgerNumCompactors int
+
+	// BadgerSyncWrites specifies if writes are synced to disk before
+	// transactions are committed. Disabling it improves performance but
+	// recent changes may be lost if the machine crashes.
+	// Optional, defaults to true.
+	BadgerSyncWrites bool
+
+	// ShutdownDrainPeriod is the time given to existing connections to
+	// finish replication after the pub is asked to stop and stops accepting
+	// new connections. Once it passes the connections are closed. The
+	// database is closed only after all parts of the pub stopped.
+	// Optional, defaults to 10 seconds.
+	ShutdownDrainPeriod time.Duration
+
+	// WelcomeMessage is used to publish a post greeting each new member
+	// after they redeem an invite.
+	// Optional, if it isn't set then welcome posts aren't published.
+	WelcomeMessage domain.WelcomeMessageTemplate
 }
 
+// placeholderDataDirectory is used by NewDefaultConfig as there is no
+// sensible default. It has to be changed before running the pub.
+const placeholderDataDirectory = "/some/data/directory"
+
+const defaultAdminSocketFileName = "admin.sock"
+
 func NewDefaultConfig() Config {
 	return Config{
-		DataDirectory: "/some/data/directory",
-		ListenAddress: ":8008",
-		NetworkKey:    boxstream.NewDefaultNetworkKey(),
-		MessageHMAC:   formats.NewDefaultMessageHMAC(),
-		Hops:          graph.MustNewHops(1),
+		DataDirectory:           placeholderDataDirectory,
+		ListenAddresses:         []string{":8008"},
+		NetworkKey:              boxstream.NewDefaultNetworkKey(),
+		MessageHMAC:             formats.NewDefaultMessageHMAC(),
+		LocalAdvertising:        true,
+		LocalDiscovery:          true,
+		Hops:                    graph.MustNewHops(1),
+		LogLevel:                LogLevelError,
+		LogFormat:               LogFormatText,
+		LogFileMaxSizeMegabytes: 100,
+		LogFileMaxBackups:       5,
+		BadgerPreset:            BadgerPresetDefault,
+		BadgerSyncWrites:        true,
+		ShutdownDrainPeriod:     10 * time.Second,
+	}
+}
+
+// Validate checks if the config can be used to run the pub. It returns a
+// *ConfigValidationError listing all problems or nil if there are none.
+// Validate inspects the filesystem to check if the data directory is writable
+// and the image exists. It also checks if the local network interfaces exist.
+func (c Config) Validate() error {
+	var problems []error
+
+	addProblem := func(field string, err error) {
+		problems = append(problems, errors.Wrap(err, field))
+	}
+
+	if err := validateDataDirectory(c.DataDirectory); err != nil {
+		addProblem("data directory", err)
+	}
+
+	if err := validateListenAddresses(c.ListenAddresses); err != nil {
+		addProblem("listen addresses", err)
+	}
+
+	if c.PublicAddress != "" {
+		if err := validatePublicAddress(c.PublicAddress); err != nil {
+			addProblem("public address", err)
+		}
+	}
+
+	if c.HTTPListenAddress != "" {
+		if err := validateListenAddress(c.HTTPListenAddress); err != nil {
+			addProblem("http listen address", err)
+		}
+	}
+
+	if err := validateAdminSocket(c.AdminSocketPath()); err != nil {
+		addProblem("admin socket", err)
+	}
+
+	if err := validateLocalNetworkInterfaces(c.LocalNetworkInterfaces); err != nil {
+		addProblem("local network interfaces", err)
+	}
+
+	if c.NetworkKey.IsZero() {
+		addProblem("network key", errors.New("not set"))
+	}
+
+	if c.LogLevel.IsZero() {
+		addProblem("log level", errors.New("not set"))
+	}
+
+	if err := validateLogComponentLevels(c.LogComponentLevels); err != nil {
+		addProblem("log component levels", err)
+	}
+
+	if c.LogFormat.IsZero() {
+		addProblem("log format", errors.New("not set"))
+	}
+
+	if c.LogFile != "" {
+		if err := validateLogFile(c.LogFile, c.LogFileMaxSizeMegabytes, c.LogFileMaxBackups); err != nil {
+			addProblem("log file", err)
+		}
+	}
+
+	if err := validateBadgerOptions(c); err != nil {
+		addProblem("badger", err)
+	}
+
+	if c.ShutdownDrainPeriod <= 0 {
+		addProblem("shutdown drain period", errors.New("must be positive"))
+	}
+
+	if c.ImagePath != "" {
+		if err := validateImagePath(c.ImagePath); err != nil {
+			addProblem("image path", err)
+		}
+	}
+
+	if len(problems) > 0 {
+		return &ConfigValidationError{Problems: problems}
+	}
+
+	return nil
+}
+
+// AdminSocketPath returns AdminSocket or the default path of the admin socket
+// if it isn't set.
+func (c Config) AdminSocketPath() string {
+	if c.AdminSocket != "" {
+		return c.AdminSocket
+	}
+	return filepath.Join(c.DataDirectory, defaultAdminSocketFileName)
+}
+
+// ConfigValidationError is returned by Config.Validate.
+type ConfigValidationError struct {
+	Problems []error
+}
+
+func (e *ConfigValidationError) Error() string {
+	var problems []string
+	for _, problem := range e.Problems {
+		problems = append(problems, problem.Error())
+	}
+	return fmt.Sprintf("config is invalid: %s", strings.Join(problems, "; "))
+}
+
+func validateDataDirectory(directory string) error {
+	if directory == "" {
+		return errors.New("not set")
+	}
+
+	if directory == placeholderDataDirectory {
+		return errors.New("set to the placeholder value from the default config")
+	}
+
+	// the data directory is created if it doesn't exist so the closest
+	// existing parent has to be writable
+	for {
+		stat, err := os.Stat(directory)
+		if err == nil {
+			if !stat.IsDir() {
+				return fmt.Errorf("'%s' is not a directory", directory)
+			}
+			return checkDirectoryIsWritable(directory)
+		}
+
+		if !os.IsNotExist(err) {
+			return errors.Wrap(err, "stat error")
+		}
+
+		parent := filepath.Dir(directory)
+		if parent == directory {
+			return errors.New("none of the parent directories exist")
+		}
+		directory = parent
+	}
+}
+
+func checkDirectoryIsWritable(directory string) error {
+	f, err := os.CreateTemp(directory, ".write-check-*")
+	if err != nil {
+		return fmt.Errorf("directory '%s' is not writable: %w", directory, err)
 	}
+
+	if err := f.Close(); err != nil {
+		return errors.Wrap(err, "error closing the temporary file")
+	}
+
+	if err := os.Remove(f.Name()); err != nil {
+		return errors.Wrap(err, "error removing the temporary file")
+	}
+
+	return nil
+}
+
+func validateListenAddresses(addresses []string) error {
+	if len(addresses) == 0 {
+		return errors.New("not set")
+	}
+
+	seen := make(map[string]struct{})
+	addressesByPort := make(map[int][]string)
+	for _, address := range addresses {
+		if _, ok := seen[address]; ok {
+			return fmt.Errorf("address '%s' is duplicated", address)
+		}
+		seen[address] = struct{}{}
+
+		if err := validateListenAddress(address); err != nil {
+			return errors.Wrapf(err, "invalid address '%s'", address)
+		}
+
+		_, port, err := splitListenAddress(address)
+		if err != nil {
+			return errors.Wrapf(err, "invalid address '%s'", address)
+		}
+		addressesByPort[port] = append(addressesByPort[port], address)
+	}
+
+	// Listening on a wildcard address binds to both IPv4 and IPv6 so any
+	// other listener using the same port fails with EADDRINUSE.
+	for _, address := range addresses {
+		host, port, err := splitListenAddress(address)
+		if err != nil {
+			return errors.Wrapf(err, "invalid address '%s'", address)
+		}
+
+		if isWildcardHost(host) && len(addressesByPort[port]) > 1 {
+			return fmt.Errorf("address '%s' listens on all IPv4 and IPv6 interfaces so it overlaps with other addresses using port %d", address, port)
+		}
+	}
+
+	return nil
+}
+
+func splitListenAddress(address string) (string, int, error) {
+	host, portString, err := net.SplitHostPort(address)
+	if err != nil {
+		return "", 0, errors.Wrap(err, "error splitting the address")
+	}
+
+	port, err := net.LookupPort("tcp", portString)
+	if err != nil {
+		return "", 0, errors.Wrap(err, "invalid port")
+	}
+
+	return host, port, nil
+}
+
+func isWildcardHost(host string) bool {
+	if host == "" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsUnspecified()
+}
+
+func validateListenAddress(address string) error {
+	if address == "" {
+		return errors.New("not set")
+	}
+
+	_, port, err := net.SplitHostPort(address)
+	if err != nil {
+		return errors.Wrap(err, "error splitting the address")
+	}
+
+	if _, err := net.LookupPort("tcp", port); err != nil {
+		return errors.Wrap(err, "invalid port")
+	}
+
+	return nil
+}
+
+func validatePublicAddress(address string) error {
+	host, portString, err := net.SplitHostPort(address)
+	if err != nil {
+		return errors.Wrap(err, "error splitting the address")
+	}
+
+	if host == "" {
+		return errors.New("host is empty")
+	}
+
+	port, err := strconv.Atoi(portString)
+	if err != nil {
+		return errors.Wrap(err, "error parsing the port")
+	}
+
+	if port <= 0 || port > 65535 {
+		return errors.New("port must be between 1 and 65535")
+	}
+
+	return nil
+}
+
+// maxAdminSocketPathLength is the lowest limit of the length of unix domain
+// socket paths among the supported operating systems.
+const maxAdminSocketPathLength = 103
+
+func validateAdminSocket(path string) error {
+	if len(path) > maxAdminSocketPathLength {
+		return fmt.Errorf("path '%s' is longer than %d bytes", path, maxAdminSocketPathLength)
+	}
+	return nil
+}
+
+func validateLocalNetworkInterfaces(names []string) error {
+	seen := make(map[string]struct{})
+	for _, name := range names {
+		if _, ok := seen[name]; ok {
+			return fmt.Errorf("interface '%s' is duplicated", name)
+		}
+		seen[name] = struct{}{}
+	}
+
+	for _, name := range names {
+		if _, err := net.InterfaceByName(name); err != nil {
+			return errors.Wrapf(err, "invalid interface '%s'", name)
+		}
+	}
+
+	return nil
+}
+
+func validateLogComponentLevels(levels map[LogComponent]LogLevel) error {
+	for component, level := range levels {
+		if component.IsZero() {
+			return errors.New("component not set")
+		}
+
+		if level.IsZero() {
+			return fmt.Errorf("level of component '%s' not set", component)
+		}
+	}
+	return nil
+}
+
+func validateLogFile(path string, maxSizeMegabytes, maxBackups int) error {
+	if maxSizeMegabytes <= 0 {
+		return errors.New("max size must be positive")
+	}
+
+	if maxBackups < 0 {
+		return errors.New("max backups can't be negative")
+	}
+
+	directory := filepath.Dir(path)
+	stat, err := os.Stat(directory)
+	if err != nil {
+		return errors.Wrap(err, "stat error")
+	}
+
+	if !stat.IsDir() {
+		return fmt.Errorf("'%s' is not a directory", directory)
+	}
+
+	return nil
+}
+
+func validateBadgerOptions(c Config) error {
+	if c.BadgerPreset.IsZero() {
+		return errors.New("preset not set")
+	}
+
+	sizes := []struct {
+		name  string
+		value int
+	}{
+		{"memtable size", c.BadgerMemTableSizeMegabytes},
+		{"block cache size", c.BadgerBlockCacheSizeMegabytes},
+		{"index cache size", c.BadgerIndexCacheSizeMegabytes},
+		{"value log file size", c.BadgerValueLogFileSizeMegabytes},
+	}
+
+	for _, size := range sizes {
+		if size.value < 0 {
+			return fmt.Errorf("%s can't be negative", size.name)
+		}
+	}
+
+	// badger requires value log files to be smaller than 2GB
+	if c.BadgerValueLogFileSizeMegabytes >= 2048 {
+		return errors.New("value log file size must be smaller than 2048 megabytes")
+	}
+
+	// badger refuses to run with a single compactor
+	if c.BadgerNumCompactors < 0 || c.BadgerNumCompactors == 1 {
+		return errors.New("number of compactors must be zero or at least two")
+	}
+
+	return nil
+}
+
+func validateImagePath(path string) error {
+	stat, err := os.Stat(path)
+	if err != nil {
+		return errors.Wrap(err, "stat error")
+	}
+
+	if !stat.Mode().IsRegular() {
+		return fmt.Errorf("'%s' is not a regular file", path)
+	}
+
+	return nil
 }
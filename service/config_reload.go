@@ -0,0 +1,71 @@
+package service
+
+import (
+	"reflect"
+	"sync"
+)
+
+// reloadableConfigFields lists the fields of Config which can be changed
+// while the pub is running.
+var reloadableConfigFields = map[string]struct{}{
+	"Hops":           {},
+	"LogLevel":       {},
+	"PreferredPeers": {},
+}
+
+// CurrentConfig holds the config of the running pub. Components which need
+// to pick up the fields changed by Reload should call Get every time they
+// need the config instead of storing it.
+type CurrentConfig struct {
+	lock   sync.Mutex
+	config Config
+}
+
+func NewCurrentConfig(config Config) *CurrentConfig {
+	return &CurrentConfig{config: config}
+}
+
+func (c *CurrentConfig) Get() Config {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return c.config
+}
+
+// Reload copies the fields which can be changed while the pub is running from
+// the new config. The remaining fields are left untouched.
+func (c *CurrentConfig) Reload(newConfig Config) ConfigReloadResult {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	var result ConfigReloadResult
+
+	current := reflect.ValueOf(&c.config).Elem()
+	updated := reflect.ValueOf(newConfig)
+
+	for i := 0; i < current.NumField(); i++ {
+		name := current.Type().Field(i).Name
+
+		if reflect.DeepEqual(current.Field(i).Interface(), updated.Field(i).Interface()) {
+			continue
+		}
+
+		if _, ok := reloadableConfigFields[name]; !ok {
+			result.Rejected = append(result.Rejected, name)
+			continue
+		}
+
+		current.Field(i).Set(updated.Field(i))
+		result.Changed = append(result.Changed, name)
+	}
+
+	return result
+}
+
+type ConfigReloadResult struct {
+	// Changed contains the names of the fields which were changed.
+	Changed []string
+
+	// Rejected contains the names of the fields which were different in the
+	// new config but can't be changed without restarting the pub.
+	Rejected []string
+}
@@ -0,0 +1,46 @@
+package service_test
+
+import (
+	"encoding/base64"
+	"testing"
+
+	"github.com/planetary-social/scuttlego-pub/internal/fixtures"
+	"github.com/planetary-social/scuttlego-pub/service"
+	"github.com/planetary-social/scuttlego-pub/service/domain"
+	"github.com/planetary-social/scuttlego/service/domain/graph"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCurrentConfig_ReloadWithoutChangesDoesNothing(t *testing.T) {
+	config := service.NewDefaultConfig()
+	currentConfig := service.NewCurrentConfig(config)
+
+	result := currentConfig.Reload(config)
+	require.Empty(t, result.Changed)
+	require.Empty(t, result.Rejected)
+	require.Equal(t, config, currentConfig.Get())
+}
+
+func TestCurrentConfig_ReloadChangesOnlyReloadableFields(t *testing.T) {
+	config := service.NewDefaultConfig()
+	currentConfig := service.NewCurrentConfig(config)
+
+	newConfig := config
+	newConfig.Hops = graph.MustNewHops(2)
+	newConfig.LogLevel = service.LogLevelDebug
+	newConfig.ListenAddresses = []string{":8009"}
+	newConfig.Name = "new name"
+	newConfig.PreferredPeers = []domain.MultiserverAddress{
+		domain.MustNewMultiserverAddress("net:example.com:8008~shs:" + base64.StdEncoding.EncodeToString(fixtures.SomePublicIdentity().PublicKey())),
+	}
+
+	result := currentConfig.Reload(newConfig)
+	require.Equal(t, []string{"PreferredPeers", "Hops", "LogLevel"}, result.Changed)
+	require.Equal(t, []string{"ListenAddresses", "Name"}, result.Rejected)
+
+	expectedConfig := config
+	expectedConfig.Hops = graph.MustNewHops(2)
+	expectedConfig.LogLevel = service.LogLevelDebug
+	expectedConfig.PreferredPeers = newConfig.PreferredPeers
+	require.Equal(t, expectedConfig, currentConfig.Get())
+}
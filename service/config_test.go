@@ -0,0 +1,280 @@
+package service_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/planetary-social/scuttlego-pub/internal/fixtures"
+	"github.com/planetary-social/scuttlego-pub/service"
+	"github.com/planetary-social/scuttlego/service/domain/transport/boxstream"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfig_Validate(t *testing.T) {
+	directory := fixtures.Directory(t)
+
+	imagePath := filepath.Join(directory, "image.png")
+	err := os.WriteFile(imagePath, fixtures.SomeBytesOfLength(10), 0o600)
+	require.NoError(t, err)
+
+	testCases := []struct {
+		Name           string
+		Modify         func(config *service.Config)
+		ExpectedErrors []string
+	}{
+		{
+			Name: "valid",
+			Modify: func(config *service.Config) {
+			},
+		},
+		{
+			Name: "valid_with_optional_fields",
+			Modify: func(config *service.Config) {
+				config.PublicAddress = "example.com:8008"
+				config.ImagePath = imagePath
+			},
+		},
+		{
+			Name: "data_directory_which_doesnt_exist_yet",
+			Modify: func(config *service.Config) {
+				config.DataDirectory = filepath.Join(directory, "some", "data")
+			},
+		},
+		{
+			Name: "placeholder_data_directory",
+			Modify: func(config *service.Config) {
+				config.DataDirectory = service.NewDefaultConfig().DataDirectory
+			},
+			ExpectedErrors: []string{
+				"data directory: set to the placeholder value from the default config",
+			},
+		},
+		{
+			Name: "data_directory_is_a_file",
+			Modify: func(config *service.Config) {
+				config.DataDirectory = imagePath
+			},
+			ExpectedErrors: []string{
+				"data directory: '" + imagePath + "' is not a directory",
+			},
+		},
+		{
+			Name: "multiple_listen_addresses",
+			Modify: func(config *service.Config) {
+				config.ListenAddresses = []string{"127.0.0.1:8008", "[::1]:8008", ":8009"}
+			},
+		},
+		{
+			Name: "overlapping_wildcard_listen_addresses",
+			Modify: func(config *service.Config) {
+				config.ListenAddresses = []string{"0.0.0.0:8008", "[::]:8008"}
+			},
+			ExpectedErrors: []string{
+				"listen addresses: address '0.0.0.0:8008' listens on all IPv4 and IPv6 interfaces so it overlaps with other addresses using port 8008",
+			},
+		},
+		{
+			Name: "wildcard_listen_address_overlapping_with_a_specific_address",
+			Modify: func(config *service.Config) {
+				config.ListenAddresses = []string{"127.0.0.1:8008", ":8008"}
+			},
+			ExpectedErrors: []string{
+				"listen addresses: address ':8008' listens on all IPv4 and IPv6 interfaces so it overlaps with other addresses using port 8008",
+			},
+		},
+		{
+			Name: "no_listen_addresses",
+			Modify: func(config *service.Config) {
+				config.ListenAddresses = nil
+			},
+			ExpectedErrors: []string{
+				"listen addresses: not set",
+			},
+		},
+		{
+			Name: "duplicated_listen_addresses",
+			Modify: func(config *service.Config) {
+				config.ListenAddresses = []string{":8008", ":8008"}
+			},
+			ExpectedErrors: []string{
+				"listen addresses: address ':8008' is duplicated",
+			},
+		},
+		{
+			Name: "unknown_local_network_interface",
+			Modify: func(config *service.Config) {
+				config.LocalNetworkInterfaces = []string{"unknown-interface"}
+			},
+			ExpectedErrors: []string{
+				"local network interfaces: invalid interface 'unknown-interface'",
+			},
+		},
+		{
+			Name: "duplicated_local_network_interfaces",
+			Modify: func(config *service.Config) {
+				config.LocalNetworkInterfaces = []string{"unknown-interface", "unknown-interface"}
+			},
+			ExpectedErrors: []string{
+				"local network interfaces: interface 'unknown-interface' is duplicated",
+			},
+		},
+		{
+			Name: "log_file",
+			Modify: func(config *service.Config) {
+				config.LogFile = filepath.Join(directory, "log")
+			},
+		},
+		{
+			Name: "log_file_in_missing_directory",
+			Modify: func(config *service.Config) {
+				config.LogFile = filepath.Join(directory, "missing", "log")
+			},
+			ExpectedErrors: []string{
+				"log file: stat error",
+			},
+		},
+		{
+			Name: "log_file_with_invalid_max_size",
+			Modify: func(config *service.Config) {
+				config.LogFile = filepath.Join(directory, "log")
+				config.LogFileMaxSizeMegabytes = 0
+			},
+			ExpectedErrors: []string{
+				"log file: max size must be positive",
+			},
+		},
+		{
+			Name: "log_component_level_not_set",
+			Modify: func(config *service.Config) {
+				config.LogComponentLevels = map[service.LogComponent]service.LogLevel{
+					service.LogComponentNetwork: {},
+				}
+			},
+			ExpectedErrors: []string{
+				"log component levels: level of component 'network' not set",
+			},
+		},
+		{
+			Name: "badger_low_memory_preset_with_overrides",
+			Modify: func(config *service.Config) {
+				config.BadgerPreset = service.BadgerPresetLowMemory
+				config.BadgerMemTableSizeMegabytes = 8
+				config.BadgerNumCompactors = 2
+			},
+		},
+		{
+			Name: "badger_preset_not_set",
+			Modify: func(config *service.Config) {
+				config.BadgerPreset = service.BadgerPreset{}
+			},
+			ExpectedErrors: []string{
+				"badger: preset not set",
+			},
+		},
+		{
+			Name: "badger_negative_size",
+			Modify: func(config *service.Config) {
+				config.BadgerBlockCacheSizeMegabytes = -1
+			},
+			ExpectedErrors: []string{
+				"badger: block cache size can't be negative",
+			},
+		},
+		{
+			Name: "badger_value_log_file_too_large",
+			Modify: func(config *service.Config) {
+				config.BadgerValueLogFileSizeMegabytes = 2048
+			},
+			ExpectedErrors: []string{
+				"badger: value log file size must be smaller than 2048 megabytes",
+			},
+		},
+		{
+			Name: "badger_single_compactor",
+			Modify: func(config *service.Config) {
+				config.BadgerNumCompactors = 1
+			},
+			ExpectedErrors: []string{
+				"badger: number of compactors must be zero or at least two",
+			},
+		},
+		{
+			Name: "http_listen_address_invalid",
+			Modify: func(config *service.Config) {
+				config.HTTPListenAddress = "invalid"
+			},
+			ExpectedErrors: []string{
+				"http listen address: error splitting the address",
+			},
+		},
+		{
+			Name: "admin_socket_too_long",
+			Modify: func(config *service.Config) {
+				config.AdminSocket = "/" + strings.Repeat("a", 103)
+			},
+			ExpectedErrors: []string{
+				"admin socket: path '/" + strings.Repeat("a", 103) + "' is longer than 103 bytes",
+			},
+		},
+		{
+			Name: "shutdown_drain_period_not_positive",
+			Modify: func(config *service.Config) {
+				config.ShutdownDrainPeriod = 0
+			},
+			ExpectedErrors: []string{
+				"shutdown drain period: must be positive",
+			},
+		},
+		{
+			Name: "all_problems_are_reported",
+			Modify: func(config *service.Config) {
+				config.DataDirectory = ""
+				config.ListenAddresses = []string{"invalid"}
+				config.PublicAddress = "example.com:0"
+				config.NetworkKey = boxstream.NetworkKey{}
+				config.ImagePath = filepath.Join(directory, "missing.png")
+			},
+			ExpectedErrors: []string{
+				"data directory: not set",
+				"listen addresses: invalid address 'invalid': error splitting the address",
+				"public address: port must be between 1 and 65535",
+				"network key: not set",
+				"image path: stat error",
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			config := service.NewDefaultConfig()
+			config.DataDirectory = directory
+			testCase.Modify(&config)
+
+			err := config.Validate()
+			if len(testCase.ExpectedErrors) == 0 {
+				require.NoError(t, err)
+				return
+			}
+
+			var validationErr *service.ConfigValidationError
+			require.ErrorAs(t, err, &validationErr)
+			require.Len(t, validationErr.Problems, len(testCase.ExpectedErrors))
+			for i, expectedError := range testCase.ExpectedErrors {
+				require.ErrorContains(t, validationErr.Problems[i], expectedError)
+			}
+		})
+	}
+}
+
+func TestConfig_AdminSocketPath(t *testing.T) {
+	config := service.NewDefaultConfig()
+	config.DataDirectory = "/some/directory"
+
+	require.Equal(t, "/some/directory/admin.sock", config.AdminSocketPath())
+
+	config.AdminSocket = "/some/other/directory/admin.sock"
+
+	require.Equal(t, "/some/other/directory/admin.sock", config.AdminSocketPath())
+}
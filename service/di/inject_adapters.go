@@ -5,7 +5,12 @@ import (
 
 	"github.com/google/wire"
 	"github.com/planetary-social/scuttlego-pub/service"
+	pubadapters "github.com/planetary-social/scuttlego-pub/service/adapters"
+	pubbadgeradapters "github.com/planetary-social/scuttlego-pub/service/adapters/badger"
 	pubcommands "github.com/planetary-social/scuttlego-pub/service/app/commands"
+	pubqueries "github.com/planetary-social/scuttlego-pub/service/app/queries"
+	httpport "github.com/planetary-social/scuttlego-pub/service/ports/http"
+	pubportspubsub "github.com/planetary-social/scuttlego-pub/service/ports/pubsub"
 	"github.com/planetary-social/scuttlego/logging"
 	"github.com/planetary-social/scuttlego/service/adapters"
 	"github.com/planetary-social/scuttlego/service/adapters/badger"
@@ -16,6 +21,7 @@ import (
 	"github.com/planetary-social/scuttlego/service/app/queries"
 	blobreplication "github.com/planetary-social/scuttlego/service/domain/blobs/replication"
 	"github.com/planetary-social/scuttlego/service/domain/invites"
+	"github.com/planetary-social/scuttlego/service/domain/replication"
 	"github.com/planetary-social/scuttlego/service/domain/replication/ebt"
 	"github.com/planetary-social/scuttlego/service/domain/transport/boxstream"
 )
@@ -27,6 +33,7 @@ var blobsAdaptersSet = wire.NewSet(
 	wire.Bind(new(queries.BlobStorage), new(*blobs.FilesystemStorage)),
 	wire.Bind(new(blobreplication.BlobSizeRepository), new(*blobs.FilesystemStorage)),
 	wire.Bind(new(commands.BlobCreator), new(*blobs.FilesystemStorage)),
+	wire.Bind(new(pubcommands.BlobCreator), new(*blobs.FilesystemStorage)),
 )
 
 func newFilesystemStorage(logger logging.Logger, config service.Config) (*blobs.FilesystemStorage, error) {
@@ -40,6 +47,7 @@ var adaptersSet = wire.NewSet(
 	wire.Bind(new(invitesadapters.CurrentTimeProvider), new(*adapters.CurrentTimeProvider)),
 	wire.Bind(new(blobreplication.CurrentTimeProvider), new(*adapters.CurrentTimeProvider)),
 	wire.Bind(new(pubcommands.CurrentTimeProvider), new(*adapters.CurrentTimeProvider)),
+	wire.Bind(new(pubqueries.CurrentTimeProvider), new(*adapters.CurrentTimeProvider)),
 
 	adapters.NewBanListHasher,
 	wire.Bind(new(badger.BanListHasher), new(*adapters.BanListHasher)),
@@ -50,3 +58,40 @@ var adaptersSet = wire.NewSet(
 	invitesadapters.NewInviteDialer,
 	wire.Bind(new(invites.InviteDialer), new(*invitesadapters.InviteDialer)),
 )
+
+var metricsSet = wire.NewSet(
+	pubadapters.NewMetrics,
+	wire.Bind(new(pubcommands.Metrics), new(*pubadapters.Metrics)),
+	wire.Bind(new(pubadapters.HandshakeMetrics), new(*pubadapters.Metrics)),
+	wire.Bind(new(pubadapters.EBTSessionMetrics), new(*pubadapters.Metrics)),
+	wire.Bind(new(pubbadgeradapters.GarbageCollectorMetrics), new(*pubadapters.Metrics)),
+	wire.Bind(new(pubportspubsub.BlobDownloadedMetrics), new(*pubadapters.Metrics)),
+	wire.Bind(new(pubqueries.InviteCounter), new(*pubadapters.Metrics)),
+
+	pubadapters.NewBlobStorageUsage,
+	wire.Bind(new(pubqueries.BlobStorageUsage), new(*pubadapters.BlobStorageUsage)),
+	wire.Bind(new(service.BlobStorageUsage), new(*pubadapters.BlobStorageUsage)),
+
+	pubadapters.NewMetricsExporter,
+	wire.Bind(new(httpport.MetricsWriter), new(*pubadapters.MetricsExporter)),
+	wire.Bind(new(service.MetricsExporter), new(*pubadapters.MetricsExporter)),
+)
+
+var localFeedHeadTrackerSet = wire.NewSet(
+	pubadapters.NewLocalFeedHeadTracker,
+	wire.Bind(new(pubcommands.RemoteFeedHeads), new(*pubadapters.LocalFeedHeadTracker)),
+)
+
+var localFeedHeadTrackingRawMessageHandlerSet = wire.NewSet(
+	newLocalFeedHeadTrackingRawMessageHandler,
+	wire.Bind(new(replication.RawMessageHandler), new(*pubadapters.LocalFeedHeadTrackingRawMessageHandler)),
+)
+
+func newLocalFeedHeadTrackingRawMessageHandler(
+	handler *commands.RawMessageHandler,
+	identifier commands.RawMessageIdentifier,
+	tracker *pubadapters.LocalFeedHeadTracker,
+	logger logging.Logger,
+) *pubadapters.LocalFeedHeadTrackingRawMessageHandler {
+	return pubadapters.NewLocalFeedHeadTrackingRawMessageHandler(handler, identifier, tracker, logger)
+}
@@ -4,11 +4,11 @@ import (
 	"github.com/google/wire"
 	"github.com/planetary-social/scuttlego-pub/service/app"
 	"github.com/planetary-social/scuttlego-pub/service/app/commands"
+	pubqueries "github.com/planetary-social/scuttlego-pub/service/app/queries"
 	ebtadapters "github.com/planetary-social/scuttlego/service/adapters/ebt"
 	scuttlegoapp "github.com/planetary-social/scuttlego/service/app"
 	scuttlegocommands "github.com/planetary-social/scuttlego/service/app/commands"
 	"github.com/planetary-social/scuttlego/service/app/queries"
-	"github.com/planetary-social/scuttlego/service/domain/replication"
 	"github.com/planetary-social/scuttlego/service/ports/network"
 	"github.com/planetary-social/scuttlego/service/ports/pubsub"
 	portsrpc "github.com/planetary-social/scuttlego/service/ports/rpc"
@@ -25,11 +25,20 @@ var commandsSet = wire.NewSet(
 	wire.Struct(new(app.Commands), "*"),
 
 	commands.NewRedeemInviteHandler,
+	commands.NewPublishPendingWelcomesHandler,
 	commands.NewCreateInviteHandler,
+	commands.NewAnnouncePubHandler,
+	commands.NewUpdateProfileHandler,
+	commands.NewDisconnectHandler,
+	commands.NewDisconnectAllHandler,
 )
 
 var queriesSet = wire.NewSet(
 	wire.Struct(new(app.Queries), "*"),
+
+	pubqueries.NewPreferredPeersStatusHandler,
+	pubqueries.NewStatusHandler,
+	pubqueries.NewConnectedPeersHandler,
 )
 
 var scuttlegoApplicationSet = wire.NewSet(
@@ -43,7 +52,6 @@ var scuttlegoCommandsSet = wire.NewSet(
 	scuttlegocommands.NewRedeemInviteHandler,
 	scuttlegocommands.NewFollowHandler,
 	scuttlegocommands.NewConnectHandler,
-	scuttlegocommands.NewDisconnectAllHandler,
 	scuttlegocommands.NewPublishRawHandler,
 	scuttlegocommands.NewPublishRawAsIdentityHandler,
 	scuttlegocommands.NewDownloadBlobHandler,
@@ -65,7 +73,6 @@ var scuttlegoCommandsSet = wire.NewSet(
 	wire.Bind(new(network.EstablishNewConnectionsCommandHandler), new(*scuttlegocommands.EstablishNewConnectionsHandler)),
 
 	scuttlegocommands.NewRawMessageHandler,
-	wire.Bind(new(replication.RawMessageHandler), new(*scuttlegocommands.RawMessageHandler)),
 
 	scuttlegocommands.NewCreateWantsHandler,
 	wire.Bind(new(portsrpc.CreateWantsCommandHandler), new(*scuttlegocommands.CreateWantsHandler)),
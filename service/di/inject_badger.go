@@ -16,7 +16,8 @@ import (
 )
 
 var badgerAdaptersSet = wire.NewSet(
-	scuttlegobadgeradapters.NewGarbageCollector,
+	pubbadgeradapters.NewGarbageCollector,
+	wire.Bind(new(service.BadgerGarbageCollector), new(*pubbadgeradapters.GarbageCollector)),
 )
 
 var badgerNoTxRepositoriesSet = wire.NewSet(
@@ -65,6 +66,9 @@ var badgerRepositoriesSet = wire.NewSet(
 
 	pubbadgeradapters.NewInviteRepository,
 	wire.Bind(new(pubcommands.InviteRepository), new(*pubbadgeradapters.InviteRepository)),
+
+	pubbadgeradapters.NewPendingWelcomeRepository,
+	wire.Bind(new(pubcommands.PendingWelcomeRepository), new(*pubbadgeradapters.PendingWelcomeRepository)),
 )
 
 var badgerTransactionProviderSet = wire.NewSet(
@@ -96,27 +100,27 @@ var badgerTestTransactionProviderSet = wire.NewSet(
 	badgerTestAdaptersFactory,
 )
 
-func noTxTxAdaptersFactory(local identity.Public, conf service.Config, logger logging.Logger) notx.TxAdaptersFactory {
+func noTxTxAdaptersFactory(local identity.Public, currentConfig *service.CurrentConfig, logger logging.Logger) notx.TxAdaptersFactory {
 	return func(tx *badger.Txn) (notx.TxAdapters, error) {
-		return buildBadgerNoTxTxAdapters(tx, local, conf, logger)
+		return buildBadgerNoTxTxAdapters(tx, local, currentConfig.Get(), logger)
 	}
 }
 
-func badgerScuttlegoCommandsAdaptersFactory(config service.Config, local identity.Public, logger logging.Logger) scuttlegobadgeradapters.CommandsAdaptersFactory {
+func badgerScuttlegoCommandsAdaptersFactory(currentConfig *service.CurrentConfig, local identity.Public, logger logging.Logger) scuttlegobadgeradapters.CommandsAdaptersFactory {
 	return func(tx *badger.Txn) (scuttlegocommands.Adapters, error) {
-		return buildBadgerScuttlegoCommandsAdapters(tx, local, config, logger)
+		return buildBadgerScuttlegoCommandsAdapters(tx, local, currentConfig.Get(), logger)
 	}
 }
 
-func badgerScuttlegoQueriesAdaptersFactory(config service.Config, local identity.Public, logger logging.Logger) scuttlegobadgeradapters.QueriesAdaptersFactory {
+func badgerScuttlegoQueriesAdaptersFactory(currentConfig *service.CurrentConfig, local identity.Public, logger logging.Logger) scuttlegobadgeradapters.QueriesAdaptersFactory {
 	return func(tx *badger.Txn) (scuttlegoqueries.Adapters, error) {
-		return buildBadgerScuttlegoQueriesAdapters(tx, local, config, logger)
+		return buildBadgerScuttlegoQueriesAdapters(tx, local, currentConfig.Get(), logger)
 	}
 }
 
-func badgerPubCommandsAdaptersFactory(config service.Config, local identity.Public, logger logging.Logger) CommandsAdaptersFactory {
+func badgerPubCommandsAdaptersFactory(currentConfig *service.CurrentConfig, local identity.Public, logger logging.Logger) CommandsAdaptersFactory {
 	return func(tx *badger.Txn) (pubcommands.Adapters, error) {
-		return buildBadgerPubCommandsAdapters(tx, local, config, logger)
+		return buildBadgerPubCommandsAdapters(tx, local, currentConfig.Get(), logger)
 	}
 }
 
@@ -141,5 +145,6 @@ func newTestTransactionProvider(db *badger.DB, factory TestAdaptersFactory) *Tes
 }
 
 type TestAdapters struct {
-	InviteRepository *pubbadgeradapters.InviteRepository
+	InviteRepository         *pubbadgeradapters.InviteRepository
+	PendingWelcomeRepository *pubbadgeradapters.PendingWelcomeRepository
 }
@@ -3,6 +3,8 @@ package di
 import (
 	"github.com/google/wire"
 	"github.com/planetary-social/scuttlego-pub/service"
+	pubqueries "github.com/planetary-social/scuttlego-pub/service/app/queries"
+	"github.com/planetary-social/scuttlego-pub/service/domain"
 	"github.com/planetary-social/scuttlego/service/domain/feeds/formats"
 	"github.com/planetary-social/scuttlego/service/domain/graph"
 	"github.com/planetary-social/scuttlego/service/domain/transport/boxstream"
@@ -12,6 +14,12 @@ var extractFromConfigSet = wire.NewSet(
 	extractNetworkKeyFromConfig,
 	extractMessageHMACFromConfig,
 	extractHopsFromConfig,
+	extractWelcomeMessageFromConfig,
+	extractPreferredPeersFromCurrentConfig,
+)
+
+var currentConfigSet = wire.NewSet(
+	service.NewCurrentConfig,
 )
 
 func extractNetworkKeyFromConfig(config service.Config) boxstream.NetworkKey {
@@ -25,3 +33,13 @@ func extractMessageHMACFromConfig(config service.Config) formats.MessageHMAC {
 func extractHopsFromConfig(config service.Config) graph.Hops {
 	return config.Hops
 }
+
+func extractWelcomeMessageFromConfig(config service.Config) domain.WelcomeMessageTemplate {
+	return config.WelcomeMessage
+}
+
+func extractPreferredPeersFromCurrentConfig(currentConfig *service.CurrentConfig) pubqueries.CurrentPreferredPeers {
+	return func() []domain.MultiserverAddress {
+		return currentConfig.Get().PreferredPeers
+	}
+}
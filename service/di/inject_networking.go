@@ -2,6 +2,9 @@ package di
 
 import (
 	"github.com/google/wire"
+	"github.com/planetary-social/scuttlego-pub/service"
+	pubadapters "github.com/planetary-social/scuttlego-pub/service/adapters"
+	pubqueries "github.com/planetary-social/scuttlego-pub/service/app/queries"
 	invitesadapters "github.com/planetary-social/scuttlego/service/adapters/invites"
 	"github.com/planetary-social/scuttlego/service/app/commands"
 	"github.com/planetary-social/scuttlego/service/app/queries"
@@ -16,10 +19,17 @@ import (
 
 var networkingSet = wire.NewSet(
 	domaintransport.NewPeerInitializer,
-	wire.Bind(new(portsnetwork.ServerPeerInitializer), new(*domaintransport.PeerInitializer)),
-	wire.Bind(new(network.ClientPeerInitializer), new(*domaintransport.PeerInitializer)),
-	wire.Bind(new(tunnel.ClientPeerInitializer), new(*domaintransport.PeerInitializer)),
-	wire.Bind(new(commands.ServerPeerInitializer), new(*domaintransport.PeerInitializer)),
+
+	pubadapters.NewHandshakeFailureCountingPeerInitializer,
+	wire.Bind(new(pubadapters.PeerInitializer), new(*pubadapters.HandshakeFailureCountingPeerInitializer)),
+
+	pubadapters.NewConnectionTrackingPeerInitializer,
+	wire.Bind(new(portsnetwork.ServerPeerInitializer), new(*pubadapters.ConnectionTrackingPeerInitializer)),
+	wire.Bind(new(network.ClientPeerInitializer), new(*pubadapters.ConnectionTrackingPeerInitializer)),
+	wire.Bind(new(tunnel.ClientPeerInitializer), new(*pubadapters.ConnectionTrackingPeerInitializer)),
+	wire.Bind(new(commands.ServerPeerInitializer), new(*pubadapters.ConnectionTrackingPeerInitializer)),
+	wire.Bind(new(pubqueries.ConnectionTracker), new(*pubadapters.ConnectionTrackingPeerInitializer)),
+	wire.Bind(new(service.PeerConnections), new(*pubadapters.ConnectionTrackingPeerInitializer)),
 
 	rpc.NewConnectionIdGenerator,
 
@@ -1,9 +1,19 @@
 package di
 
 import (
+	"github.com/boreq/errors"
 	"github.com/google/wire"
 	"github.com/planetary-social/scuttlego-pub/service"
+	pubadapters "github.com/planetary-social/scuttlego-pub/service/adapters"
+	"github.com/planetary-social/scuttlego-pub/service/app"
+	adminport "github.com/planetary-social/scuttlego-pub/service/ports/admin"
+	httpport "github.com/planetary-social/scuttlego-pub/service/ports/http"
+	pubportsnetwork "github.com/planetary-social/scuttlego-pub/service/ports/network"
+	pubportspubsub "github.com/planetary-social/scuttlego-pub/service/ports/pubsub"
 	"github.com/planetary-social/scuttlego/logging"
+	"github.com/planetary-social/scuttlego/service/adapters"
+	scuttlegocommands "github.com/planetary-social/scuttlego/service/app/commands"
+	"github.com/planetary-social/scuttlego/service/domain/identity"
 	"github.com/planetary-social/scuttlego/service/domain/network/local"
 	"github.com/planetary-social/scuttlego/service/domain/transport/rpc/mux"
 	portsnetwork "github.com/planetary-social/scuttlego/service/ports/network"
@@ -23,21 +33,95 @@ var portsSet = wire.NewSet(
 	portsrpc.NewMuxClosingHandlers,
 	portsrpc.NewHandlerCreateHistoryStream,
 
+	portspubsub.NewNewPeerSubscriber,
 	portspubsub.NewRequestSubscriber,
 
 	portspubsub.NewRoomAttendantEventSubscriber,
 
-	local.NewDiscoverer,
-	portsnetwork.NewDiscoverer,
+	pubportspubsub.NewBlobDownloadedSubscriber,
+
+	newDiscoverer,
 	portsnetwork.NewConnectionEstablisher,
 
-	newListener,
+	newListeners,
+
+	newHTTPServer,
+	newAdminServer,
 )
 
-func newListener(
+func newListeners(
 	initializer portsnetwork.ServerPeerInitializer,
 	config service.Config,
 	logger logging.Logger,
-) (*portsnetwork.Listener, error) {
-	return portsnetwork.NewListener(initializer, config.ListenAddress, logger)
+) []*pubportsnetwork.Listener {
+	var listeners []*pubportsnetwork.Listener
+	for _, address := range config.ListenAddresses {
+		listeners = append(listeners, pubportsnetwork.NewListener(initializer, address, logger))
+	}
+	return listeners
+}
+
+// newDiscoverer returns nil if local discovery is disabled as creating the
+// discoverer already starts listening for broadcasts.
+func newDiscoverer(
+	public identity.Public,
+	handler portsnetwork.ProcessNewLocalDiscoveryCommandHandler,
+	config service.Config,
+	logger logging.Logger,
+) (*portsnetwork.Discoverer, error) {
+	if !config.LocalDiscovery {
+		return nil, nil
+	}
+
+	discoverer, err := local.NewDiscoverer(public, logger)
+	if err != nil {
+		return nil, errors.Wrap(err, "error creating the local discoverer")
+	}
+
+	if len(config.LocalNetworkInterfaces) > 0 {
+		handler = pubadapters.NewLocalDiscoveryFilter(handler, config.LocalNetworkInterfaces)
+	}
+
+	return portsnetwork.NewDiscoverer(discoverer, handler, logger), nil
+}
+
+// newHTTPServer returns nil if the HTTP listener is disabled.
+func newHTTPServer(
+	config service.Config,
+	readiness httpport.ReadinessChecker,
+	metrics httpport.MetricsWriter,
+	logger logging.Logger,
+) *httpport.Server {
+	if config.HTTPListenAddress == "" {
+		return nil
+	}
+	return httpport.NewServer(config.HTTPListenAddress, readiness, metrics, logger)
+}
+
+func newAdminServer(
+	config service.Config,
+	local identity.Public,
+	application app.Application,
+	addToBanList *scuttlegocommands.AddToBanListHandler,
+	removeFromBanList *scuttlegocommands.RemoveFromBanListHandler,
+	banListHasher *adapters.BanListHasher,
+	connect *scuttlegocommands.ConnectHandler,
+	logger logging.Logger,
+) *adminport.Server {
+	return adminport.NewServer(
+		config.AdminSocketPath(),
+		local,
+		config.ListenAddresses,
+		config.PublicAddress,
+		application.Commands.CreateInvite,
+		addToBanList,
+		removeFromBanList,
+		banListHasher,
+		application.Queries.Status,
+		connect,
+		application.Commands.Disconnect,
+		application.Commands.DisconnectAll,
+		application.Queries.ConnectedPeers,
+		logger,
+	)
 }
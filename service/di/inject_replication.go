@@ -2,6 +2,7 @@ package di
 
 import (
 	"github.com/google/wire"
+	pubadapters "github.com/planetary-social/scuttlego-pub/service/adapters"
 	"github.com/planetary-social/scuttlego/service/app/commands"
 	"github.com/planetary-social/scuttlego/service/app/queries"
 	"github.com/planetary-social/scuttlego/service/domain/replication"
@@ -28,7 +29,8 @@ var replicationSet = wire.NewSet(
 	wire.Bind(new(commands.ForkedFeedTracker), new(*replication.WantedFeedsCache)),
 
 	ebt.NewSessionTracker,
-	wire.Bind(new(ebt.Tracker), new(*ebt.SessionTracker)),
+	pubadapters.NewSessionCountingEBTTracker,
+	wire.Bind(new(ebt.Tracker), new(*pubadapters.SessionCountingEBTTracker)),
 
 	ebt.NewSessionRunner,
 	wire.Bind(new(ebt.Runner), new(*ebt.SessionRunner)),
@@ -4,6 +4,8 @@
 package di
 
 import (
+	"fmt"
+	"os"
 	"path/filepath"
 	"testing"
 
@@ -13,8 +15,12 @@ import (
 	"github.com/planetary-social/scuttlego-pub/internal/fixtures"
 	"github.com/planetary-social/scuttlego-pub/internal/mocks"
 	"github.com/planetary-social/scuttlego-pub/service"
+	pubadapters "github.com/planetary-social/scuttlego-pub/service/adapters"
+	pubbadgeradapters "github.com/planetary-social/scuttlego-pub/service/adapters/badger"
 	"github.com/planetary-social/scuttlego-pub/service/app"
 	"github.com/planetary-social/scuttlego-pub/service/app/commands"
+	pubqueries "github.com/planetary-social/scuttlego-pub/service/app/queries"
+	httpport "github.com/planetary-social/scuttlego-pub/service/ports/http"
 	"github.com/planetary-social/scuttlego/logging"
 	badgeradapters "github.com/planetary-social/scuttlego/service/adapters/badger"
 	"github.com/planetary-social/scuttlego/service/adapters/badger/notx"
@@ -22,7 +28,9 @@ import (
 	scuttlegoqueries "github.com/planetary-social/scuttlego/service/app/queries"
 	"github.com/planetary-social/scuttlego/service/domain"
 	"github.com/planetary-social/scuttlego/service/domain/identity"
+	domainmocks "github.com/planetary-social/scuttlego/service/domain/mocks"
 	"github.com/planetary-social/scuttlego/service/domain/network/local"
+	"github.com/planetary-social/scuttlego/service/domain/rooms"
 	"github.com/planetary-social/scuttlego/service/domain/rooms/tunnel"
 	"github.com/sirupsen/logrus"
 )
@@ -30,23 +38,46 @@ import (
 func BuildService(identity.Private, service.Config) (service.Service, func(), error) {
 	wire.Build(
 		service.NewService,
+		service.NewSupervisor,
+		service.NewReadiness,
+		wire.Bind(new(httpport.ReadinessChecker), new(*service.Readiness)),
 
-		domain.NewPeerManager,
-		wire.Bind(new(scuttlegocommands.PeerManager), new(*domain.PeerManager)),
+		pubbadgeradapters.NewWritabilityChecker,
+		wire.Bind(new(service.DatabaseWritabilityChecker), new(*pubbadgeradapters.WritabilityChecker)),
+
+		newPeerManager,
+		pubadapters.NewReloadablePeerManager,
+		wire.Bind(new(scuttlegocommands.PeerManager), new(*pubadapters.ReloadablePeerManager)),
+		wire.Bind(new(commands.PeerManager), new(*pubadapters.ReloadablePeerManager)),
+		wire.Bind(new(pubqueries.PeerManager), new(*pubadapters.ReloadablePeerManager)),
+		wire.Bind(new(scuttlegoqueries.PeerManager), new(*pubadapters.ReloadablePeerManager)),
+		wire.Bind(new(pubadapters.MetricsPeerManager), new(*pubadapters.ReloadablePeerManager)),
+
+		wire.Bind(new(pubqueries.StatusQueryHandler), new(*scuttlegoqueries.StatusHandler)),
+		wire.Bind(new(pubadapters.MetricsStatusQueryHandler), new(*scuttlegoqueries.StatusHandler)),
 
 		newBadger,
 
-		newAdvertiser,
+		newAdvertisers,
 		privateIdentityToPublicIdentity,
 
 		scuttlegocommands.NewMessageBuffer,
 
+		rooms.NewScanner,
+		wire.Bind(new(scuttlegocommands.RoomScanner), new(*rooms.Scanner)),
+
+		rooms.NewPeerRPCAdapter,
+		wire.Bind(new(rooms.MetadataGetter), new(*rooms.PeerRPCAdapter)),
+		wire.Bind(new(rooms.AttendantsGetter), new(*rooms.PeerRPCAdapter)),
+
 		tunnel.NewDialer,
 		wire.Bind(new(domain.RoomDialer), new(*tunnel.Dialer)),
 
 		newContextLogger,
+		newLogrusLogger,
 		newLoggingSystem,
-		wire.Bind(new(logging.LoggingSystem), new(logging.LogrusLoggingSystem)),
+		wire.Bind(new(logging.LoggingSystem), new(*pubadapters.LoggingSystem)),
+		wire.Bind(new(service.LogLevelSetter), new(*pubadapters.LoggingSystem)),
 
 		newPeerManagerConfig,
 
@@ -67,6 +98,10 @@ func BuildService(identity.Private, service.Config) (service.Service, func(), er
 		networkingSet,
 		migrationsSet,
 		contentSet,
+		localFeedHeadTrackerSet,
+		localFeedHeadTrackingRawMessageHandlerSet,
+		currentConfigSet,
+		metricsSet,
 	)
 	return service.Service{}, nil, nil
 }
@@ -76,14 +111,23 @@ type TestApplication struct {
 
 	SocialGraphRepository *mocks.SocialGraphRepositoryMock
 	InviteRepository      *mocks.InviteRespositoryMock
+	PendingWelcomes       *mocks.PendingWelcomeRepositoryMock
 	FeedRepository        *mocks.FeedRepositoryMock
 	Marshaler             *mocks.MarshalerMock
 	FeedFormat            *mocks.FeedFormatMock
 	LocalIdentity         identity.Private
 	CurrentTimeProvider   *mocks.CurrentTimeProviderMock
+	BlobCreator           *mocks.BlobCreatorMock
+	RemoteFeedHeads       *mocks.RemoteFeedHeadsMock
+	Metrics               *mocks.MetricsMock
+	PeerManager           *domainmocks.PeerManagerMock
 }
 
-func BuildTestApplication(testing.TB) (TestApplication, error) {
+func BuildTestApplication(tb testing.TB) (TestApplication, error) {
+	return BuildTestApplicationWithConfig(tb, service.NewDefaultConfig())
+}
+
+func BuildTestApplicationWithConfig(testing.TB, service.Config) (TestApplication, error) {
 	wire.Build(
 		wire.Struct(new(TestApplication), "*"),
 
@@ -100,6 +144,9 @@ func BuildTestApplication(testing.TB) (TestApplication, error) {
 		mocks.NewInviteRespositoryMock,
 		wire.Bind(new(commands.InviteRepository), new(*mocks.InviteRespositoryMock)),
 
+		mocks.NewPendingWelcomeRepositoryMock,
+		wire.Bind(new(commands.PendingWelcomeRepository), new(*mocks.PendingWelcomeRepositoryMock)),
+
 		mocks.NewFeedRepositoryMock,
 		wire.Bind(new(commands.FeedRepository), new(*mocks.FeedRepositoryMock)),
 
@@ -109,9 +156,22 @@ func BuildTestApplication(testing.TB) (TestApplication, error) {
 		mocks.NewMarshalerMock,
 		wire.Bind(new(commands.Marshaler), new(*mocks.MarshalerMock)),
 
+		mocks.NewBlobCreatorMock,
+		wire.Bind(new(commands.BlobCreator), new(*mocks.BlobCreatorMock)),
+
+		mocks.NewRemoteFeedHeadsMock,
+		wire.Bind(new(commands.RemoteFeedHeads), new(*mocks.RemoteFeedHeadsMock)),
+
 		mocks.NewFeedFormatMock,
 
+		mocks.NewMetricsMock,
+		wire.Bind(new(commands.Metrics), new(*mocks.MetricsMock)),
+
+		domainmocks.NewPeerManagerMock,
+		wire.Bind(new(commands.PeerManager), new(*domainmocks.PeerManagerMock)),
+
 		fixtures.SomePrivateIdentity,
+		extractWelcomeMessageFromConfig,
 	)
 
 	return TestApplication{}, nil
@@ -132,6 +192,52 @@ func BuildBadgerTestAdapters(testing.TB) (BadgerTestAdapters, error) {
 	return BadgerTestAdapters{}, nil
 }
 
+type BadgerTestApplication struct {
+	Commands app.Commands
+
+	TransactionProvider  *TestTransactionProvider
+	LocalIdentity        identity.Private
+	LocalFeedHeadTracker *pubadapters.LocalFeedHeadTracker
+}
+
+func BuildBadgerTestApplication(testing.TB) (BadgerTestApplication, error) {
+	wire.Build(
+		wire.Struct(new(BadgerTestApplication), "*"),
+
+		commandsSet,
+
+		newCommandsTransactionProvider,
+		wire.Bind(new(commands.TransactionProvider), new(*CommandsTransactionProvider)),
+		badgerPubCommandsAdaptersFactory,
+		service.NewCurrentConfig,
+
+		badgerTestTransactionProviderSet,
+		fixtures.Badger,
+
+		formatsSet,
+		adaptersSet,
+
+		mocks.NewBlobCreatorMock,
+		wire.Bind(new(commands.BlobCreator), new(*mocks.BlobCreatorMock)),
+
+		mocks.NewMetricsMock,
+		wire.Bind(new(commands.Metrics), new(*mocks.MetricsMock)),
+
+		domainmocks.NewPeerManagerMock,
+		wire.Bind(new(commands.PeerManager), new(*domainmocks.PeerManagerMock)),
+
+		localFeedHeadTrackerSet,
+
+		privateIdentityToPublicIdentity,
+		fixtures.SomePrivateIdentity,
+		service.NewDefaultConfig,
+		extractWelcomeMessageFromConfig,
+		newDevNullLogger,
+	)
+
+	return BadgerTestApplication{}, nil
+}
+
 func buildBadgerNoTxTxAdapters(*badger.Txn, identity.Public, service.Config, logging.Logger) (notx.TxAdapters, error) {
 	wire.Build(
 		wire.Struct(new(notx.TxAdapters), "*"),
@@ -198,16 +304,38 @@ func buildBadgerTestAdapters(*badger.Txn) (TestAdapters, error) {
 	return TestAdapters{}, nil
 }
 
-func newAdvertiser(l identity.Public, config service.Config) (*local.Advertiser, error) {
-	return local.NewAdvertiser(l, config.ListenAddress)
+func newAdvertisers(l identity.Public, config service.Config) ([]*local.Advertiser, error) {
+	if !config.LocalAdvertising {
+		return nil, nil
+	}
+
+	addresses, err := pubadapters.LocalAdvertisementAddresses(config.ListenAddresses, config.LocalNetworkInterfaces)
+	if err != nil {
+		return nil, errors.Wrap(err, "error determining the advertised addresses")
+	}
+
+	var advertisers []*local.Advertiser
+	for _, address := range addresses {
+		advertiser, err := local.NewAdvertiser(l, address)
+		if err != nil {
+			return nil, errors.Wrapf(err, "error creating an advertiser for address '%s'", address)
+		}
+		advertisers = append(advertisers, advertiser)
+	}
+	return advertisers, nil
 }
 
 func newBadger(system logging.LoggingSystem, logger logging.Logger, config service.Config) (*badger.DB, func(), error) {
 	badgerDirectory := filepath.Join(config.DataDirectory, "badger")
 
-	options := badger.DefaultOptions(badgerDirectory)
-	options.Logger = badgeradapters.NewLogger(system, badgeradapters.LoggerLevelWarning)
-	options.SyncWrites = true
+	options, err := pubbadgeradapters.NewOptions(badgerDirectory, config)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "error creating the options")
+	}
+
+	// all messages are passed on as they are filtered by the logging system
+	// based on the log level of the badger component
+	options.Logger = badgeradapters.NewLogger(pubadapters.NewNamedLoggingSystem(system, "badger"), badgeradapters.LoggerLevelDebug)
 
 	db, err := badger.Open(options)
 	if err != nil {
@@ -230,14 +358,58 @@ func newContextLogger(loggingSystem logging.LoggingSystem) logging.Logger {
 	return logging.NewContextLogger(loggingSystem, "scuttlego")
 }
 
-func newLoggingSystem() logging.LogrusLoggingSystem {
+func newDevNullLogger() logging.Logger {
+	return logging.NewDevNullLogger()
+}
+
+func newLogrusLogger(config service.Config) (*logrus.Logger, func(), error) {
 	logger := logrus.New()
 
-	return logging.NewLogrusLoggingSystem(logger)
+	if config.LogFormat == service.LogFormatJSON {
+		logger.SetFormatter(&logrus.JSONFormatter{})
+	}
+
+	if config.LogFile == "" {
+		return logger, func() {}, nil
+	}
+
+	const megabyte = 1024 * 1024
+	f, err := pubadapters.NewRotatingFile(config.LogFile, int64(config.LogFileMaxSizeMegabytes)*megabyte, config.LogFileMaxBackups)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "error opening the log file")
+	}
+
+	logger.SetOutput(f)
+
+	return logger, func() {
+		if err := f.Close(); err != nil {
+			fmt.Fprintf(os.Stderr, "error closing the log file: %s\n", err)
+		}
+	}, nil
+}
+
+func newLoggingSystem(logger *logrus.Logger, config service.Config) (*pubadapters.LoggingSystem, error) {
+	return pubadapters.NewLoggingSystem(logger, config.LogLevel, config.LogComponentLevels)
+}
+
+// newPeerManager creates a peer manager without any preferred pubs as they
+// are provided by pubadapters.ReloadablePeerManager.
+func newPeerManager(dialer domain.Dialer, roomDialer domain.RoomDialer, logger logging.Logger) *domain.PeerManager {
+	return domain.NewPeerManager(domain.PeerManagerConfig{}, dialer, roomDialer, logger)
 }
 
-func newPeerManagerConfig() domain.PeerManagerConfig {
-	return domain.PeerManagerConfig{
-		PreferredPubs: nil,
+func newPeerManagerConfig(currentConfig *service.CurrentConfig) pubadapters.CurrentPeerManagerConfig {
+	return func() domain.PeerManagerConfig {
+		var preferredPubs []domain.Pub
+		for _, preferredPeer := range currentConfig.Get().PreferredPeers {
+			preferredPubs = append(preferredPubs, domain.Pub{
+				Identity: preferredPeer.Identity(),
+				Address:  preferredPeer.Address(),
+			})
+		}
+
+		return domain.PeerManagerConfig{
+			PreferredPubs: preferredPubs,
+		}
 	}
 }
@@ -7,6 +7,8 @@
 package di
 
 import (
+	"fmt"
+	"os"
 	"path/filepath"
 	"testing"
 
@@ -15,10 +17,13 @@ import (
 	"github.com/planetary-social/scuttlego-pub/internal/fixtures"
 	"github.com/planetary-social/scuttlego-pub/internal/mocks"
 	"github.com/planetary-social/scuttlego-pub/service"
+	adapters2 "github.com/planetary-social/scuttlego-pub/service/adapters"
 	badger3 "github.com/planetary-social/scuttlego-pub/service/adapters/badger"
 	"github.com/planetary-social/scuttlego-pub/service/app"
 	"github.com/planetary-social/scuttlego-pub/service/app/commands"
+	queries2 "github.com/planetary-social/scuttlego-pub/service/app/queries"
 	"github.com/planetary-social/scuttlego-pub/service/domain/messages/transport"
+	pubsub3 "github.com/planetary-social/scuttlego-pub/service/ports/pubsub"
 	"github.com/planetary-social/scuttlego/logging"
 	migrations2 "github.com/planetary-social/scuttlego/migrations"
 	"github.com/planetary-social/scuttlego/service/adapters"
@@ -36,11 +41,13 @@ import (
 	transport2 "github.com/planetary-social/scuttlego/service/domain/feeds/content/transport"
 	"github.com/planetary-social/scuttlego/service/domain/feeds/formats"
 	"github.com/planetary-social/scuttlego/service/domain/identity"
+	mocks2 "github.com/planetary-social/scuttlego/service/domain/mocks"
 	"github.com/planetary-social/scuttlego/service/domain/network"
 	"github.com/planetary-social/scuttlego/service/domain/network/local"
 	replication2 "github.com/planetary-social/scuttlego/service/domain/replication"
 	"github.com/planetary-social/scuttlego/service/domain/replication/ebt"
 	"github.com/planetary-social/scuttlego/service/domain/replication/gossip"
+	"github.com/planetary-social/scuttlego/service/domain/rooms"
 	"github.com/planetary-social/scuttlego/service/domain/rooms/tunnel"
 	transport3 "github.com/planetary-social/scuttlego/service/domain/transport"
 	"github.com/planetary-social/scuttlego/service/domain/transport/boxstream"
@@ -55,38 +62,58 @@ import (
 // Injectors from wire.go:
 
 func BuildService(private identity.Private, config service.Config) (service.Service, func(), error) {
-	logrusLoggingSystem := newLoggingSystem()
-	logger := newContextLogger(logrusLoggingSystem)
-	db, cleanup, err := newBadger(logrusLoggingSystem, logger, config)
+	logrusLogger, cleanup, err := newLogrusLogger(config)
 	if err != nil {
 		return service.Service{}, nil, err
 	}
+	loggingSystem, err := newLoggingSystem(logrusLogger, config)
+	if err != nil {
+		cleanup()
+		return service.Service{}, nil, err
+	}
+	logger := newContextLogger(loggingSystem)
+	db, cleanup2, err := newBadger(loggingSystem, logger, config)
+	if err != nil {
+		cleanup()
+		return service.Service{}, nil, err
+	}
+	currentConfig := service.NewCurrentConfig(config)
 	public := privateIdentityToPublicIdentity(private)
-	adaptersFactory := badgerPubCommandsAdaptersFactory(config, public, logger)
+	adaptersFactory := badgerPubCommandsAdaptersFactory(currentConfig, public, logger)
 	transactionProvider := newCommandsTransactionProvider(db, adaptersFactory)
-	createInviteHandler := commands.NewCreateInviteHandler(transactionProvider)
+	metrics := adapters2.NewMetrics()
+	createInviteHandler := commands.NewCreateInviteHandler(transactionProvider, metrics)
 	currentTimeProvider := adapters.NewCurrentTimeProvider()
 	messageContentMappings := transport.Mappings()
 	marshaler, err := transport2.NewMarshaler(messageContentMappings, logger)
 	if err != nil {
+		cleanup2()
 		cleanup()
 		return service.Service{}, nil, err
 	}
-	redeemInviteHandler := commands.NewRedeemInviteHandler(transactionProvider, currentTimeProvider, marshaler, private)
-	appCommands := app.Commands{
-		CreateInvite: createInviteHandler,
-		RedeemInvite: redeemInviteHandler,
+	localFeedHeadTracker, err := adapters2.NewLocalFeedHeadTracker(public, logger)
+	if err != nil {
+		cleanup2()
+		cleanup()
+		return service.Service{}, nil, err
 	}
-	appQueries := app.Queries{}
-	application := app.Application{
-		Commands: appCommands,
-		Queries:  appQueries,
+	welcomeMessageTemplate := extractWelcomeMessageFromConfig(config)
+	redeemInviteHandler := commands.NewRedeemInviteHandler(transactionProvider, currentTimeProvider, marshaler, private, localFeedHeadTracker, welcomeMessageTemplate, metrics)
+	publishPendingWelcomesHandler := commands.NewPublishPendingWelcomesHandler(transactionProvider, currentTimeProvider, marshaler, private, localFeedHeadTracker, welcomeMessageTemplate)
+	announcePubHandler := commands.NewAnnouncePubHandler(transactionProvider, currentTimeProvider, marshaler, private, localFeedHeadTracker)
+	filesystemStorage, err := newFilesystemStorage(logger, config)
+	if err != nil {
+		cleanup2()
+		cleanup()
+		return service.Service{}, nil, err
 	}
+	updateProfileHandler := commands.NewUpdateProfileHandler(transactionProvider, filesystemStorage, currentTimeProvider, marshaler, private, localFeedHeadTracker)
 	badgerStorage := migrations.NewBadgerStorage(db)
 	runner := migrations2.NewRunner(badgerStorage, logger)
 	v := newMigrationsList()
 	migrationsMigrations, err := migrations2.NewMigrations(v)
 	if err != nil {
+		cleanup2()
 		cleanup()
 		return service.Service{}, nil, err
 	}
@@ -94,6 +121,7 @@ func BuildService(private identity.Private, config service.Config) (service.Serv
 	networkKey := extractNetworkKeyFromConfig(config)
 	handshaker, err := boxstream.NewHandshaker(private, networkKey, currentTimeProvider)
 	if err != nil {
+		cleanup2()
 		cleanup()
 		return service.Service{}, nil, err
 	}
@@ -101,45 +129,55 @@ func BuildService(private identity.Private, config service.Config) (service.Serv
 	connectionIdGenerator := rpc.NewConnectionIdGenerator()
 	newPeerPubSub := pubsub.NewNewPeerPubSub()
 	peerInitializer := transport3.NewPeerInitializer(handshaker, requestPubSub, connectionIdGenerator, newPeerPubSub, logger)
-	listener, err := newListener(peerInitializer, config, logger)
+	handshakeFailureCountingPeerInitializer := adapters2.NewHandshakeFailureCountingPeerInitializer(peerInitializer, metrics)
+	connectionTrackingPeerInitializer := adapters2.NewConnectionTrackingPeerInitializer(handshakeFailureCountingPeerInitializer)
+	listeners := newListeners(connectionTrackingPeerInitializer, config, logger)
+	dialer, err := network.NewDialer(connectionTrackingPeerInitializer, logger)
 	if err != nil {
+		cleanup2()
 		cleanup()
 		return service.Service{}, nil, err
 	}
-	discoverer, err := local.NewDiscoverer(public, logger)
-	if err != nil {
-		cleanup()
-		return service.Service{}, nil, err
+	tunnelDialer := tunnel.NewDialer(connectionTrackingPeerInitializer)
+	peerManager := newPeerManager(dialer, tunnelDialer, logger)
+	currentPeerManagerConfig := newPeerManagerConfig(currentConfig)
+	reloadablePeerManager := adapters2.NewReloadablePeerManager(peerManager, currentPeerManagerConfig)
+	disconnectHandler := commands.NewDisconnectHandler(reloadablePeerManager)
+	disconnectAllHandler := commands.NewDisconnectAllHandler(reloadablePeerManager)
+	appCommands := app.Commands{
+		CreateInvite:           createInviteHandler,
+		RedeemInvite:           redeemInviteHandler,
+		PublishPendingWelcomes: publishPendingWelcomesHandler,
+		AnnouncePub:            announcePubHandler,
+		UpdateProfile:          updateProfileHandler,
+		Disconnect:             disconnectHandler,
+		DisconnectAll:          disconnectAllHandler,
 	}
-	peerManagerConfig := newPeerManagerConfig()
-	dialer, err := network.NewDialer(peerInitializer, logger)
+	currentPreferredPeers := extractPreferredPeersFromCurrentConfig(currentConfig)
+	preferredPeersStatusHandler := queries2.NewPreferredPeersStatusHandler(currentPreferredPeers, reloadablePeerManager)
+	processNewLocalDiscoveryHandler := commands2.NewProcessNewLocalDiscoveryHandler(reloadablePeerManager)
+	networkDiscoverer, err := newDiscoverer(public, processNewLocalDiscoveryHandler, config, logger)
 	if err != nil {
+		cleanup2()
 		cleanup()
 		return service.Service{}, nil, err
 	}
-	tunnelDialer := tunnel.NewDialer(peerInitializer)
-	peerManager := domain.NewPeerManager(peerManagerConfig, dialer, tunnelDialer, logger)
-	processNewLocalDiscoveryHandler := commands2.NewProcessNewLocalDiscoveryHandler(peerManager)
-	networkDiscoverer := network2.NewDiscoverer(discoverer, processNewLocalDiscoveryHandler, logger)
-	establishNewConnectionsHandler := commands2.NewEstablishNewConnectionsHandler(peerManager)
+	establishNewConnectionsHandler := commands2.NewEstablishNewConnectionsHandler(reloadablePeerManager)
 	connectionEstablisher := network2.NewConnectionEstablisher(establishNewConnectionsHandler, logger)
-	filesystemStorage, err := newFilesystemStorage(logger, config)
-	if err != nil {
-		cleanup()
-		return service.Service{}, nil, err
-	}
 	getBlobHandler, err := queries.NewGetBlobHandler(filesystemStorage)
 	if err != nil {
+		cleanup2()
 		cleanup()
 		return service.Service{}, nil, err
 	}
 	handlerBlobsGet := rpc2.NewHandlerBlobsGet(getBlobHandler)
-	txAdaptersFactory := noTxTxAdaptersFactory(public, config, logger)
+	txAdaptersFactory := noTxTxAdaptersFactory(public, currentConfig, logger)
 	txAdaptersFactoryTransactionProvider := notx.NewTxAdaptersFactoryTransactionProvider(db, txAdaptersFactory)
 	noTxBlobWantListRepository := notx.NewNoTxBlobWantListRepository(txAdaptersFactoryTransactionProvider, logger)
 	noTxBlobsRepository := notx.NewNoTxBlobsRepository(txAdaptersFactoryTransactionProvider)
 	storageBlobsThatShouldBePushedProvider, err := replication.NewStorageBlobsThatShouldBePushedProvider(noTxBlobsRepository, public, currentTimeProvider)
 	if err != nil {
+		cleanup2()
 		cleanup()
 		return service.Service{}, nil, err
 	}
@@ -151,88 +189,146 @@ func BuildService(private identity.Private, config service.Config) (service.Serv
 	createWantsHandler := commands2.NewCreateWantsHandler(manager)
 	handlerBlobsCreateWants := rpc2.NewHandlerBlobsCreateWants(createWantsHandler)
 	sessionTracker := ebt.NewSessionTracker()
+	sessionCountingEBTTracker := adapters2.NewSessionCountingEBTTracker(sessionTracker, metrics)
 	scanner := blobs.NewScanner()
 	parser := content.NewParser(marshaler, scanner)
 	messageHMAC := extractMessageHMACFromConfig(config)
 	scuttlebutt := formats.NewScuttlebutt(parser, messageHMAC)
 	v2 := newFormats(scuttlebutt)
 	rawMessageIdentifier := formats.NewRawMessageIdentifier(v2)
-	commandsAdaptersFactory := badgerScuttlegoCommandsAdaptersFactory(config, public, logger)
+	commandsAdaptersFactory := badgerScuttlegoCommandsAdaptersFactory(currentConfig, public, logger)
 	commandsTransactionProvider := badger.NewCommandsTransactionProvider(db, commandsAdaptersFactory)
-	queriesAdaptersFactory := badgerScuttlegoQueriesAdaptersFactory(config, public, logger)
+	queriesAdaptersFactory := badgerScuttlegoQueriesAdaptersFactory(currentConfig, public, logger)
 	queriesTransactionProvider := badger.NewQueriesTransactionProvider(db, queriesAdaptersFactory)
 	wantedFeedsProvider := queries.NewWantedFeedsProvider(queriesTransactionProvider)
 	wantedFeedsCache := replication2.NewWantedFeedsCache(wantedFeedsProvider)
 	messageBuffer := commands2.NewMessageBuffer(commandsTransactionProvider, rawMessageIdentifier, wantedFeedsCache, logger)
 	rawMessageHandler := commands2.NewRawMessageHandler(rawMessageIdentifier, messageBuffer, logger)
+	localFeedHeadTrackingRawMessageHandler := newLocalFeedHeadTrackingRawMessageHandler(rawMessageHandler, rawMessageIdentifier, localFeedHeadTracker, logger)
 	messagePubSub := pubsub.NewMessagePubSub()
 	createHistoryStreamHandler := queries.NewCreateHistoryStreamHandler(queriesTransactionProvider, messagePubSub, logger)
 	createHistoryStreamHandlerAdapter := ebt2.NewCreateHistoryStreamHandlerAdapter(createHistoryStreamHandler)
-	sessionRunner := ebt.NewSessionRunner(logger, rawMessageHandler, wantedFeedsCache, createHistoryStreamHandlerAdapter)
+	sessionRunner := ebt.NewSessionRunner(logger, localFeedHeadTrackingRawMessageHandler, wantedFeedsCache, createHistoryStreamHandlerAdapter)
 	gossipManager := gossip.NewManager(logger, wantedFeedsCache)
-	gossipReplicator, err := gossip.NewGossipReplicator(gossipManager, rawMessageHandler, logger)
+	gossipReplicator, err := gossip.NewGossipReplicator(gossipManager, localFeedHeadTrackingRawMessageHandler, logger)
 	if err != nil {
+		cleanup2()
 		cleanup()
 		return service.Service{}, nil, err
 	}
-	replicator := ebt.NewReplicator(sessionTracker, sessionRunner, gossipReplicator, logger)
+	replicator := ebt.NewReplicator(sessionCountingEBTTracker, sessionRunner, gossipReplicator, logger)
+	negotiator := replication2.NewNegotiator(logger, replicator, gossipReplicator)
+	replicationReplicator := replication.NewReplicator(manager)
+	peerRPCAdapter := rooms.NewPeerRPCAdapter(logger)
+	roomAttendantEventPubSub := pubsub.NewRoomAttendantEventPubSub()
+	roomsScanner := rooms.NewScanner(peerRPCAdapter, peerRPCAdapter, roomAttendantEventPubSub, logger)
+	acceptNewPeerHandler := commands2.NewAcceptNewPeerHandler(reloadablePeerManager, negotiator, replicationReplicator, roomsScanner, logger)
+	newPeerSubscriber := pubsub2.NewNewPeerSubscriber(newPeerPubSub, acceptNewPeerHandler, logger)
 	handleIncomingEbtReplicateHandler := commands2.NewHandleIncomingEbtReplicateHandler(replicator)
 	handlerEbtReplicate := rpc2.NewHandlerEbtReplicate(handleIncomingEbtReplicateHandler)
-	acceptTunnelConnectHandler := commands2.NewAcceptTunnelConnectHandler(public, peerInitializer)
+	acceptTunnelConnectHandler := commands2.NewAcceptTunnelConnectHandler(public, connectionTrackingPeerInitializer)
 	handlerTunnelConnect := rpc2.NewHandlerTunnelConnect(acceptTunnelConnectHandler)
 	v3 := rpc2.NewMuxHandlers(handlerBlobsGet, handlerBlobsCreateWants, handlerEbtReplicate, handlerTunnelConnect)
 	handlerCreateHistoryStream := rpc2.NewHandlerCreateHistoryStream(createHistoryStreamHandler, logger)
 	v4 := rpc2.NewMuxClosingHandlers(handlerCreateHistoryStream)
 	muxMux, err := mux.NewMux(logger, v3, v4)
 	if err != nil {
+		cleanup2()
 		cleanup()
 		return service.Service{}, nil, err
 	}
 	requestSubscriber := pubsub2.NewRequestSubscriber(requestPubSub, muxMux)
-	roomAttendantEventPubSub := pubsub.NewRoomAttendantEventPubSub()
-	processRoomAttendantEventHandler := commands2.NewProcessRoomAttendantEventHandler(peerManager)
+	processRoomAttendantEventHandler := commands2.NewProcessRoomAttendantEventHandler(reloadablePeerManager)
 	roomAttendantEventSubscriber := pubsub2.NewRoomAttendantEventSubscriber(roomAttendantEventPubSub, processRoomAttendantEventHandler, logger)
-	advertiser, err := newAdvertiser(public, config)
+	blobDownloadedSubscriber := pubsub3.NewBlobDownloadedSubscriber(blobDownloadedPubSub, metrics)
+	advertisers, err := newAdvertisers(public, config)
 	if err != nil {
+		cleanup2()
 		cleanup()
 		return service.Service{}, nil, err
 	}
-	garbageCollector := badger.NewGarbageCollector(db, logger)
-	serviceService := service.NewService(application, runMigrationsHandler, listener, networkDiscoverer, connectionEstablisher, requestSubscriber, roomAttendantEventSubscriber, advertiser, messageBuffer, createHistoryStreamHandler, garbageCollector)
+	garbageCollector := badger3.NewGarbageCollector(db, metrics, logger)
+	supervisor := service.NewSupervisor(logger)
+	writabilityChecker := badger3.NewWritabilityChecker(db)
+	readiness := service.NewReadiness(supervisor, writabilityChecker)
+	statusHandler := queries.NewStatusHandler(queriesTransactionProvider, reloadablePeerManager)
+	blobStorageUsage := adapters2.NewBlobStorageUsage(config)
+	queriesStatusHandler := queries2.NewStatusHandler(statusHandler, connectionTrackingPeerInitializer, blobStorageUsage, metrics, currentTimeProvider)
+	connectedPeersHandler := queries2.NewConnectedPeersHandler(reloadablePeerManager, connectionTrackingPeerInitializer)
+	appQueries := app.Queries{
+		PreferredPeersStatus: preferredPeersStatusHandler,
+		Status:               queriesStatusHandler,
+		ConnectedPeers:       connectedPeersHandler,
+	}
+	application := app.Application{
+		Commands: appCommands,
+		Queries:  appQueries,
+	}
+	metricsExporter := adapters2.NewMetricsExporter(metrics, reloadablePeerManager, statusHandler, db, supervisor, blobStorageUsage)
+	server := newHTTPServer(config, readiness, metricsExporter, logger)
+	addToBanListHandler := commands2.NewAddToBanListHandler(commandsTransactionProvider)
+	removeFromBanListHandler := commands2.NewRemoveFromBanListHandler(commandsTransactionProvider)
+	banListHasher := adapters.NewBanListHasher()
+	connectHandler := commands2.NewConnectHandler(reloadablePeerManager, logger)
+	adminServer := newAdminServer(config, public, application, addToBanListHandler, removeFromBanListHandler, banListHasher, connectHandler, logger)
+	serviceService := service.NewService(application, config, currentConfig, loggingSystem, logger, supervisor, readiness, server, adminServer, connectionTrackingPeerInitializer, runMigrationsHandler, listeners, networkDiscoverer, connectionEstablisher, newPeerSubscriber, requestSubscriber, roomAttendantEventSubscriber, blobDownloadedSubscriber, advertisers, messageBuffer, createHistoryStreamHandler, garbageCollector, metricsExporter, blobStorageUsage)
 	return serviceService, func() {
+		cleanup2()
 		cleanup()
 	}, nil
 }
 
-func BuildTestApplication(tb testing.TB) (TestApplication, error) {
+func BuildTestApplicationWithConfig(tb testing.TB, config service.Config) (TestApplication, error) {
 	socialGraphRepositoryMock := mocks.NewSocialGraphRepositoryMock()
 	inviteRespositoryMock := mocks.NewInviteRespositoryMock()
 	feedFormatMock := mocks.NewFeedFormatMock()
 	feedRepositoryMock := mocks.NewFeedRepositoryMock(feedFormatMock)
+	pendingWelcomeRepositoryMock := mocks.NewPendingWelcomeRepositoryMock()
 	commandsAdapters := commands.Adapters{
-		SocialGraph: socialGraphRepositoryMock,
-		Invite:      inviteRespositoryMock,
-		Feed:        feedRepositoryMock,
+		SocialGraph:    socialGraphRepositoryMock,
+		Invite:         inviteRespositoryMock,
+		Feed:           feedRepositoryMock,
+		PendingWelcome: pendingWelcomeRepositoryMock,
 	}
 	mockCommandsTransactionProvider := mocks.NewMockCommandsTransactionProvider(commandsAdapters)
-	createInviteHandler := commands.NewCreateInviteHandler(mockCommandsTransactionProvider)
+	metricsMock := mocks.NewMetricsMock()
+	createInviteHandler := commands.NewCreateInviteHandler(mockCommandsTransactionProvider, metricsMock)
 	currentTimeProviderMock := mocks.NewCurrentTimeProviderMock()
 	marshalerMock := mocks.NewMarshalerMock()
 	private := fixtures.SomePrivateIdentity()
-	redeemInviteHandler := commands.NewRedeemInviteHandler(mockCommandsTransactionProvider, currentTimeProviderMock, marshalerMock, private)
+	remoteFeedHeadsMock := mocks.NewRemoteFeedHeadsMock()
+	welcomeMessageTemplate := extractWelcomeMessageFromConfig(config)
+	redeemInviteHandler := commands.NewRedeemInviteHandler(mockCommandsTransactionProvider, currentTimeProviderMock, marshalerMock, private, remoteFeedHeadsMock, welcomeMessageTemplate, metricsMock)
+	publishPendingWelcomesHandler := commands.NewPublishPendingWelcomesHandler(mockCommandsTransactionProvider, currentTimeProviderMock, marshalerMock, private, remoteFeedHeadsMock, welcomeMessageTemplate)
+	announcePubHandler := commands.NewAnnouncePubHandler(mockCommandsTransactionProvider, currentTimeProviderMock, marshalerMock, private, remoteFeedHeadsMock)
+	blobCreatorMock := mocks.NewBlobCreatorMock()
+	updateProfileHandler := commands.NewUpdateProfileHandler(mockCommandsTransactionProvider, blobCreatorMock, currentTimeProviderMock, marshalerMock, private, remoteFeedHeadsMock)
+	peerManagerMock := mocks2.NewPeerManagerMock()
+	disconnectHandler := commands.NewDisconnectHandler(peerManagerMock)
+	disconnectAllHandler := commands.NewDisconnectAllHandler(peerManagerMock)
 	appCommands := app.Commands{
-		CreateInvite: createInviteHandler,
-		RedeemInvite: redeemInviteHandler,
+		CreateInvite:           createInviteHandler,
+		RedeemInvite:           redeemInviteHandler,
+		PublishPendingWelcomes: publishPendingWelcomesHandler,
+		AnnouncePub:            announcePubHandler,
+		UpdateProfile:          updateProfileHandler,
+		Disconnect:             disconnectHandler,
+		DisconnectAll:          disconnectAllHandler,
 	}
 	testApplication := TestApplication{
 		Commands:              appCommands,
 		SocialGraphRepository: socialGraphRepositoryMock,
 		InviteRepository:      inviteRespositoryMock,
+		PendingWelcomes:       pendingWelcomeRepositoryMock,
 		FeedRepository:        feedRepositoryMock,
 		Marshaler:             marshalerMock,
 		FeedFormat:            feedFormatMock,
 		LocalIdentity:         private,
 		CurrentTimeProvider:   currentTimeProviderMock,
+		BlobCreator:           blobCreatorMock,
+		RemoteFeedHeads:       remoteFeedHeadsMock,
+		Metrics:               metricsMock,
+		PeerManager:           peerManagerMock,
 	}
 	return testApplication, nil
 }
@@ -247,6 +343,56 @@ func BuildBadgerTestAdapters(tb testing.TB) (BadgerTestAdapters, error) {
 	return badgerTestAdapters, nil
 }
 
+func BuildBadgerTestApplication(tb testing.TB) (BadgerTestApplication, error) {
+	db := fixtures.Badger(tb)
+	config := service.NewDefaultConfig()
+	private := fixtures.SomePrivateIdentity()
+	public := privateIdentityToPublicIdentity(private)
+	logger := newDevNullLogger()
+	currentConfig := service.NewCurrentConfig(config)
+	adaptersFactory := badgerPubCommandsAdaptersFactory(currentConfig, public, logger)
+	transactionProvider := newCommandsTransactionProvider(db, adaptersFactory)
+	metricsMock := mocks.NewMetricsMock()
+	createInviteHandler := commands.NewCreateInviteHandler(transactionProvider, metricsMock)
+	currentTimeProvider := adapters.NewCurrentTimeProvider()
+	messageContentMappings := transport.Mappings()
+	marshaler, err := transport2.NewMarshaler(messageContentMappings, logger)
+	if err != nil {
+		return BadgerTestApplication{}, err
+	}
+	localFeedHeadTracker, err := adapters2.NewLocalFeedHeadTracker(public, logger)
+	if err != nil {
+		return BadgerTestApplication{}, err
+	}
+	welcomeMessageTemplate := extractWelcomeMessageFromConfig(config)
+	redeemInviteHandler := commands.NewRedeemInviteHandler(transactionProvider, currentTimeProvider, marshaler, private, localFeedHeadTracker, welcomeMessageTemplate, metricsMock)
+	publishPendingWelcomesHandler := commands.NewPublishPendingWelcomesHandler(transactionProvider, currentTimeProvider, marshaler, private, localFeedHeadTracker, welcomeMessageTemplate)
+	announcePubHandler := commands.NewAnnouncePubHandler(transactionProvider, currentTimeProvider, marshaler, private, localFeedHeadTracker)
+	blobCreatorMock := mocks.NewBlobCreatorMock()
+	updateProfileHandler := commands.NewUpdateProfileHandler(transactionProvider, blobCreatorMock, currentTimeProvider, marshaler, private, localFeedHeadTracker)
+	peerManagerMock := mocks2.NewPeerManagerMock()
+	disconnectHandler := commands.NewDisconnectHandler(peerManagerMock)
+	disconnectAllHandler := commands.NewDisconnectAllHandler(peerManagerMock)
+	appCommands := app.Commands{
+		CreateInvite:           createInviteHandler,
+		RedeemInvite:           redeemInviteHandler,
+		PublishPendingWelcomes: publishPendingWelcomesHandler,
+		AnnouncePub:            announcePubHandler,
+		UpdateProfile:          updateProfileHandler,
+		Disconnect:             disconnectHandler,
+		DisconnectAll:          disconnectAllHandler,
+	}
+	badgerAdaptersFactory := badgerTestAdaptersFactory()
+	badgerTransactionProvider := newTestTransactionProvider(db, badgerAdaptersFactory)
+	badgerTestApplication := BadgerTestApplication{
+		Commands:             appCommands,
+		TransactionProvider:  badgerTransactionProvider,
+		LocalIdentity:        private,
+		LocalFeedHeadTracker: localFeedHeadTracker,
+	}
+	return badgerTestApplication, nil
+}
+
 func buildBadgerNoTxTxAdapters(txn *badger2.Txn, public identity.Public, config service.Config, logger logging.Logger) (notx.TxAdapters, error) {
 	banListHasher := adapters.NewBanListHasher()
 	banListRepository := badger.NewBanListRepository(txn, banListHasher)
@@ -376,18 +522,22 @@ func buildBadgerPubCommandsAdapters(txn *badger2.Txn, public identity.Public, co
 	pubRepository := badger.NewPubRepository(txn)
 	blobRepository := badger.NewBlobRepository(txn)
 	feedRepository := badger.NewFeedRepository(txn, socialGraphRepository, receiveLogRepository, messageRepository, pubRepository, blobRepository, banListRepository, scuttlebutt)
+	pendingWelcomeRepository := badger3.NewPendingWelcomeRepository(txn)
 	commandsAdapters := commands.Adapters{
-		SocialGraph: socialGraphRepository,
-		Invite:      inviteRepository,
-		Feed:        feedRepository,
+		SocialGraph:    socialGraphRepository,
+		Invite:         inviteRepository,
+		Feed:           feedRepository,
+		PendingWelcome: pendingWelcomeRepository,
 	}
 	return commandsAdapters, nil
 }
 
 func buildBadgerTestAdapters(txn *badger2.Txn) (TestAdapters, error) {
 	inviteRepository := badger3.NewInviteRepository(txn)
+	pendingWelcomeRepository := badger3.NewPendingWelcomeRepository(txn)
 	testAdapters := TestAdapters{
-		InviteRepository: inviteRepository,
+		InviteRepository:         inviteRepository,
+		PendingWelcomeRepository: pendingWelcomeRepository,
 	}
 	return testAdapters, nil
 }
@@ -399,27 +549,64 @@ type TestApplication struct {
 
 	SocialGraphRepository *mocks.SocialGraphRepositoryMock
 	InviteRepository      *mocks.InviteRespositoryMock
+	PendingWelcomes       *mocks.PendingWelcomeRepositoryMock
 	FeedRepository        *mocks.FeedRepositoryMock
 	Marshaler             *mocks.MarshalerMock
 	FeedFormat            *mocks.FeedFormatMock
 	LocalIdentity         identity.Private
 	CurrentTimeProvider   *mocks.CurrentTimeProviderMock
+	BlobCreator           *mocks.BlobCreatorMock
+	RemoteFeedHeads       *mocks.RemoteFeedHeadsMock
+	Metrics               *mocks.MetricsMock
+	PeerManager           *mocks2.PeerManagerMock
+}
+
+func BuildTestApplication(tb testing.TB) (TestApplication, error) {
+	return BuildTestApplicationWithConfig(tb, service.NewDefaultConfig())
 }
 
 type BadgerTestAdapters struct {
 	TransactionProvider *TestTransactionProvider
 }
 
-func newAdvertiser(l identity.Public, config service.Config) (*local.Advertiser, error) {
-	return local.NewAdvertiser(l, config.ListenAddress)
+type BadgerTestApplication struct {
+	Commands app.Commands
+
+	TransactionProvider  *TestTransactionProvider
+	LocalIdentity        identity.Private
+	LocalFeedHeadTracker *adapters2.LocalFeedHeadTracker
+}
+
+func newAdvertisers(l identity.Public, config service.Config) ([]*local.Advertiser, error) {
+	if !config.LocalAdvertising {
+		return nil, nil
+	}
+
+	addresses, err := adapters2.LocalAdvertisementAddresses(config.ListenAddresses, config.LocalNetworkInterfaces)
+	if err != nil {
+		return nil, errors.Wrap(err, "error determining the advertised addresses")
+	}
+
+	var advertisers []*local.Advertiser
+	for _, address := range addresses {
+		advertiser, err := local.NewAdvertiser(l, address)
+		if err != nil {
+			return nil, errors.Wrapf(err, "error creating an advertiser for address '%s'", address)
+		}
+		advertisers = append(advertisers, advertiser)
+	}
+	return advertisers, nil
 }
 
 func newBadger(system logging.LoggingSystem, logger logging.Logger, config service.Config) (*badger2.DB, func(), error) {
 	badgerDirectory := filepath.Join(config.DataDirectory, "badger")
 
-	options := badger2.DefaultOptions(badgerDirectory)
-	options.Logger = badger.NewLogger(system, badger.LoggerLevelWarning)
-	options.SyncWrites = true
+	options, err := badger3.NewOptions(badgerDirectory, config)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "error creating the options")
+	}
+
+	options.Logger = badger.NewLogger(adapters2.NewNamedLoggingSystem(system, "badger"), badger.LoggerLevelDebug)
 
 	db, err := badger2.Open(options)
 	if err != nil {
@@ -442,14 +629,58 @@ func newContextLogger(loggingSystem logging.LoggingSystem) logging.Logger {
 	return logging.NewContextLogger(loggingSystem, "scuttlego")
 }
 
-func newLoggingSystem() logging.LogrusLoggingSystem {
+func newDevNullLogger() logging.Logger {
+	return logging.NewDevNullLogger()
+}
+
+func newLogrusLogger(config service.Config) (*logrus.Logger, func(), error) {
 	logger := logrus.New()
 
-	return logging.NewLogrusLoggingSystem(logger)
+	if config.LogFormat == service.LogFormatJSON {
+		logger.SetFormatter(&logrus.JSONFormatter{})
+	}
+
+	if config.LogFile == "" {
+		return logger, func() {}, nil
+	}
+
+	const megabyte = 1024 * 1024
+	f, err := adapters2.NewRotatingFile(config.LogFile, int64(config.LogFileMaxSizeMegabytes)*megabyte, config.LogFileMaxBackups)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "error opening the log file")
+	}
+
+	logger.SetOutput(f)
+
+	return logger, func() {
+		if err := f.Close(); err != nil {
+			fmt.Fprintf(os.Stderr, "error closing the log file: %s\n", err)
+		}
+	}, nil
+}
+
+func newLoggingSystem(logger *logrus.Logger, config service.Config) (*adapters2.LoggingSystem, error) {
+	return adapters2.NewLoggingSystem(logger, config.LogLevel, config.LogComponentLevels)
 }
 
-func newPeerManagerConfig() domain.PeerManagerConfig {
-	return domain.PeerManagerConfig{
-		PreferredPubs: nil,
+// newPeerManager creates a peer manager without any preferred pubs as they
+// are provided by pubadapters.ReloadablePeerManager.
+func newPeerManager(dialer domain.Dialer, roomDialer domain.RoomDialer, logger logging.Logger) *domain.PeerManager {
+	return domain.NewPeerManager(domain.PeerManagerConfig{}, dialer, roomDialer, logger)
+}
+
+func newPeerManagerConfig(currentConfig *service.CurrentConfig) adapters2.CurrentPeerManagerConfig {
+	return func() domain.PeerManagerConfig {
+		var preferredPubs []domain.Pub
+		for _, preferredPeer := range currentConfig.Get().PreferredPeers {
+			preferredPubs = append(preferredPubs, domain.Pub{
+				Identity: preferredPeer.Identity(),
+				Address:  preferredPeer.Address(),
+			})
+		}
+
+		return domain.PeerManagerConfig{
+			PreferredPubs: preferredPubs,
+		}
 	}
 }
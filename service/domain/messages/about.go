@@ -0,0 +1,122 @@
+package known
+
+import (
+	"github.com/boreq/errors"
+	"github.com/planetary-social/scuttlego-pub/internal"
+	"github.com/planetary-social/scuttlego/service/domain/feeds/content/known"
+	"github.com/planetary-social/scuttlego/service/domain/refs"
+)
+
+type About struct {
+	about       refs.Identity
+	name        string
+	description string
+	image       *refs.Blob
+
+	clearsName        bool
+	clearsDescription bool
+	clearsImage       bool
+}
+
+func NewAbout(about refs.Identity, name string, description string, image *refs.Blob) (About, error) {
+	if about.IsZero() {
+		return About{}, errors.New("zero value of about")
+	}
+
+	if image != nil && image.IsZero() {
+		return About{}, errors.New("zero value of image")
+	}
+
+	v := About{
+		about:       about,
+		name:        name,
+		description: description,
+	}
+
+	if image != nil {
+		v.image = internal.Pointer(*image)
+	}
+
+	return v, nil
+}
+
+func MustNewAbout(about refs.Identity, name string, description string, image *refs.Blob) About {
+	v, err := NewAbout(about, name, description, image)
+	if err != nil {
+		panic(err)
+	}
+	return v
+}
+
+func (a About) Type() known.MessageContentType {
+	return "about"
+}
+
+func (a About) About() refs.Identity {
+	return a.about
+}
+
+func (a About) Name() string {
+	return a.name
+}
+
+func (a About) Description() string {
+	return a.description
+}
+
+func (a About) Image() (refs.Blob, bool) {
+	if a.image == nil {
+		return refs.Blob{}, false
+	}
+	return *a.image, true
+}
+
+// IsEmpty returns true if the message doesn't set any fields describing the
+// identity.
+func (a About) IsEmpty() bool {
+	return a.name == "" && a.description == "" && a.image == nil
+}
+
+// WithClearedFields returns a copy of the message which explicitly clears the
+// fields which are empty in this message but are set in the provided message.
+// Clients merge about messages field by field so fields which are omitted keep
+// the values set by previous messages.
+func (a About) WithClearedFields(previous About) About {
+	a.clearsName = a.name == "" && previous.name != ""
+	a.clearsDescription = a.description == "" && previous.description != ""
+	a.clearsImage = a.image == nil && previous.image != nil
+	return a
+}
+
+// ClearsName returns true if the name should be explicitly set to an empty
+// value.
+func (a About) ClearsName() bool {
+	return a.clearsName
+}
+
+// ClearsDescription returns true if the description should be explicitly set
+// to an empty value.
+func (a About) ClearsDescription() bool {
+	return a.clearsDescription
+}
+
+// ClearsImage returns true if the image should be explicitly set to an empty
+// value.
+func (a About) ClearsImage() bool {
+	return a.clearsImage
+}
+
+// Equal returns true if both messages describe the same identity in the same
+// way. Cleared fields aren't compared as clearing an empty field doesn't
+// change how the identity is described.
+func (a About) Equal(o About) bool {
+	if !a.about.Equal(o.about) || a.name != o.name || a.description != o.description {
+		return false
+	}
+
+	if a.image == nil || o.image == nil {
+		return a.image == nil && o.image == nil
+	}
+
+	return a.image.Equal(*o.image)
+}
@@ -0,0 +1,82 @@
+package known
+
+import (
+	"github.com/boreq/errors"
+	"github.com/planetary-social/scuttlego-pub/internal"
+	"github.com/planetary-social/scuttlego/service/domain/feeds/content/known"
+	"github.com/planetary-social/scuttlego/service/domain/refs"
+)
+
+type Post struct {
+	text     string
+	mentions []Mention
+}
+
+func NewPost(text string, mentions []Mention) (Post, error) {
+	if text == "" {
+		return Post{}, errors.New("text is empty")
+	}
+
+	for _, mention := range mentions {
+		if mention.IsZero() {
+			return Post{}, errors.New("zero value of mention")
+		}
+	}
+
+	return Post{
+		text:     text,
+		mentions: internal.CopySlice(mentions),
+	}, nil
+}
+
+func MustNewPost(text string, mentions []Mention) Post {
+	v, err := NewPost(text, mentions)
+	if err != nil {
+		panic(err)
+	}
+	return v
+}
+
+func (p Post) Type() known.MessageContentType {
+	return "post"
+}
+
+func (p Post) Text() string {
+	return p.text
+}
+
+func (p Post) Mentions() []Mention {
+	return internal.CopySlice(p.mentions)
+}
+
+type Mention struct {
+	link refs.Feed
+	name string
+}
+
+func NewMention(link refs.Feed, name string) (Mention, error) {
+	if link.IsZero() {
+		return Mention{}, errors.New("zero value of link")
+	}
+	return Mention{link: link, name: name}, nil
+}
+
+func MustNewMention(link refs.Feed, name string) Mention {
+	v, err := NewMention(link, name)
+	if err != nil {
+		panic(err)
+	}
+	return v
+}
+
+func (m Mention) Link() refs.Feed {
+	return m.link
+}
+
+func (m Mention) Name() string {
+	return m.name
+}
+
+func (m Mention) IsZero() bool {
+	return m.link.IsZero()
+}
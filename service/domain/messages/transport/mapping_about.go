@@ -0,0 +1,126 @@
+package transport
+
+import (
+	"bytes"
+	"encoding/json"
+
+	"github.com/boreq/errors"
+	"github.com/planetary-social/scuttlego-pub/internal"
+	known "github.com/planetary-social/scuttlego-pub/service/domain/messages"
+	scuttlegoknown "github.com/planetary-social/scuttlego/service/domain/feeds/content/known"
+	"github.com/planetary-social/scuttlego/service/domain/feeds/content/transport"
+	"github.com/planetary-social/scuttlego/service/domain/refs"
+)
+
+var AboutMapping = transport.MessageContentMapping{
+	Marshal: func(con scuttlegoknown.KnownMessageContent) ([]byte, error) {
+		about, ok := con.(known.About)
+		if !ok {
+			return nil, errors.New("unknown type")
+		}
+
+		t := transportAbout{
+			MessageContentType: transport.NewMessageContentType(about),
+			About:              about.About().String(),
+		}
+
+		if about.Name() != "" || about.ClearsName() {
+			t.Name = internal.Pointer(about.Name())
+		}
+
+		if about.Description() != "" || about.ClearsDescription() {
+			t.Description = internal.Pointer(about.Description())
+		}
+
+		if image, ok := about.Image(); ok {
+			marshaledImage, err := marshalWithoutEscapingHTML(image.String())
+			if err != nil {
+				return nil, errors.Wrap(err, "error marshaling the image")
+			}
+			t.Image = marshaledImage
+		} else if about.ClearsImage() {
+			t.Image = json.RawMessage("null")
+		}
+
+		return marshalWithoutEscapingHTML(t)
+	},
+	Unmarshal: func(b []byte) (scuttlegoknown.KnownMessageContent, error) {
+		var t transportAboutWithAnyImage
+
+		if err := json.Unmarshal(b, &t); err != nil {
+			return nil, errors.Wrap(err, "json unmarshal failed")
+		}
+
+		about, err := refs.NewIdentity(t.About)
+		if err != nil {
+			return nil, errors.Wrap(err, "could not create an identity ref")
+		}
+
+		image, err := unmarshalAboutImage(t.Image)
+		if err != nil {
+			return nil, errors.Wrap(err, "could not unmarshal the image")
+		}
+
+		return known.NewAbout(about, t.Name, t.Description, image)
+	},
+}
+
+// marshalWithoutEscapingHTML is used as blob refs start with an ampersand which
+// would be escaped by the standard library producing a non-canonical message.
+func marshalWithoutEscapingHTML(v any) ([]byte, error) {
+	buf := &bytes.Buffer{}
+
+	encoder := json.NewEncoder(buf)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(v); err != nil {
+		return nil, errors.Wrap(err, "encoding failed")
+	}
+
+	return bytes.TrimSuffix(buf.Bytes(), []byte("\n")), nil
+}
+
+// unmarshalAboutImage accepts images specified either directly as a blob ref
+// or as an object with a link field as both formats are used by clients.
+func unmarshalAboutImage(raw json.RawMessage) (*refs.Blob, error) {
+	if len(raw) == 0 || string(raw) == "null" {
+		return nil, nil
+	}
+
+	var link string
+	if err := json.Unmarshal(raw, &link); err != nil {
+		var object transportAboutImage
+		if err := json.Unmarshal(raw, &object); err != nil {
+			return nil, errors.Wrap(err, "image is neither a string nor an object")
+		}
+		link = object.Link
+	}
+
+	blob, err := refs.NewBlob(link)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not create a blob ref")
+	}
+
+	return &blob, nil
+}
+
+// transportAbout omits fields which aren't set unless they are cleared in
+// which case they are set to empty values.
+type transportAbout struct {
+	transport.MessageContentType
+	About       string          `json:"about"`
+	Name        *string         `json:"name,omitempty"`
+	Description *string         `json:"description,omitempty"`
+	Image       json.RawMessage `json:"image,omitempty"`
+}
+
+type transportAboutWithAnyImage struct {
+	transport.MessageContentType
+	About       string          `json:"about"`
+	Name        string          `json:"name"`
+	Description string          `json:"description"`
+	Image       json.RawMessage `json:"image"`
+}
+
+type transportAboutImage struct {
+	Link string `json:"link"`
+}
@@ -0,0 +1,135 @@
+package transport_test
+
+import (
+	"testing"
+
+	"github.com/planetary-social/scuttlego-pub/internal"
+	known "github.com/planetary-social/scuttlego-pub/service/domain/messages"
+	msgcontents "github.com/planetary-social/scuttlego/service/domain/feeds/content"
+	scuttlegoknown "github.com/planetary-social/scuttlego/service/domain/feeds/content/known"
+	"github.com/planetary-social/scuttlego/service/domain/feeds/message"
+	"github.com/planetary-social/scuttlego/service/domain/refs"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMappingAboutUnmarshal(t *testing.T) {
+	iden := refs.MustNewIdentity("@sxlUkN7dW/qZ23Wid6J1IAnqWEJ3V13dT6TaFtn5LTc=.ed25519")
+	blob := refs.MustNewBlob("&uaGieSQDJcHfUp6hjIcIq55GoZh4Ug7tNmgaohoxrpw=.sha256")
+
+	testCases := []struct {
+		Name            string
+		Content         string
+		ExpectedMessage scuttlegoknown.KnownMessageContent
+	}{
+		{
+			Name: "all_fields",
+			Content: `
+{
+	"type": "about",
+	"about": "@sxlUkN7dW/qZ23Wid6J1IAnqWEJ3V13dT6TaFtn5LTc=.ed25519",
+	"name": "some name",
+	"description": "some description",
+	"image": "&uaGieSQDJcHfUp6hjIcIq55GoZh4Ug7tNmgaohoxrpw=.sha256"
+}`,
+			ExpectedMessage: known.MustNewAbout(iden, "some name", "some description", &blob),
+		},
+		{
+			Name: "image_as_object",
+			Content: `
+{
+	"type": "about",
+	"about": "@sxlUkN7dW/qZ23Wid6J1IAnqWEJ3V13dT6TaFtn5LTc=.ed25519",
+	"image": {
+		"link": "&uaGieSQDJcHfUp6hjIcIq55GoZh4Ug7tNmgaohoxrpw=.sha256",
+		"size": 123
+	}
+}`,
+			ExpectedMessage: known.MustNewAbout(iden, "", "", &blob),
+		},
+		{
+			Name: "only_name",
+			Content: `
+{
+	"type": "about",
+	"about": "@sxlUkN7dW/qZ23Wid6J1IAnqWEJ3V13dT6TaFtn5LTc=.ed25519",
+	"name": "some name"
+}`,
+			ExpectedMessage: known.MustNewAbout(iden, "some name", "", nil),
+		},
+		{
+			Name: "about_is_not_an_identity",
+			Content: `
+{
+	"type": "about",
+	"about": "%uaGieSQDJcHfUp6hjIcIq55GoZh4Ug7tNmgaohoxrpw=.sha256",
+	"name": "some name"
+}`,
+			ExpectedMessage: nil,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			marshaler := newMarshaler(t)
+
+			msg, err := marshaler.Unmarshal(message.MustNewRawContent([]byte(testCase.Content)))
+			if testCase.ExpectedMessage != nil {
+				require.NoError(t, err)
+				require.Equal(t, testCase.ExpectedMessage, msg)
+			} else {
+				require.ErrorIs(t, err, msgcontents.ErrUnknownContent)
+			}
+		})
+	}
+}
+
+func TestMappingAboutMarshal(t *testing.T) {
+	iden := refs.MustNewIdentity("@sxlUkN7dW/qZ23Wid6J1IAnqWEJ3V13dT6TaFtn5LTc=.ed25519")
+	blob := refs.MustNewBlob("&uaGieSQDJcHfUp6hjIcIq55GoZh4Ug7tNmgaohoxrpw=.sha256")
+
+	testCases := []struct {
+		Name            string
+		About           known.About
+		ExpectedContent string
+	}{
+		{
+			Name:            "all_fields",
+			About:           known.MustNewAbout(iden, "some name", "some description", internal.Pointer(blob)),
+			ExpectedContent: `{"type":"about","about":"@sxlUkN7dW/qZ23Wid6J1IAnqWEJ3V13dT6TaFtn5LTc=.ed25519","name":"some name","description":"some description","image":"&uaGieSQDJcHfUp6hjIcIq55GoZh4Ug7tNmgaohoxrpw=.sha256"}`,
+		},
+		{
+			Name:            "only_name",
+			About:           known.MustNewAbout(iden, "some name", "", nil),
+			ExpectedContent: `{"type":"about","about":"@sxlUkN7dW/qZ23Wid6J1IAnqWEJ3V13dT6TaFtn5LTc=.ed25519","name":"some name"}`,
+		},
+		{
+			Name: "cleared_fields",
+			About: known.MustNewAbout(iden, "some name", "", nil).WithClearedFields(
+				known.MustNewAbout(iden, "old name", "old description", internal.Pointer(blob)),
+			),
+			ExpectedContent: `{"type":"about","about":"@sxlUkN7dW/qZ23Wid6J1IAnqWEJ3V13dT6TaFtn5LTc=.ed25519","name":"some name","description":"","image":null}`,
+		},
+		{
+			Name: "fields_which_were_not_set_are_not_cleared",
+			About: known.MustNewAbout(iden, "some name", "", nil).WithClearedFields(
+				known.MustNewAbout(iden, "old name", "", nil),
+			),
+			ExpectedContent: `{"type":"about","about":"@sxlUkN7dW/qZ23Wid6J1IAnqWEJ3V13dT6TaFtn5LTc=.ed25519","name":"some name"}`,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			marshaler := newMarshaler(t)
+
+			raw, err := marshaler.Marshal(testCase.About)
+			require.NoError(t, err)
+
+			require.Equal(
+				t,
+				testCase.ExpectedContent,
+				string(raw.Bytes()),
+			)
+		})
+	}
+}
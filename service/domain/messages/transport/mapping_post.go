@@ -0,0 +1,92 @@
+package transport
+
+import (
+	"encoding/json"
+
+	"github.com/boreq/errors"
+	known "github.com/planetary-social/scuttlego-pub/service/domain/messages"
+	scuttlegoknown "github.com/planetary-social/scuttlego/service/domain/feeds/content/known"
+	"github.com/planetary-social/scuttlego/service/domain/feeds/content/transport"
+	"github.com/planetary-social/scuttlego/service/domain/refs"
+)
+
+var PostMapping = transport.MessageContentMapping{
+	Marshal: func(con scuttlegoknown.KnownMessageContent) ([]byte, error) {
+		post, ok := con.(known.Post)
+		if !ok {
+			return nil, errors.New("unknown type")
+		}
+
+		t := transportPost{
+			MessageContentType: transport.NewMessageContentType(post),
+			Text:               post.Text(),
+		}
+
+		for _, mention := range post.Mentions() {
+			t.Mentions = append(t.Mentions, transportPostMention{
+				Link: mention.Link().String(),
+				Name: mention.Name(),
+			})
+		}
+
+		return marshalWithoutEscapingHTML(t)
+	},
+	Unmarshal: func(b []byte) (scuttlegoknown.KnownMessageContent, error) {
+		var t transportPostWithAnyMentions
+
+		if err := json.Unmarshal(b, &t); err != nil {
+			return nil, errors.Wrap(err, "json unmarshal failed")
+		}
+
+		return known.NewPost(t.Text, unmarshalPostMentions(t.Mentions))
+	},
+}
+
+// unmarshalPostMentions returns only mentions of feeds. Clients use mentions
+// to link to other kinds of entities as well and there is no single format
+// for them so all other mentions are skipped instead of rejecting the post.
+func unmarshalPostMentions(raw json.RawMessage) []known.Mention {
+	var mentions []json.RawMessage
+	if err := json.Unmarshal(raw, &mentions); err != nil {
+		return nil
+	}
+
+	var result []known.Mention
+	for _, rawMention := range mentions {
+		var t transportPostMention
+		if err := json.Unmarshal(rawMention, &t); err != nil {
+			continue
+		}
+
+		link, err := refs.NewFeed(t.Link)
+		if err != nil {
+			continue
+		}
+
+		mention, err := known.NewMention(link, t.Name)
+		if err != nil {
+			continue
+		}
+
+		result = append(result, mention)
+	}
+
+	return result
+}
+
+type transportPost struct {
+	transport.MessageContentType
+	Text     string                 `json:"text"`
+	Mentions []transportPostMention `json:"mentions,omitempty"`
+}
+
+type transportPostWithAnyMentions struct {
+	transport.MessageContentType
+	Text     string          `json:"text"`
+	Mentions json.RawMessage `json:"mentions"`
+}
+
+type transportPostMention struct {
+	Link string `json:"link"`
+	Name string `json:"name,omitempty"`
+}
@@ -0,0 +1,129 @@
+package transport_test
+
+import (
+	"testing"
+
+	known "github.com/planetary-social/scuttlego-pub/service/domain/messages"
+	"github.com/planetary-social/scuttlego/service/domain/feeds/message"
+	"github.com/planetary-social/scuttlego/service/domain/refs"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMappingPostUnmarshal(t *testing.T) {
+	feed := refs.MustNewFeed("@sxlUkN7dW/qZ23Wid6J1IAnqWEJ3V13dT6TaFtn5LTc=.ed25519")
+
+	testCases := []struct {
+		Name            string
+		Content         string
+		ExpectedMessage known.Post
+	}{
+		{
+			Name: "no_mentions",
+			Content: `
+{
+	"type": "post",
+	"text": "some text"
+}`,
+			ExpectedMessage: known.MustNewPost("some text", nil),
+		},
+		{
+			Name: "feed_mentions",
+			Content: `
+{
+	"type": "post",
+	"text": "some text",
+	"mentions": [
+		{
+			"link": "@sxlUkN7dW/qZ23Wid6J1IAnqWEJ3V13dT6TaFtn5LTc=.ed25519",
+			"name": "alice"
+		}
+	]
+}`,
+			ExpectedMessage: known.MustNewPost("some text", []known.Mention{
+				known.MustNewMention(feed, "alice"),
+			}),
+		},
+		{
+			Name: "other_mentions_are_skipped",
+			Content: `
+{
+	"type": "post",
+	"text": "some text",
+	"mentions": [
+		{
+			"link": "&uaGieSQDJcHfUp6hjIcIq55GoZh4Ug7tNmgaohoxrpw=.sha256",
+			"name": "image.png"
+		},
+		{
+			"link": "#channel"
+		},
+		"@sxlUkN7dW/qZ23Wid6J1IAnqWEJ3V13dT6TaFtn5LTc=.ed25519",
+		{
+			"link": "@sxlUkN7dW/qZ23Wid6J1IAnqWEJ3V13dT6TaFtn5LTc=.ed25519"
+		}
+	]
+}`,
+			ExpectedMessage: known.MustNewPost("some text", []known.Mention{
+				known.MustNewMention(feed, ""),
+			}),
+		},
+		{
+			Name: "mentions_in_an_unknown_format",
+			Content: `
+{
+	"type": "post",
+	"text": "some text",
+	"mentions": {"something": "else"}
+}`,
+			ExpectedMessage: known.MustNewPost("some text", nil),
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			marshaler := newMarshaler(t)
+
+			msg, err := marshaler.Unmarshal(message.MustNewRawContent([]byte(testCase.Content)))
+			require.NoError(t, err)
+			require.Equal(t, testCase.ExpectedMessage, msg)
+		})
+	}
+}
+
+func TestMappingPostMarshal(t *testing.T) {
+	feed := refs.MustNewFeed("@sxlUkN7dW/qZ23Wid6J1IAnqWEJ3V13dT6TaFtn5LTc=.ed25519")
+
+	testCases := []struct {
+		Name            string
+		Post            known.Post
+		ExpectedContent string
+	}{
+		{
+			Name:            "no_mentions",
+			Post:            known.MustNewPost("some text", nil),
+			ExpectedContent: `{"type":"post","text":"some text"}`,
+		},
+		{
+			Name: "mentions",
+			Post: known.MustNewPost("Welcome [@alice](@sxlUkN7dW/qZ23Wid6J1IAnqWEJ3V13dT6TaFtn5LTc=.ed25519) & others!", []known.Mention{
+				known.MustNewMention(feed, "alice"),
+			}),
+			ExpectedContent: `{"type":"post","text":"Welcome [@alice](@sxlUkN7dW/qZ23Wid6J1IAnqWEJ3V13dT6TaFtn5LTc=.ed25519) & others!","mentions":[{"link":"@sxlUkN7dW/qZ23Wid6J1IAnqWEJ3V13dT6TaFtn5LTc=.ed25519","name":"alice"}]}`,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			marshaler := newMarshaler(t)
+
+			raw, err := marshaler.Marshal(testCase.Post)
+			require.NoError(t, err)
+
+			require.Equal(
+				t,
+				testCase.ExpectedContent,
+				string(raw.Bytes()),
+			)
+		})
+	}
+}
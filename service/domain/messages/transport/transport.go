@@ -1,13 +1,16 @@
 package transport
 
 import (
+	pubknown "github.com/planetary-social/scuttlego-pub/service/domain/messages"
 	"github.com/planetary-social/scuttlego/service/domain/feeds/content/known"
 	"github.com/planetary-social/scuttlego/service/domain/feeds/content/transport"
 )
 
 func Mappings() transport.MessageContentMappings {
 	return transport.MessageContentMappings{
-		known.Contact{}.Type(): ContactMapping,
-		known.Pub{}.Type():     transport.PubMapping,
+		known.Contact{}.Type():  ContactMapping,
+		known.Pub{}.Type():      transport.PubMapping,
+		pubknown.About{}.Type(): AboutMapping,
+		pubknown.Post{}.Type():  PostMapping,
 	}
 }
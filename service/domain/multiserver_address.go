@@ -0,0 +1,111 @@
+package domain
+
+import (
+	"encoding/base64"
+	"net"
+	"strconv"
+	"strings"
+
+	"github.com/boreq/errors"
+	"github.com/planetary-social/scuttlego/service/domain/identity"
+	"github.com/planetary-social/scuttlego/service/domain/network"
+)
+
+const (
+	multiserverNetPrefix = "net:"
+	multiserverShsPrefix = "shs:"
+)
+
+// MultiserverAddress specifies how to connect to a peer using TCP and secret
+// handshake, in the format "net:host:port~shs:key" where key is the base64
+// encoded public key of the peer.
+type MultiserverAddress struct {
+	identity identity.Public
+	address  network.Address
+	s        string
+}
+
+func NewMultiserverAddress(s string) (MultiserverAddress, error) {
+	transport, transform, ok := strings.Cut(s, "~")
+	if !ok {
+		return MultiserverAddress{}, errors.New("missing the '~' separator")
+	}
+
+	if !strings.HasPrefix(transport, multiserverNetPrefix) {
+		return MultiserverAddress{}, errors.New("only the net transport is supported")
+	}
+
+	if !strings.HasPrefix(transform, multiserverShsPrefix) {
+		return MultiserverAddress{}, errors.New("only the shs transform is supported")
+	}
+
+	address, err := parseMultiserverNetAddress(strings.TrimPrefix(transport, multiserverNetPrefix))
+	if err != nil {
+		return MultiserverAddress{}, errors.Wrap(err, "invalid net address")
+	}
+
+	key, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(transform, multiserverShsPrefix))
+	if err != nil {
+		return MultiserverAddress{}, errors.Wrap(err, "error decoding the key")
+	}
+
+	iden, err := identity.NewPublicFromBytes(key)
+	if err != nil {
+		return MultiserverAddress{}, errors.Wrap(err, "invalid key")
+	}
+
+	return MultiserverAddress{
+		identity: iden,
+		address:  address,
+		s:        s,
+	}, nil
+}
+
+func MustNewMultiserverAddress(s string) MultiserverAddress {
+	v, err := NewMultiserverAddress(s)
+	if err != nil {
+		panic(err)
+	}
+	return v
+}
+
+// parseMultiserverNetAddress parses the "host:port" part of the net transport.
+// IPv6 hosts don't have to be enclosed in brackets.
+func parseMultiserverNetAddress(s string) (network.Address, error) {
+	i := strings.LastIndex(s, ":")
+	if i < 0 {
+		return network.Address{}, errors.New("missing port")
+	}
+
+	host := strings.TrimSuffix(strings.TrimPrefix(s[:i], "["), "]")
+	if host == "" {
+		return network.Address{}, errors.New("host is empty")
+	}
+
+	port, err := strconv.Atoi(s[i+1:])
+	if err != nil {
+		return network.Address{}, errors.Wrap(err, "error parsing the port")
+	}
+
+	if port <= 0 || port > 65535 {
+		return network.Address{}, errors.New("port must be between 1 and 65535")
+	}
+
+	return network.NewAddress(net.JoinHostPort(host, strconv.Itoa(port))), nil
+}
+
+func (a MultiserverAddress) Identity() identity.Public {
+	return a.identity
+}
+
+func (a MultiserverAddress) Address() network.Address {
+	return a.address
+}
+
+func (a MultiserverAddress) String() string {
+	return a.s
+}
+
+func (a MultiserverAddress) IsZero() bool {
+	return a.s == ""
+}
@@ -0,0 +1,84 @@
+package domain_test
+
+import (
+	"encoding/base64"
+	"testing"
+
+	"github.com/planetary-social/scuttlego-pub/internal/fixtures"
+	"github.com/planetary-social/scuttlego-pub/service/domain"
+	"github.com/planetary-social/scuttlego/service/domain/network"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewMultiserverAddress(t *testing.T) {
+	iden := fixtures.SomePublicIdentity()
+	key := base64.StdEncoding.EncodeToString(iden.PublicKey())
+
+	testCases := []struct {
+		Name            string
+		Address         string
+		ExpectedAddress network.Address
+		ExpectedError   string
+	}{
+		{
+			Name:            "hostname",
+			Address:         "net:example.com:8008~shs:" + key,
+			ExpectedAddress: network.NewAddress("example.com:8008"),
+		},
+		{
+			Name:            "ipv4",
+			Address:         "net:192.168.1.10:8008~shs:" + key,
+			ExpectedAddress: network.NewAddress("192.168.1.10:8008"),
+		},
+		{
+			Name:            "ipv6",
+			Address:         "net:::1:8008~shs:" + key,
+			ExpectedAddress: network.NewAddress("[::1]:8008"),
+		},
+		{
+			Name:            "ipv6_in_brackets",
+			Address:         "net:[::1]:8008~shs:" + key,
+			ExpectedAddress: network.NewAddress("[::1]:8008"),
+		},
+		{
+			Name:          "missing_transform",
+			Address:       "net:example.com:8008",
+			ExpectedError: "missing the '~' separator",
+		},
+		{
+			Name:          "unsupported_transport",
+			Address:       "ws:example.com:8008~shs:" + key,
+			ExpectedError: "only the net transport is supported",
+		},
+		{
+			Name:          "unsupported_transform",
+			Address:       "net:example.com:8008~noauth",
+			ExpectedError: "only the shs transform is supported",
+		},
+		{
+			Name:          "missing_port",
+			Address:       "net:example.com~shs:" + key,
+			ExpectedError: "invalid net address: missing port",
+		},
+		{
+			Name:          "invalid_key",
+			Address:       "net:example.com:8008~shs:" + base64.StdEncoding.EncodeToString([]byte("short")),
+			ExpectedError: "invalid key",
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			address, err := domain.NewMultiserverAddress(testCase.Address)
+			if testCase.ExpectedError != "" {
+				require.ErrorContains(t, err, testCase.ExpectedError)
+				return
+			}
+
+			require.NoError(t, err)
+			require.True(t, iden.Equal(address.Identity()))
+			require.Equal(t, testCase.ExpectedAddress, address.Address())
+			require.Equal(t, testCase.Address, address.String())
+		})
+	}
+}
@@ -0,0 +1,49 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/boreq/errors"
+	"github.com/planetary-social/scuttlego/service/domain/refs"
+)
+
+// PendingWelcome is a welcome post which wasn't published yet as the name of
+// the new member wasn't known when they redeemed an invite.
+type PendingWelcome struct {
+	feed       refs.Feed
+	redeemedAt time.Time
+}
+
+func NewPendingWelcome(feed refs.Feed, redeemedAt time.Time) (PendingWelcome, error) {
+	if feed.IsZero() {
+		return PendingWelcome{}, errors.New("zero value of feed")
+	}
+
+	if redeemedAt.IsZero() {
+		return PendingWelcome{}, errors.New("zero value of redeemed at")
+	}
+
+	return PendingWelcome{feed: feed, redeemedAt: redeemedAt}, nil
+}
+
+func MustNewPendingWelcome(feed refs.Feed, redeemedAt time.Time) PendingWelcome {
+	v, err := NewPendingWelcome(feed, redeemedAt)
+	if err != nil {
+		panic(err)
+	}
+	return v
+}
+
+// Feed returns the feed of the new member.
+func (p PendingWelcome) Feed() refs.Feed {
+	return p.feed
+}
+
+// RedeemedAt returns the time at which the new member redeemed an invite.
+func (p PendingWelcome) RedeemedAt() time.Time {
+	return p.redeemedAt
+}
+
+func (p PendingWelcome) IsZero() bool {
+	return p.feed.IsZero()
+}
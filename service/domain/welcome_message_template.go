@@ -0,0 +1,85 @@
+package domain
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/boreq/errors"
+	"github.com/planetary-social/scuttlego/service/domain/refs"
+)
+
+const (
+	WelcomeMessageTemplatePlaceholderName = "{{name}}"
+	WelcomeMessageTemplatePlaceholderFeed = "{{feed}}"
+)
+
+// MaxWelcomeMessageNameLength is the maximum number of characters of a name
+// returned by SanitizeWelcomeMessageName.
+const MaxWelcomeMessageNameLength = 64
+
+// welcomeMessageNameRemovedCharacters could be used to create markdown links
+// or images.
+const welcomeMessageNameRemovedCharacters = "[]()<>!`"
+
+// WelcomeMessageTemplate is used to create posts greeting new members of the
+// pub. Placeholders WelcomeMessageTemplatePlaceholderName and
+// WelcomeMessageTemplatePlaceholderFeed are replaced with the name and the
+// feed of the new member.
+type WelcomeMessageTemplate struct {
+	template string
+}
+
+func NewWelcomeMessageTemplate(template string) (WelcomeMessageTemplate, error) {
+	if strings.TrimSpace(template) == "" {
+		return WelcomeMessageTemplate{}, errors.New("template is empty")
+	}
+	return WelcomeMessageTemplate{template: template}, nil
+}
+
+func MustNewWelcomeMessageTemplate(template string) WelcomeMessageTemplate {
+	v, err := NewWelcomeMessageTemplate(template)
+	if err != nil {
+		panic(err)
+	}
+	return v
+}
+
+func (t WelcomeMessageTemplate) Render(name string, feed refs.Feed) string {
+	return strings.NewReplacer(
+		WelcomeMessageTemplatePlaceholderName, name,
+		WelcomeMessageTemplatePlaceholderFeed, feed.String(),
+	).Replace(t.template)
+}
+
+func (t WelcomeMessageTemplate) String() string {
+	return t.template
+}
+
+func (t WelcomeMessageTemplate) IsZero() bool {
+	return t.template == ""
+}
+
+// SanitizeWelcomeMessageName prepares the name which a new member chose for
+// themselves to be used in the welcome post. Control characters and characters
+// which could be used to create markdown links are removed, whitespace is
+// collapsed into single spaces and the name is trimmed to
+// MaxWelcomeMessageNameLength characters. The returned name may be empty.
+func SanitizeWelcomeMessageName(name string) string {
+	name = strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) {
+			return ' '
+		}
+		if strings.ContainsRune(welcomeMessageNameRemovedCharacters, r) {
+			return -1
+		}
+		return r
+	}, name)
+
+	name = strings.Join(strings.Fields(name), " ")
+
+	if runes := []rune(name); len(runes) > MaxWelcomeMessageNameLength {
+		name = strings.TrimSpace(string(runes[:MaxWelcomeMessageNameLength]))
+	}
+
+	return name
+}
@@ -0,0 +1,96 @@
+package domain_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/planetary-social/scuttlego-pub/service/domain"
+	"github.com/planetary-social/scuttlego/service/domain/refs"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewWelcomeMessageTemplate_ReturnsAnErrorForEmptyTemplates(t *testing.T) {
+	_, err := domain.NewWelcomeMessageTemplate("")
+	require.EqualError(t, err, "template is empty")
+
+	_, err = domain.NewWelcomeMessageTemplate(" \n")
+	require.EqualError(t, err, "template is empty")
+}
+
+func TestWelcomeMessageTemplate_Render(t *testing.T) {
+	feed := refs.MustNewFeed("@sxlUkN7dW/qZ23Wid6J1IAnqWEJ3V13dT6TaFtn5LTc=.ed25519")
+
+	testCases := []struct {
+		Name           string
+		Template       string
+		ExpectedResult string
+	}{
+		{
+			Name:           "no_placeholders",
+			Template:       "Welcome!",
+			ExpectedResult: "Welcome!",
+		},
+		{
+			Name:           "all_placeholders",
+			Template:       "Welcome [@{{name}}]({{feed}})!",
+			ExpectedResult: "Welcome [@alice](@sxlUkN7dW/qZ23Wid6J1IAnqWEJ3V13dT6TaFtn5LTc=.ed25519)!",
+		},
+		{
+			Name:           "repeated_placeholders",
+			Template:       "{{name}} {{name}}",
+			ExpectedResult: "alice alice",
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			template := domain.MustNewWelcomeMessageTemplate(testCase.Template)
+			require.Equal(t, testCase.ExpectedResult, template.Render("alice", feed))
+		})
+	}
+}
+
+func TestSanitizeWelcomeMessageName(t *testing.T) {
+	testCases := []struct {
+		Name           string
+		MemberName     string
+		ExpectedResult string
+	}{
+		{
+			Name:           "regular_name",
+			MemberName:     "alice",
+			ExpectedResult: "alice",
+		},
+		{
+			Name:           "whitespace_is_trimmed_and_collapsed",
+			MemberName:     "  alice \t in  wonderland ",
+			ExpectedResult: "alice in wonderland",
+		},
+		{
+			Name:           "newlines_and_control_characters_are_replaced",
+			MemberName:     "alice\n\nsome text\x00",
+			ExpectedResult: "alice some text",
+		},
+		{
+			Name:           "markdown_links_are_removed",
+			MemberName:     "[alice](http://example.com) ![image](http://example.com/image.png)",
+			ExpectedResult: "alicehttp://example.com imagehttp://example.com/image.png",
+		},
+		{
+			Name:           "long_names_are_shortened",
+			MemberName:     strings.Repeat("ą", 100),
+			ExpectedResult: strings.Repeat("ą", domain.MaxWelcomeMessageNameLength),
+		},
+		{
+			Name:           "name_can_become_empty",
+			MemberName:     " \n[]() ",
+			ExpectedResult: "",
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			require.Equal(t, testCase.ExpectedResult, domain.SanitizeWelcomeMessageName(testCase.MemberName))
+		})
+	}
+}
@@ -0,0 +1,43 @@
+package service
+
+import (
+	"fmt"
+)
+
+var (
+	LogComponentReplication = LogComponent{"replication"}
+	LogComponentNetwork     = LogComponent{"network"}
+	LogComponentBadger      = LogComponent{"badger"}
+	LogComponentInvites     = LogComponent{"invites"}
+)
+
+// LogComponent is a group of subsystems for which the log level can be set
+// separately.
+type LogComponent struct {
+	s string
+}
+
+func NewLogComponent(s string) (LogComponent, error) {
+	for _, component := range []LogComponent{LogComponentReplication, LogComponentNetwork, LogComponentBadger, LogComponentInvites} {
+		if component.s == s {
+			return component, nil
+		}
+	}
+	return LogComponent{}, fmt.Errorf("unknown log component '%s', valid components are 'replication', 'network', 'badger' and 'invites'", s)
+}
+
+func MustNewLogComponent(s string) LogComponent {
+	v, err := NewLogComponent(s)
+	if err != nil {
+		panic(err)
+	}
+	return v
+}
+
+func (c LogComponent) String() string {
+	return c.s
+}
+
+func (c LogComponent) IsZero() bool {
+	return c == LogComponent{}
+}
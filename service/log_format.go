@@ -0,0 +1,40 @@
+package service
+
+import (
+	"fmt"
+)
+
+var (
+	LogFormatText = LogFormat{"text"}
+	LogFormatJSON = LogFormat{"json"}
+)
+
+// LogFormat specifies how log messages are formatted.
+type LogFormat struct {
+	s string
+}
+
+func NewLogFormat(s string) (LogFormat, error) {
+	for _, format := range []LogFormat{LogFormatText, LogFormatJSON} {
+		if format.s == s {
+			return format, nil
+		}
+	}
+	return LogFormat{}, fmt.Errorf("unknown log format '%s', valid formats are 'text' and 'json'", s)
+}
+
+func MustNewLogFormat(s string) LogFormat {
+	v, err := NewLogFormat(s)
+	if err != nil {
+		panic(err)
+	}
+	return v
+}
+
+func (f LogFormat) String() string {
+	return f.s
+}
+
+func (f LogFormat) IsZero() bool {
+	return f == LogFormat{}
+}
@@ -0,0 +1,42 @@
+package service
+
+import (
+	"fmt"
+)
+
+var (
+	LogLevelError = LogLevel{"error"}
+	LogLevelDebug = LogLevel{"debug"}
+	LogLevelTrace = LogLevel{"trace"}
+)
+
+// LogLevel specifies the most verbose level of log messages which are
+// displayed.
+type LogLevel struct {
+	s string
+}
+
+func NewLogLevel(s string) (LogLevel, error) {
+	for _, level := range []LogLevel{LogLevelError, LogLevelDebug, LogLevelTrace} {
+		if level.s == s {
+			return level, nil
+		}
+	}
+	return LogLevel{}, fmt.Errorf("unknown log level '%s', valid levels are 'error', 'debug' and 'trace'", s)
+}
+
+func MustNewLogLevel(s string) LogLevel {
+	v, err := NewLogLevel(s)
+	if err != nil {
+		panic(err)
+	}
+	return v
+}
+
+func (l LogLevel) String() string {
+	return l.s
+}
+
+func (l LogLevel) IsZero() bool {
+	return l == LogLevel{}
+}
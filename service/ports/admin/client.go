@@ -0,0 +1,129 @@
+package admin
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+
+	"github.com/boreq/errors"
+	"github.com/planetary-social/scuttlego/service/domain/refs"
+)
+
+// Client sends requests to the admin socket of a running pub.
+type Client struct {
+	path string
+}
+
+func NewClient(path string) *Client {
+	return &Client{path: path}
+}
+
+func (c *Client) CreateInvite(ctx context.Context, params CreateInviteParams) (CreateInviteResult, error) {
+	var result CreateInviteResult
+	if err := c.call(ctx, MethodCreateInvite, params, &result); err != nil {
+		return CreateInviteResult{}, errors.Wrap(err, "call failed")
+	}
+	return result, nil
+}
+
+func (c *Client) BanFeed(ctx context.Context, feed refs.Feed) error {
+	if err := c.call(ctx, MethodBanFeed, FeedParams{Feed: feed.String()}, nil); err != nil {
+		return errors.Wrap(err, "call failed")
+	}
+	return nil
+}
+
+func (c *Client) UnbanFeed(ctx context.Context, feed refs.Feed) error {
+	if err := c.call(ctx, MethodUnbanFeed, FeedParams{Feed: feed.String()}, nil); err != nil {
+		return errors.Wrap(err, "call failed")
+	}
+	return nil
+}
+
+func (c *Client) Status(ctx context.Context) (StatusResult, error) {
+	var result StatusResult
+	if err := c.call(ctx, MethodStatus, nil, &result); err != nil {
+		return StatusResult{}, errors.Wrap(err, "call failed")
+	}
+	return result, nil
+}
+
+// Connect doesn't return an error if the pub is already connected to the peer.
+func (c *Client) Connect(ctx context.Context, address string) error {
+	if err := c.call(ctx, MethodConnect, ConnectParams{Address: address}, nil); err != nil {
+		return errors.Wrap(err, "call failed")
+	}
+	return nil
+}
+
+func (c *Client) Disconnect(ctx context.Context, feed refs.Feed) error {
+	if err := c.call(ctx, MethodDisconnect, FeedParams{Feed: feed.String()}, nil); err != nil {
+		return errors.Wrap(err, "call failed")
+	}
+	return nil
+}
+
+// DisconnectAll closes connections to all peers.
+func (c *Client) DisconnectAll(ctx context.Context) error {
+	if err := c.call(ctx, MethodDisconnectAll, nil, nil); err != nil {
+		return errors.Wrap(err, "call failed")
+	}
+	return nil
+}
+
+func (c *Client) ListPeers(ctx context.Context) (ListPeersResult, error) {
+	var result ListPeersResult
+	if err := c.call(ctx, MethodListPeers, nil, &result); err != nil {
+		return ListPeersResult{}, errors.Wrap(err, "call failed")
+	}
+	return result, nil
+}
+
+// call sends the request and unmarshals the result into the provided value
+// unless it is nil.
+func (c *Client) call(ctx context.Context, method string, params any, result any) error {
+	marshaledParams, err := json.Marshal(params)
+	if err != nil {
+		return errors.Wrap(err, "error marshaling params")
+	}
+
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "unix", c.path)
+	if err != nil {
+		return errors.Wrapf(err, "error connecting to the admin socket '%s', is the pub running?", c.path)
+	}
+	defer conn.Close()
+
+	if deadline, ok := ctx.Deadline(); ok {
+		if err := conn.SetDeadline(deadline); err != nil {
+			return errors.Wrap(err, "error setting the deadline")
+		}
+	}
+
+	request := Request{
+		Method: method,
+		Params: marshaledParams,
+	}
+
+	if err := json.NewEncoder(conn).Encode(request); err != nil {
+		return errors.Wrap(err, "error sending the request")
+	}
+
+	var response Response
+	if err := json.NewDecoder(conn).Decode(&response); err != nil {
+		return errors.Wrap(err, "error receiving the response")
+	}
+
+	if response.Error != "" {
+		return fmt.Errorf("pub returned an error: %s", response.Error)
+	}
+
+	if result != nil {
+		if err := json.Unmarshal(response.Result, result); err != nil {
+			return errors.Wrap(err, "error unmarshaling the result")
+		}
+	}
+
+	return nil
+}
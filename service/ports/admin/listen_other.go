@@ -0,0 +1,9 @@
+//go:build !unix
+
+package admin
+
+import "net"
+
+func listen(path string) (net.Listener, error) {
+	return net.Listen("unix", path)
+}
@@ -0,0 +1,20 @@
+//go:build unix
+
+package admin
+
+import (
+	"net"
+	"syscall"
+)
+
+// listen creates the socket with its permissions already restricted so that
+// there is no window during which other users could connect to it. The umask
+// is process-wide so files created concurrently by other goroutines may end up
+// with more restrictive permissions than they would otherwise have, which is
+// harmless.
+func listen(path string) (net.Listener, error) {
+	oldUmask := syscall.Umask(0o777 &^ socketPermissions)
+	defer syscall.Umask(oldUmask)
+
+	return net.Listen("unix", path)
+}
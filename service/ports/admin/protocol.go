@@ -0,0 +1,107 @@
+// Package admin implements the admin socket which is used by the CLI to manage
+// the running pub. The client sends a single request encoded as JSON, the
+// server responds with a single response encoded as JSON and closes the
+// connection.
+package admin
+
+import (
+	"encoding/json"
+	"time"
+)
+
+const (
+	MethodCreateInvite  = "create_invite"
+	MethodBanFeed       = "ban_feed"
+	MethodUnbanFeed     = "unban_feed"
+	MethodStatus        = "status"
+	MethodConnect       = "connect"
+	MethodDisconnect    = "disconnect"
+	MethodDisconnectAll = "disconnect_all"
+	MethodListPeers     = "list_peers"
+)
+
+type Request struct {
+	Method string          `json:"method"`
+	Params json.RawMessage `json:"params,omitempty"`
+}
+
+// Response contains either the result or an error.
+type Response struct {
+	Result json.RawMessage `json:"result,omitempty"`
+	Error  string          `json:"error,omitempty"`
+}
+
+type CreateInviteParams struct {
+	// NumberOfUses is optional, if it is nil the invite can be used any
+	// number of times.
+	NumberOfUses *int `json:"number_of_uses,omitempty"`
+
+	// ValidUntil is optional, if it is nil the invite doesn't expire.
+	ValidUntil *time.Time `json:"valid_until,omitempty"`
+}
+
+type CreateInviteResult struct {
+	// SecretKeySeed is encoded using standard base64.
+	SecretKeySeed string `json:"secret_key_seed"`
+
+	// InviteCode is empty if the public address of the pub isn't
+	// configured.
+	InviteCode string `json:"invite_code,omitempty"`
+}
+
+type FeedParams struct {
+	Feed string `json:"feed"`
+}
+
+type ConnectParams struct {
+	// Address is a multiserver address in the format
+	// "net:host:port~shs:key".
+	Address string `json:"address"`
+}
+
+type ListPeersResult struct {
+	Peers []PeerStatus `json:"peers"`
+}
+
+type StatusResult struct {
+	Identity        string   `json:"identity"`
+	ListenAddresses []string `json:"listen_addresses"`
+
+	// PublicAddress is empty if it isn't configured.
+	PublicAddress string `json:"public_address,omitempty"`
+
+	StartedAt        time.Time    `json:"started_at"`
+	Peers            []PeerStatus `json:"peers"`
+	NumberOfFeeds    int          `json:"number_of_feeds"`
+	NumberOfMessages int          `json:"number_of_messages"`
+
+	// BlobStorageBytes is nil if the size of the blob storage hasn't been
+	// determined yet.
+	BlobStorageBytes *int64 `json:"blob_storage_bytes"`
+
+	// Invites are counted since the pub was started.
+	Invites InviteCounts `json:"invites"`
+}
+
+type PeerStatus struct {
+	Identity string `json:"identity"`
+
+	// Connection is nil if the pub doesn't know the details of the
+	// connection.
+	Connection *ConnectionStatus `json:"connection,omitempty"`
+}
+
+type ConnectionStatus struct {
+	// Address is empty if the connection doesn't have a network address
+	// e.g. because it was tunneled through a room.
+	Address string `json:"address,omitempty"`
+
+	ConnectedAt       time.Time `json:"connected_at"`
+	InitiatedByRemote bool      `json:"initiated_by_remote"`
+}
+
+type InviteCounts struct {
+	Created  uint64 `json:"created"`
+	Redeemed uint64 `json:"redeemed"`
+	Rejected uint64 `json:"rejected"`
+}
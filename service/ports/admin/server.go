@@ -0,0 +1,451 @@
+package admin
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net"
+	"os"
+	"syscall"
+	"time"
+
+	"github.com/boreq/errors"
+	"github.com/planetary-social/scuttlego-pub/service/app/commands"
+	"github.com/planetary-social/scuttlego-pub/service/app/queries"
+	"github.com/planetary-social/scuttlego-pub/service/domain"
+	"github.com/planetary-social/scuttlego/logging"
+	scuttlegocommands "github.com/planetary-social/scuttlego/service/app/commands"
+	"github.com/planetary-social/scuttlego/service/domain/bans"
+	"github.com/planetary-social/scuttlego/service/domain/identity"
+	"github.com/planetary-social/scuttlego/service/domain/refs"
+)
+
+// requestTimeout limits the time spent on reading a request, executing it and
+// writing the response. Connecting to peers is bounded by the timeouts of
+// dialing and of the handshake instead.
+const requestTimeout = 1 * time.Minute
+
+const socketPermissions = 0o600
+
+// staleSocketDialTimeout limits the time spent on checking if another instance
+// is listening on an existing socket.
+const staleSocketDialTimeout = 5 * time.Second
+
+type CreateInviteCommandHandler interface {
+	Handle(cmd commands.CreateInvite) (domain.SecretKeySeed, error)
+}
+
+type AddToBanListCommandHandler interface {
+	Handle(cmd scuttlegocommands.AddToBanList) error
+}
+
+type RemoveFromBanListCommandHandler interface {
+	Handle(cmd scuttlegocommands.RemoveFromBanList) error
+}
+
+type BanListHasher interface {
+	HashForFeed(feed refs.Feed) (bans.Hash, error)
+}
+
+type StatusQueryHandler interface {
+	Handle() (queries.Status, error)
+}
+
+type ConnectCommandHandler interface {
+	Handle(ctx context.Context, cmd scuttlegocommands.Connect) error
+}
+
+type DisconnectCommandHandler interface {
+	Handle(cmd commands.Disconnect) error
+}
+
+type DisconnectAllCommandHandler interface {
+	Handle() error
+}
+
+type ConnectedPeersQueryHandler interface {
+	Handle() []queries.PeerStatus
+}
+
+// handlerFunc receives the context of the request which is cancelled when the
+// request is completed and the context of the server which is cancelled when
+// the server stops. Connections to peers live as long as the context which was
+// used to establish them so they must be established using the context of the
+// server.
+type handlerFunc func(ctx, serverCtx context.Context, params json.RawMessage) (any, error)
+
+// Server listens on a unix domain socket and executes requests sent by the
+// CLI.
+type Server struct {
+	path            string
+	local           identity.Public
+	listenAddresses []string
+	publicAddress   string
+
+	createInvite      CreateInviteCommandHandler
+	addToBanList      AddToBanListCommandHandler
+	removeFromBanList RemoveFromBanListCommandHandler
+	banListHasher     BanListHasher
+	status            StatusQueryHandler
+	connect           ConnectCommandHandler
+	disconnect        DisconnectCommandHandler
+	disconnectAll     DisconnectAllCommandHandler
+	connectedPeers    ConnectedPeersQueryHandler
+
+	handlers map[string]handlerFunc
+	logger   logging.Logger
+}
+
+// NewServer creates a server listening on the provided path. Public address is
+// optional, it is used to create invite codes. Listen addresses and the public
+// address are reported in the status.
+func NewServer(
+	path string,
+	local identity.Public,
+	listenAddresses []string,
+	publicAddress string,
+	createInvite CreateInviteCommandHandler,
+	addToBanList AddToBanListCommandHandler,
+	removeFromBanList RemoveFromBanListCommandHandler,
+	banListHasher BanListHasher,
+	status StatusQueryHandler,
+	connect ConnectCommandHandler,
+	disconnect DisconnectCommandHandler,
+	disconnectAll DisconnectAllCommandHandler,
+	connectedPeers ConnectedPeersQueryHandler,
+	logger logging.Logger,
+) *Server {
+	s := &Server{
+		path:            path,
+		local:           local,
+		listenAddresses: listenAddresses,
+		publicAddress:   publicAddress,
+
+		createInvite:      createInvite,
+		addToBanList:      addToBanList,
+		removeFromBanList: removeFromBanList,
+		banListHasher:     banListHasher,
+		status:            status,
+		connect:           connect,
+		disconnect:        disconnect,
+		disconnectAll:     disconnectAll,
+		connectedPeers:    connectedPeers,
+
+		logger: logger.New("admin_server"),
+	}
+
+	s.handlers = map[string]handlerFunc{
+		MethodCreateInvite:  s.handleCreateInvite,
+		MethodBanFeed:       s.handleBanFeed,
+		MethodUnbanFeed:     s.handleUnbanFeed,
+		MethodStatus:        s.handleStatus,
+		MethodConnect:       s.handleConnect,
+		MethodDisconnect:    s.handleDisconnect,
+		MethodDisconnectAll: s.handleDisconnectAll,
+		MethodListPeers:     s.handleListPeers,
+	}
+
+	return s
+}
+
+// Run listens on the socket and serves requests until the context is
+// cancelled. A socket left behind by a pub which wasn't stopped cleanly is
+// removed first. If another instance is still listening on the socket an error
+// is returned instead.
+func (s *Server) Run(ctx context.Context) error {
+	if err := removeStaleSocket(s.path); err != nil {
+		return errors.Wrap(err, "error removing the stale socket")
+	}
+
+	listener, err := listen(s.path)
+	if err != nil {
+		return errors.Wrap(err, "error listening")
+	}
+	defer listener.Close()
+
+	go func() {
+		<-ctx.Done()
+		if err := listener.Close(); err != nil {
+			s.logger.Debug().WithError(err).Message("error closing the listener")
+		}
+	}()
+
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			return errors.Wrap(err, "error accepting a connection")
+		}
+
+		go s.handleConnection(ctx, conn)
+	}
+}
+
+func (s *Server) handleConnection(serverCtx context.Context, conn net.Conn) {
+	defer conn.Close()
+
+	ctx, cancel := context.WithTimeout(serverCtx, requestTimeout)
+	defer cancel()
+
+	if err := conn.SetDeadline(time.Now().Add(requestTimeout)); err != nil {
+		s.logger.Debug().WithError(err).Message("error setting the deadline")
+		return
+	}
+
+	var request Request
+	if err := json.NewDecoder(conn).Decode(&request); err != nil {
+		s.logger.Debug().WithError(err).Message("error decoding the request")
+		return
+	}
+
+	response := s.handleRequest(ctx, serverCtx, request)
+
+	if err := json.NewEncoder(conn).Encode(response); err != nil {
+		s.logger.Debug().WithError(err).Message("error encoding the response")
+		return
+	}
+}
+
+func (s *Server) handleRequest(ctx, serverCtx context.Context, request Request) Response {
+	logger := s.logger.WithField("method", request.Method)
+
+	handler, ok := s.handlers[request.Method]
+	if !ok {
+		logger.Debug().Message("unknown method")
+		return Response{Error: fmt.Sprintf("unknown method '%s'", request.Method)}
+	}
+
+	result, err := handler(ctx, serverCtx, request.Params)
+	if err != nil {
+		logger.Debug().WithError(err).Message("error handling the request")
+		return Response{Error: err.Error()}
+	}
+
+	marshaledResult, err := json.Marshal(result)
+	if err != nil {
+		logger.Error().WithError(err).Message("error marshaling the result")
+		return Response{Error: "error marshaling the result"}
+	}
+
+	logger.Debug().Message("handled the request")
+	return Response{Result: marshaledResult}
+}
+
+func (s *Server) handleCreateInvite(ctx, _ context.Context, rawParams json.RawMessage) (any, error) {
+	var params CreateInviteParams
+	if err := unmarshalParams(rawParams, &params); err != nil {
+		return nil, errors.Wrap(err, "error unmarshaling params")
+	}
+
+	cmd, err := commands.NewCreateInvite(params.NumberOfUses, params.ValidUntil)
+	if err != nil {
+		return nil, errors.Wrap(err, "error creating the command")
+	}
+
+	seed, err := s.createInvite.Handle(cmd)
+	if err != nil {
+		return nil, errors.Wrap(err, "error creating the invite")
+	}
+
+	encodedSeed := base64.StdEncoding.EncodeToString(seed.Bytes())
+
+	var inviteCode string
+	if s.publicAddress != "" {
+		inviteCode = fmt.Sprintf("%s:%s~%s", s.publicAddress, refs.MustNewIdentityFromPublic(s.local), encodedSeed)
+	}
+
+	return CreateInviteResult{
+		SecretKeySeed: encodedSeed,
+		InviteCode:    inviteCode,
+	}, nil
+}
+
+func (s *Server) handleBanFeed(ctx, _ context.Context, rawParams json.RawMessage) (any, error) {
+	hash, err := s.feedHash(rawParams)
+	if err != nil {
+		return nil, errors.Wrap(err, "error getting the hash of the feed")
+	}
+
+	cmd, err := scuttlegocommands.NewAddToBanList(hash)
+	if err != nil {
+		return nil, errors.Wrap(err, "error creating the command")
+	}
+
+	if err := s.addToBanList.Handle(cmd); err != nil {
+		return nil, errors.Wrap(err, "error banning the feed")
+	}
+
+	return struct{}{}, nil
+}
+
+func (s *Server) handleUnbanFeed(ctx, _ context.Context, rawParams json.RawMessage) (any, error) {
+	hash, err := s.feedHash(rawParams)
+	if err != nil {
+		return nil, errors.Wrap(err, "error getting the hash of the feed")
+	}
+
+	cmd, err := scuttlegocommands.NewRemoveFromBanList(hash)
+	if err != nil {
+		return nil, errors.Wrap(err, "error creating the command")
+	}
+
+	if err := s.removeFromBanList.Handle(cmd); err != nil {
+		return nil, errors.Wrap(err, "error unbanning the feed")
+	}
+
+	return struct{}{}, nil
+}
+
+func (s *Server) handleStatus(ctx, _ context.Context, rawParams json.RawMessage) (any, error) {
+	status, err := s.status.Handle()
+	if err != nil {
+		return nil, errors.Wrap(err, "error getting the status")
+	}
+
+	return StatusResult{
+		Identity:         refs.MustNewIdentityFromPublic(s.local).String(),
+		ListenAddresses:  s.listenAddresses,
+		PublicAddress:    s.publicAddress,
+		StartedAt:        status.StartedAt,
+		Peers:            newPeerStatuses(status.Peers),
+		NumberOfFeeds:    status.NumberOfFeeds,
+		NumberOfMessages: status.NumberOfMessages,
+		BlobStorageBytes: status.BlobStorageBytes,
+		Invites: InviteCounts{
+			Created:  status.Invites.Created,
+			Redeemed: status.Invites.Redeemed,
+			Rejected: status.Invites.Rejected,
+		},
+	}, nil
+}
+
+func (s *Server) handleConnect(_, serverCtx context.Context, rawParams json.RawMessage) (any, error) {
+	var params ConnectParams
+	if err := unmarshalParams(rawParams, &params); err != nil {
+		return nil, errors.Wrap(err, "error unmarshaling params")
+	}
+
+	address, err := domain.NewMultiserverAddress(params.Address)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid address")
+	}
+
+	cmd := scuttlegocommands.Connect{
+		Remote:  address.Identity(),
+		Address: address.Address(),
+	}
+
+	if err := s.connect.Handle(serverCtx, cmd); err != nil {
+		return nil, errors.Wrap(err, "error connecting")
+	}
+
+	return struct{}{}, nil
+}
+
+func (s *Server) handleDisconnect(ctx, _ context.Context, rawParams json.RawMessage) (any, error) {
+	var params FeedParams
+	if err := unmarshalParams(rawParams, &params); err != nil {
+		return nil, errors.Wrap(err, "error unmarshaling params")
+	}
+
+	feed, err := refs.NewFeed(params.Feed)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid feed")
+	}
+
+	cmd, err := commands.NewDisconnect(feed.Identity())
+	if err != nil {
+		return nil, errors.Wrap(err, "error creating the command")
+	}
+
+	if err := s.disconnect.Handle(cmd); err != nil {
+		return nil, errors.Wrap(err, "error disconnecting")
+	}
+
+	return struct{}{}, nil
+}
+
+func (s *Server) handleDisconnectAll(ctx, _ context.Context, rawParams json.RawMessage) (any, error) {
+	if err := s.disconnectAll.Handle(); err != nil {
+		return nil, errors.Wrap(err, "error disconnecting")
+	}
+
+	return struct{}{}, nil
+}
+
+func (s *Server) handleListPeers(ctx, _ context.Context, rawParams json.RawMessage) (any, error) {
+	return ListPeersResult{
+		Peers: newPeerStatuses(s.connectedPeers.Handle()),
+	}, nil
+}
+
+func (s *Server) feedHash(rawParams json.RawMessage) (bans.Hash, error) {
+	var params FeedParams
+	if err := unmarshalParams(rawParams, &params); err != nil {
+		return bans.Hash{}, errors.Wrap(err, "error unmarshaling params")
+	}
+
+	feed, err := refs.NewFeed(params.Feed)
+	if err != nil {
+		return bans.Hash{}, errors.Wrap(err, "invalid feed")
+	}
+
+	return s.banListHasher.HashForFeed(feed)
+}
+
+func newPeerStatuses(peers []queries.PeerStatus) []PeerStatus {
+	result := make([]PeerStatus, 0, len(peers))
+	for _, peer := range peers {
+		peerStatus := PeerStatus{
+			Identity: refs.MustNewIdentityFromPublic(peer.Identity).String(),
+		}
+		if peer.Connection != nil {
+			peerStatus.Connection = &ConnectionStatus{
+				Address:           peer.Connection.Address,
+				ConnectedAt:       peer.Connection.ConnectedAt,
+				InitiatedByRemote: peer.Connection.InitiatedByRemote,
+			}
+		}
+		result = append(result, peerStatus)
+	}
+	return result
+}
+
+// unmarshalParams leaves params unchanged if they weren't sent.
+func unmarshalParams(rawParams json.RawMessage, params any) error {
+	if len(rawParams) == 0 {
+		return nil
+	}
+	return json.Unmarshal(rawParams, params)
+}
+
+func removeStaleSocket(path string) error {
+	info, err := os.Lstat(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+		return errors.Wrap(err, "stat failed")
+	}
+
+	if info.Mode()&os.ModeSocket == 0 {
+		return fmt.Errorf("'%s' exists and isn't a socket", path)
+	}
+
+	conn, err := net.DialTimeout("unix", path, staleSocketDialTimeout)
+	if err == nil {
+		if err := conn.Close(); err != nil {
+			return errors.Wrap(err, "error closing the connection")
+		}
+		return fmt.Errorf("another instance is running and listening on '%s'", path)
+	}
+
+	if !errors.Is(err, syscall.ECONNREFUSED) {
+		return errors.Wrap(err, "error checking if the socket is in use")
+	}
+
+	return os.Remove(path)
+}
@@ -0,0 +1,513 @@
+package admin_test
+
+import (
+	"context"
+	"encoding/base64"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/boreq/errors"
+	"github.com/planetary-social/scuttlego-pub/internal"
+	"github.com/planetary-social/scuttlego-pub/internal/fixtures"
+	"github.com/planetary-social/scuttlego-pub/internal/mocks"
+	"github.com/planetary-social/scuttlego-pub/service/app/commands"
+	"github.com/planetary-social/scuttlego-pub/service/app/queries"
+	"github.com/planetary-social/scuttlego-pub/service/ports/admin"
+	"github.com/planetary-social/scuttlego/logging"
+	"github.com/planetary-social/scuttlego/service/adapters"
+	scuttlegocommands "github.com/planetary-social/scuttlego/service/app/commands"
+	"github.com/planetary-social/scuttlego/service/domain/bans"
+	"github.com/planetary-social/scuttlego/service/domain/identity"
+	"github.com/planetary-social/scuttlego/service/domain/invites"
+	"github.com/planetary-social/scuttlego/service/domain/network"
+	"github.com/planetary-social/scuttlego/service/domain/refs"
+	"github.com/stretchr/testify/require"
+)
+
+func TestServer_CreateInvite(t *testing.T) {
+	ts := newTestServer(t, "example.com:8008")
+
+	seed := fixtures.SomeSecretKeySeed()
+	ts.CreateInvite.HandleReturnValue = seed
+
+	numberOfUses := fixtures.SomePositiveInt()
+	validUntil := time.Date(2030, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	result, err := ts.Client.CreateInvite(context.Background(), admin.CreateInviteParams{
+		NumberOfUses: internal.Pointer(numberOfUses),
+		ValidUntil:   internal.Pointer(validUntil),
+	})
+	require.NoError(t, err)
+
+	require.Equal(t,
+		[]commands.CreateInvite{
+			mustNewCreateInvite(&numberOfUses, &validUntil),
+		},
+		ts.CreateInvite.HandleCalls(),
+	)
+
+	require.Equal(t, base64.StdEncoding.EncodeToString(seed.Bytes()), result.SecretKeySeed)
+
+	invite, err := invites.NewInviteFromString(result.InviteCode)
+	require.NoError(t, err)
+	require.Equal(t, "example.com:8008", invite.Address().String())
+	require.Equal(t, refs.MustNewIdentityFromPublic(ts.Local.Public()), invite.Remote())
+	require.Equal(t, seed.Bytes(), invite.SecretKeySeed())
+}
+
+func TestServer_CreateInviteWithoutPublicAddressReturnsOnlyTheSeed(t *testing.T) {
+	ts := newTestServer(t, "")
+
+	seed := fixtures.SomeSecretKeySeed()
+	ts.CreateInvite.HandleReturnValue = seed
+
+	result, err := ts.Client.CreateInvite(context.Background(), admin.CreateInviteParams{})
+	require.NoError(t, err)
+
+	require.Equal(t,
+		[]commands.CreateInvite{
+			mustNewCreateInvite(nil, nil),
+		},
+		ts.CreateInvite.HandleCalls(),
+	)
+
+	require.Equal(t, base64.StdEncoding.EncodeToString(seed.Bytes()), result.SecretKeySeed)
+	require.Empty(t, result.InviteCode)
+}
+
+func TestServer_CreateInviteReturnsErrorsOfInvalidCommands(t *testing.T) {
+	ts := newTestServer(t, "")
+
+	_, err := ts.Client.CreateInvite(context.Background(), admin.CreateInviteParams{
+		NumberOfUses: internal.Pointer(0),
+	})
+	require.EqualError(t, err, "call failed: pub returned an error: error creating the command: number of uses is zero")
+
+	require.Empty(t, ts.CreateInvite.HandleCalls())
+}
+
+func TestServer_BanAndUnbanFeed(t *testing.T) {
+	ts := newTestServer(t, "")
+
+	feed := fixtures.SomeRefFeed()
+
+	hash, err := adapters.NewBanListHasher().HashForFeed(feed)
+	require.NoError(t, err)
+
+	err = ts.Client.BanFeed(context.Background(), feed)
+	require.NoError(t, err)
+
+	require.Equal(t,
+		[]scuttlegocommands.AddToBanList{
+			mustNewAddToBanList(hash),
+		},
+		ts.AddToBanList.HandleCalls(),
+	)
+
+	err = ts.Client.UnbanFeed(context.Background(), feed)
+	require.NoError(t, err)
+
+	require.Equal(t,
+		[]scuttlegocommands.RemoveFromBanList{
+			mustNewRemoveFromBanList(hash),
+		},
+		ts.RemoveFromBanList.HandleCalls(),
+	)
+}
+
+func TestServer_Status(t *testing.T) {
+	ts := newTestServer(t, "example.com:8008")
+
+	startedAt := time.Date(2030, 1, 2, 3, 4, 5, 0, time.UTC)
+	connectedAt := startedAt.Add(time.Hour)
+	trackedPeer := fixtures.SomePublicIdentity()
+	untrackedPeer := fixtures.SomePublicIdentity()
+	blobStorageBytes := int64(30)
+
+	ts.Status.HandleReturnValue = queries.Status{
+		StartedAt: startedAt,
+		Peers: []queries.PeerStatus{
+			{
+				Identity: trackedPeer,
+				Connection: &queries.Connection{
+					Identity:          trackedPeer,
+					Address:           "192.0.2.1:8008",
+					ConnectedAt:       connectedAt,
+					InitiatedByRemote: true,
+				},
+			},
+			{
+				Identity: untrackedPeer,
+			},
+		},
+		NumberOfFeeds:    10,
+		NumberOfMessages: 20,
+		BlobStorageBytes: &blobStorageBytes,
+		Invites: queries.InviteCounts{
+			Created:  1,
+			Redeemed: 2,
+			Rejected: 3,
+		},
+	}
+
+	result, err := ts.Client.Status(context.Background())
+	require.NoError(t, err)
+
+	require.Equal(t,
+		admin.StatusResult{
+			Identity:        refs.MustNewIdentityFromPublic(ts.Local.Public()).String(),
+			ListenAddresses: testListenAddresses,
+			PublicAddress:   "example.com:8008",
+			StartedAt:       startedAt,
+			Peers: []admin.PeerStatus{
+				{
+					Identity: refs.MustNewIdentityFromPublic(trackedPeer).String(),
+					Connection: &admin.ConnectionStatus{
+						Address:           "192.0.2.1:8008",
+						ConnectedAt:       connectedAt,
+						InitiatedByRemote: true,
+					},
+				},
+				{
+					Identity: refs.MustNewIdentityFromPublic(untrackedPeer).String(),
+				},
+			},
+			NumberOfFeeds:    10,
+			NumberOfMessages: 20,
+			BlobStorageBytes: &blobStorageBytes,
+			Invites: admin.InviteCounts{
+				Created:  1,
+				Redeemed: 2,
+				Rejected: 3,
+			},
+		},
+		result,
+	)
+}
+
+func TestServer_Connect(t *testing.T) {
+	ts := newTestServer(t, "")
+
+	remote := fixtures.SomePublicIdentity()
+	address := "net:example.com:8008~shs:" + base64.StdEncoding.EncodeToString(remote.PublicKey())
+
+	err := ts.Client.Connect(context.Background(), address)
+	require.NoError(t, err)
+
+	require.Equal(t,
+		[]scuttlegocommands.Connect{
+			{
+				Remote:  remote,
+				Address: network.NewAddress("example.com:8008"),
+			},
+		},
+		ts.Connect.HandleCalls(),
+	)
+}
+
+func TestServer_ConnectionsAreNotTiedToTheRequest(t *testing.T) {
+	ts := newTestServer(t, "")
+
+	remote := fixtures.SomePublicIdentity()
+	address := "net:example.com:8008~shs:" + base64.StdEncoding.EncodeToString(remote.PublicKey())
+
+	err := ts.Client.Connect(context.Background(), address)
+	require.NoError(t, err)
+
+	ctxs := ts.Connect.HandleCtxs()
+	require.Len(t, ctxs, 1)
+
+	require.Never(t, func() bool {
+		return ctxs[0].Err() != nil
+	}, 100*time.Millisecond, 10*time.Millisecond)
+}
+
+func TestServer_ConnectReturnsErrorsOfInvalidAddresses(t *testing.T) {
+	ts := newTestServer(t, "")
+
+	err := ts.Client.Connect(context.Background(), "example.com:8008")
+	require.ErrorContains(t, err, "invalid address")
+
+	require.Empty(t, ts.Connect.HandleCalls())
+}
+
+func TestServer_Disconnect(t *testing.T) {
+	ts := newTestServer(t, "")
+
+	feed := fixtures.SomeRefFeed()
+
+	err := ts.Client.Disconnect(context.Background(), feed)
+	require.NoError(t, err)
+
+	require.Equal(t,
+		[]commands.Disconnect{
+			mustNewDisconnect(feed.Identity()),
+		},
+		ts.Disconnect.HandleCalls(),
+	)
+}
+
+func TestServer_DisconnectReturnsErrors(t *testing.T) {
+	ts := newTestServer(t, "")
+	ts.Disconnect.HandleReturnValue = commands.ErrPeerNotConnected
+
+	err := ts.Client.Disconnect(context.Background(), fixtures.SomeRefFeed())
+	require.ErrorContains(t, err, commands.ErrPeerNotConnected.Error())
+}
+
+func TestServer_DisconnectAll(t *testing.T) {
+	ts := newTestServer(t, "")
+
+	err := ts.Client.DisconnectAll(context.Background())
+	require.NoError(t, err)
+
+	require.Equal(t, 1, ts.DisconnectAll.HandleCalls())
+}
+
+func TestServer_DisconnectAllReturnsErrors(t *testing.T) {
+	ts := newTestServer(t, "")
+	ts.DisconnectAll.HandleReturnValue = errors.New("some error")
+
+	err := ts.Client.DisconnectAll(context.Background())
+	require.ErrorContains(t, err, "some error")
+}
+
+func TestServer_ListPeers(t *testing.T) {
+	ts := newTestServer(t, "")
+
+	connectedAt := time.Date(2030, 1, 2, 3, 4, 5, 0, time.UTC)
+	peer := fixtures.SomePublicIdentity()
+
+	ts.ConnectedPeers.HandleReturnValue = []queries.PeerStatus{
+		{
+			Identity: peer,
+			Connection: &queries.Connection{
+				Identity:          peer,
+				Address:           "192.0.2.1:8008",
+				ConnectedAt:       connectedAt,
+				InitiatedByRemote: false,
+			},
+		},
+	}
+
+	result, err := ts.Client.ListPeers(context.Background())
+	require.NoError(t, err)
+
+	require.Equal(t,
+		admin.ListPeersResult{
+			Peers: []admin.PeerStatus{
+				{
+					Identity: refs.MustNewIdentityFromPublic(peer).String(),
+					Connection: &admin.ConnectionStatus{
+						Address:           "192.0.2.1:8008",
+						ConnectedAt:       connectedAt,
+						InitiatedByRemote: false,
+					},
+				},
+			},
+		},
+		result,
+	)
+}
+
+func TestServer_SocketIsAccessibleOnlyToTheOwner(t *testing.T) {
+	ts := newTestServer(t, "")
+
+	info, err := os.Stat(ts.Path)
+	require.NoError(t, err)
+	require.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+}
+
+func TestServer_StaleSocketIsRemoved(t *testing.T) {
+	path := filepath.Join(fixtures.Directory(t), "admin.sock")
+
+	listener, err := net.Listen("unix", path)
+	require.NoError(t, err)
+	listener.(*net.UnixListener).SetUnlinkOnClose(false)
+	require.NoError(t, listener.Close())
+
+	ts := newTestServerWithPath(t, path, "")
+
+	_, err = ts.Client.CreateInvite(context.Background(), admin.CreateInviteParams{})
+	require.NoError(t, err)
+}
+
+func TestServer_RefusesToRemoveFilesWhichArentSockets(t *testing.T) {
+	path := filepath.Join(fixtures.Directory(t), "admin.sock")
+
+	err := os.WriteFile(path, nil, 0o600)
+	require.NoError(t, err)
+
+	server := admin.NewServer(
+		path,
+		fixtures.SomePublicIdentity(),
+		nil,
+		"",
+		mocks.NewCreateInviteHandlerMock(),
+		mocks.NewAddToBanListHandlerMock(),
+		mocks.NewRemoveFromBanListHandlerMock(),
+		adapters.NewBanListHasher(),
+		mocks.NewStatusHandlerMock(),
+		mocks.NewConnectHandlerMock(),
+		mocks.NewDisconnectHandlerMock(),
+		mocks.NewDisconnectAllHandlerMock(),
+		mocks.NewConnectedPeersHandlerMock(),
+		logging.NewDevNullLogger(),
+	)
+
+	err = server.Run(context.Background())
+	require.ErrorContains(t, err, "isn't a socket")
+}
+
+func TestServer_RefusesToRemoveSocketsWhichAreInUse(t *testing.T) {
+	path := filepath.Join(fixtures.Directory(t), "admin.sock")
+
+	listener, err := net.Listen("unix", path)
+	require.NoError(t, err)
+	defer listener.Close()
+
+	server := admin.NewServer(
+		path,
+		fixtures.SomePublicIdentity(),
+		nil,
+		"",
+		mocks.NewCreateInviteHandlerMock(),
+		mocks.NewAddToBanListHandlerMock(),
+		mocks.NewRemoveFromBanListHandlerMock(),
+		adapters.NewBanListHasher(),
+		mocks.NewStatusHandlerMock(),
+		mocks.NewConnectHandlerMock(),
+		mocks.NewDisconnectHandlerMock(),
+		mocks.NewDisconnectAllHandlerMock(),
+		mocks.NewConnectedPeersHandlerMock(),
+		logging.NewDevNullLogger(),
+	)
+
+	err = server.Run(context.Background())
+	require.ErrorContains(t, err, "another instance is running")
+
+	_, err = os.Stat(path)
+	require.NoError(t, err)
+}
+
+func TestClient_ReturnsAnErrorIfThePubIsntRunning(t *testing.T) {
+	path := filepath.Join(fixtures.Directory(t), "admin.sock")
+
+	client := admin.NewClient(path)
+
+	_, err := client.CreateInvite(context.Background(), admin.CreateInviteParams{})
+	require.ErrorContains(t, err, "is the pub running?")
+}
+
+var testListenAddresses = []string{":8008"}
+
+type testServer struct {
+	Path   string
+	Client *admin.Client
+	Local  identity.Private
+
+	CreateInvite      *mocks.CreateInviteHandlerMock
+	AddToBanList      *mocks.AddToBanListHandlerMock
+	RemoveFromBanList *mocks.RemoveFromBanListHandlerMock
+	Status            *mocks.StatusHandlerMock
+	Connect           *mocks.ConnectHandlerMock
+	Disconnect        *mocks.DisconnectHandlerMock
+	DisconnectAll     *mocks.DisconnectAllHandlerMock
+	ConnectedPeers    *mocks.ConnectedPeersHandlerMock
+}
+
+func newTestServer(t *testing.T, publicAddress string) testServer {
+	return newTestServerWithPath(t, filepath.Join(fixtures.Directory(t), "admin.sock"), publicAddress)
+}
+
+func newTestServerWithPath(t *testing.T, path string, publicAddress string) testServer {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	ts := testServer{
+		Path:   path,
+		Client: admin.NewClient(path),
+		Local:  fixtures.SomePrivateIdentity(),
+
+		CreateInvite:      mocks.NewCreateInviteHandlerMock(),
+		AddToBanList:      mocks.NewAddToBanListHandlerMock(),
+		RemoveFromBanList: mocks.NewRemoveFromBanListHandlerMock(),
+		Status:            mocks.NewStatusHandlerMock(),
+		Connect:           mocks.NewConnectHandlerMock(),
+		Disconnect:        mocks.NewDisconnectHandlerMock(),
+		DisconnectAll:     mocks.NewDisconnectAllHandlerMock(),
+		ConnectedPeers:    mocks.NewConnectedPeersHandlerMock(),
+	}
+
+	server := admin.NewServer(
+		path,
+		ts.Local.Public(),
+		testListenAddresses,
+		publicAddress,
+		ts.CreateInvite,
+		ts.AddToBanList,
+		ts.RemoveFromBanList,
+		adapters.NewBanListHasher(),
+		ts.Status,
+		ts.Connect,
+		ts.Disconnect,
+		ts.DisconnectAll,
+		ts.ConnectedPeers,
+		logging.NewDevNullLogger(),
+	)
+
+	errCh := make(chan error)
+	go func() {
+		errCh <- server.Run(ctx)
+	}()
+
+	t.Cleanup(func() {
+		cancel()
+		require.ErrorIs(t, <-errCh, context.Canceled)
+	})
+
+	require.Eventually(t, func() bool {
+		conn, err := net.Dial("unix", path)
+		if err != nil {
+			return false
+		}
+		conn.Close()
+		return true
+	}, 1*time.Second, 10*time.Millisecond)
+
+	return ts
+}
+
+func mustNewCreateInvite(numberOfUses *int, validUntil *time.Time) commands.CreateInvite {
+	cmd, err := commands.NewCreateInvite(numberOfUses, validUntil)
+	if err != nil {
+		panic(err)
+	}
+	return cmd
+}
+
+func mustNewDisconnect(remote identity.Public) commands.Disconnect {
+	cmd, err := commands.NewDisconnect(remote)
+	if err != nil {
+		panic(err)
+	}
+	return cmd
+}
+
+func mustNewAddToBanList(hash bans.Hash) scuttlegocommands.AddToBanList {
+	cmd, err := scuttlegocommands.NewAddToBanList(hash)
+	if err != nil {
+		panic(err)
+	}
+	return cmd
+}
+
+func mustNewRemoveFromBanList(hash bans.Hash) scuttlegocommands.RemoveFromBanList {
+	cmd, err := scuttlegocommands.NewRemoveFromBanList(hash)
+	if err != nil {
+		panic(err)
+	}
+	return cmd
+}
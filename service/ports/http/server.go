@@ -0,0 +1,110 @@
+package http
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/boreq/errors"
+	"github.com/planetary-social/scuttlego/logging"
+)
+
+const (
+	readHeaderTimeout = 10 * time.Second
+	shutdownTimeout   = 5 * time.Second
+)
+
+const metricsContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+type ReadinessChecker interface {
+	Check() error
+}
+
+type MetricsWriter interface {
+	// WriteMetrics writes the metrics in the Prometheus text exposition
+	// format. If an error is returned the metrics which could be determined
+	// may have been written anyway.
+	WriteMetrics(w io.Writer) error
+}
+
+// Server serves the health check and metrics endpoints. /healthz responds as
+// long as the process is alive, /readyz responds with an error if the pub
+// isn't ready and /metrics returns the metrics in the Prometheus format.
+type Server struct {
+	address string
+	handler http.Handler
+	logger  logging.Logger
+}
+
+func NewServer(address string, readiness ReadinessChecker, metrics MetricsWriter, logger logging.Logger) *Server {
+	logger = logger.New("http_server")
+	return &Server{
+		address: address,
+		handler: NewHandler(readiness, metrics, logger),
+		logger:  logger,
+	}
+}
+
+// Start listens on the address and then serves requests in the background
+// until the context is cancelled.
+func (s *Server) Start(ctx context.Context) error {
+	listener, err := net.Listen("tcp", s.address)
+	if err != nil {
+		return errors.Wrap(err, "error listening")
+	}
+
+	server := &http.Server{
+		Handler:           s.handler,
+		ReadHeaderTimeout: readHeaderTimeout,
+	}
+
+	go func() {
+		<-ctx.Done()
+
+		ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer cancel()
+
+		if err := server.Shutdown(ctx); err != nil {
+			s.logger.Error().WithError(err).Message("error shutting down the server")
+		}
+	}()
+
+	go func() {
+		if err := server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			s.logger.Error().WithError(err).Message("error serving requests")
+		}
+	}()
+
+	return nil
+}
+
+func NewHandler(readiness ReadinessChecker, metrics MetricsWriter, logger logging.Logger) http.Handler {
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintln(w, "ok")
+	})
+
+	mux.HandleFunc("/readyz", func(w http.ResponseWriter, r *http.Request) {
+		if err := readiness.Check(); err != nil {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			fmt.Fprintln(w, err)
+			return
+		}
+		fmt.Fprintln(w, "ok")
+	})
+
+	mux.HandleFunc("/metrics", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", metricsContentType)
+
+		// partial results are still useful so the error is only logged
+		if err := metrics.WriteMetrics(w); err != nil {
+			logger.Error().WithError(err).Message("error writing metrics")
+		}
+	})
+
+	return mux
+}
@@ -0,0 +1,84 @@
+package http_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/planetary-social/scuttlego-pub/internal/mocks"
+	httpport "github.com/planetary-social/scuttlego-pub/service/ports/http"
+	"github.com/planetary-social/scuttlego/logging"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandler(t *testing.T) {
+	testCases := []struct {
+		Name           string
+		Path           string
+		ReadinessErr   error
+		MetricsErr     error
+		ExpectedStatus int
+		ExpectedBody   string
+	}{
+		{
+			Name:           "healthz",
+			Path:           "/healthz",
+			ReadinessErr:   errors.New("some error"),
+			ExpectedStatus: http.StatusOK,
+			ExpectedBody:   "ok\n",
+		},
+		{
+			Name:           "readyz_ready",
+			Path:           "/readyz",
+			ReadinessErr:   nil,
+			ExpectedStatus: http.StatusOK,
+			ExpectedBody:   "ok\n",
+		},
+		{
+			Name:           "readyz_not_ready",
+			Path:           "/readyz",
+			ReadinessErr:   errors.New("some error"),
+			ExpectedStatus: http.StatusServiceUnavailable,
+			ExpectedBody:   "some error\n",
+		},
+		{
+			Name:           "metrics",
+			Path:           "/metrics",
+			ExpectedStatus: http.StatusOK,
+			ExpectedBody:   "some_metric 1\n",
+		},
+		{
+			Name:           "metrics_with_errors",
+			Path:           "/metrics",
+			MetricsErr:     errors.New("some error"),
+			ExpectedStatus: http.StatusOK,
+			ExpectedBody:   "some_metric 1\n",
+		},
+		{
+			Name:           "unknown_path",
+			Path:           "/unknown",
+			ExpectedStatus: http.StatusNotFound,
+			ExpectedBody:   "404 page not found\n",
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			readiness := mocks.NewReadinessCheckerMock()
+			readiness.CheckReturnValue = testCase.ReadinessErr
+
+			metrics := mocks.NewMetricsWriterMock()
+			metrics.Metrics = "some_metric 1\n"
+			metrics.WriteMetricsReturnValue = testCase.MetricsErr
+
+			handler := httpport.NewHandler(readiness, metrics, logging.NewDevNullLogger())
+
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, testCase.Path, nil))
+
+			require.Equal(t, testCase.ExpectedStatus, recorder.Code)
+			require.Equal(t, testCase.ExpectedBody, recorder.Body.String())
+		})
+	}
+}
@@ -0,0 +1,70 @@
+// Package network handles incoming network connections.
+package network
+
+import (
+	"context"
+	"net"
+
+	"github.com/boreq/errors"
+	"github.com/planetary-social/scuttlego/logging"
+	portsnetwork "github.com/planetary-social/scuttlego/service/ports/network"
+)
+
+// Listener handles incoming TCP connections initiated by other peers in the
+// same way as the listener from scuttlego but reports once it is listening so
+// that the pub isn't reported as ready before it can accept connections.
+type Listener struct {
+	initializer portsnetwork.ServerPeerInitializer
+	address     string
+	logger      logging.Logger
+}
+
+// NewListener creates a new listener which listens on the provided address.
+// The address should be formatted in the way which can be handled by the net
+// package e.g. ":8008".
+func NewListener(
+	initializer portsnetwork.ServerPeerInitializer,
+	address string,
+	logger logging.Logger,
+) *Listener {
+	return &Listener{
+		initializer: initializer,
+		address:     address,
+		logger:      logger.New("listener"),
+	}
+}
+
+// ListenAndServe starts listening, calls listening and then keeps accepting
+// connections and initializing them until the context is cancelled.
+func (l *Listener) ListenAndServe(ctx context.Context, listening func()) error {
+	var lc net.ListenConfig
+	listener, err := lc.Listen(ctx, "tcp", l.address)
+	if err != nil {
+		return errors.Wrap(err, "could not start a listener")
+	}
+
+	go func() {
+		<-ctx.Done()
+		if err := listener.Close(); err != nil {
+			l.logger.Error().WithError(err).Message("error closing the listener")
+		}
+	}()
+
+	listening()
+
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			return errors.Wrap(err, "could not accept a connection")
+		}
+
+		go l.handleNewConnection(ctx, conn)
+	}
+}
+
+func (l *Listener) handleNewConnection(ctx context.Context, conn net.Conn) {
+	if _, err := l.initializer.InitializeServerPeer(ctx, conn); err != nil {
+		conn.Close()
+		l.logger.Debug().WithError(err).Message("could not init a peer")
+	}
+}
@@ -0,0 +1,60 @@
+package network_test
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/planetary-social/scuttlego-pub/internal/mocks"
+	"github.com/planetary-social/scuttlego-pub/service/ports/network"
+	"github.com/planetary-social/scuttlego/logging"
+	"github.com/stretchr/testify/require"
+)
+
+func TestListener_ReportsThatItIsListeningOnlyAfterBinding(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	occupied, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer occupied.Close()
+
+	listener := network.NewListener(mocks.NewPeerInitializerMock(), occupied.Addr().String(), logging.NewDevNullLogger())
+
+	err = listener.ListenAndServe(ctx, func() {
+		t.Fatal("listening shouldn't be called if binding failed")
+	})
+	require.ErrorContains(t, err, "could not start a listener")
+}
+
+func TestListener_ReportsThatItIsListeningAndStopsWhenTheContextIsCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	initializer := mocks.NewPeerInitializerMock()
+	listener := network.NewListener(initializer, "127.0.0.1:0", logging.NewDevNullLogger())
+
+	listening := make(chan struct{})
+	errCh := make(chan error)
+	go func() {
+		errCh <- listener.ListenAndServe(ctx, func() {
+			close(listening)
+		})
+	}()
+
+	select {
+	case <-listening:
+	case <-time.After(time.Second):
+		t.Fatal("timeout")
+	}
+
+	cancel()
+
+	select {
+	case err := <-errCh:
+		require.Error(t, err)
+	case <-time.After(time.Second):
+		t.Fatal("timeout")
+	}
+}
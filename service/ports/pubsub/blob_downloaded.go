@@ -0,0 +1,37 @@
+package pubsub
+
+import (
+	"context"
+
+	"github.com/planetary-social/scuttlego/service/app/queries"
+	"github.com/planetary-social/scuttlego/service/domain/blobs"
+)
+
+type BlobDownloadedMetrics interface {
+	BlobDownloaded(size blobs.Size)
+}
+
+// BlobDownloadedSubscriber reports blobs downloaded from other peers.
+type BlobDownloadedSubscriber struct {
+	subscriber queries.BlobDownloadedSubscriber
+	metrics    BlobDownloadedMetrics
+}
+
+func NewBlobDownloadedSubscriber(
+	subscriber queries.BlobDownloadedSubscriber,
+	metrics BlobDownloadedMetrics,
+) *BlobDownloadedSubscriber {
+	return &BlobDownloadedSubscriber{
+		subscriber: subscriber,
+		metrics:    metrics,
+	}
+}
+
+// Run keeps receiving events from the pubsub until the context is closed.
+func (p *BlobDownloadedSubscriber) Run(ctx context.Context) error {
+	for event := range p.subscriber.Subscribe(ctx) {
+		p.metrics.BlobDownloaded(event.Size)
+	}
+
+	return nil
+}
@@ -0,0 +1,93 @@
+package service
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/boreq/errors"
+)
+
+// writabilityCheckInterval limits how often the database is written to by
+// readiness probes.
+const writabilityCheckInterval = 10 * time.Second
+
+type DatabaseWritabilityChecker interface {
+	CheckWritable() error
+}
+
+// Readiness checks if the pub is ready to replicate with peers. The pub is
+// ready once the migrations finished, all runners started by Service.Run are
+// ready and the database is writable.
+type Readiness struct {
+	supervisor               *Supervisor
+	database                 DatabaseWritabilityChecker
+	writabilityCheckInterval time.Duration
+
+	migrationsFinished atomic.Bool
+
+	writabilityLock      sync.Mutex
+	writabilityCheckedAt time.Time
+	writabilityErr       error
+}
+
+func NewReadiness(supervisor *Supervisor, database DatabaseWritabilityChecker) *Readiness {
+	return NewReadinessWithWritabilityCheckInterval(writabilityCheckInterval, supervisor, database)
+}
+
+// NewReadinessWithWritabilityCheckInterval creates readiness which reuses the
+// result of checking if the database is writable until the interval passes.
+func NewReadinessWithWritabilityCheckInterval(interval time.Duration, supervisor *Supervisor, database DatabaseWritabilityChecker) *Readiness {
+	return &Readiness{
+		supervisor:               supervisor,
+		database:                 database,
+		writabilityCheckInterval: interval,
+	}
+}
+
+func (r *Readiness) MarkMigrationsFinished() {
+	r.migrationsFinished.Store(true)
+}
+
+// Check returns an error describing why the pub isn't ready or nil if it is.
+func (r *Readiness) Check() error {
+	if !r.migrationsFinished.Load() {
+		return errors.New("migrations haven't finished yet")
+	}
+
+	statuses := r.supervisor.Statuses()
+	if len(statuses) == 0 {
+		return errors.New("runners haven't been started yet")
+	}
+
+	for _, status := range statuses {
+		if !status.Running {
+			return fmt.Errorf("runner '%s' isn't running", status.Name)
+		}
+
+		if !status.Ready {
+			return fmt.Errorf("runner '%s' isn't ready", status.Name)
+		}
+	}
+
+	if err := r.checkWritable(); err != nil {
+		return errors.Wrap(err, "database isn't writable")
+	}
+
+	return nil
+}
+
+// checkWritable writes to the database at most once per interval as
+// readiness is checked frequently.
+func (r *Readiness) checkWritable() error {
+	r.writabilityLock.Lock()
+	defer r.writabilityLock.Unlock()
+
+	if r.writabilityCheckedAt.IsZero() || time.Since(r.writabilityCheckedAt) >= r.writabilityCheckInterval {
+		r.writabilityErr = r.database.CheckWritable()
+		r.writabilityCheckedAt = time.Now()
+	}
+
+	return r.writabilityErr
+}
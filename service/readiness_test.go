@@ -0,0 +1,121 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/planetary-social/scuttlego-pub/internal/mocks"
+	"github.com/planetary-social/scuttlego-pub/service"
+	"github.com/planetary-social/scuttlego/logging"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadiness(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	supervisor := service.NewSupervisorWithBackoff(time.Millisecond, 10*time.Millisecond, logging.NewDevNullLogger())
+	database := mocks.NewDatabaseWritabilityCheckerMock()
+	readiness := service.NewReadinessWithWritabilityCheckInterval(0, supervisor, database)
+
+	require.EqualError(t, readiness.Check(), "migrations haven't finished yet")
+
+	readiness.MarkMigrationsFinished()
+
+	require.EqualError(t, readiness.Check(), "runners haven't been started yet")
+
+	go func() {
+		_ = supervisor.Supervise(ctx, service.Runner{
+			Name: "some_runner",
+			Run: func(ctx context.Context) error {
+				<-ctx.Done()
+				return nil
+			},
+		})
+	}()
+
+	require.Eventually(t, func() bool {
+		return readiness.Check() == nil
+	}, time.Second, time.Millisecond)
+
+	database.CheckWritableReturnValue = errors.New("some error")
+	require.EqualError(t, readiness.Check(), "database isn't writable: some error")
+	database.CheckWritableReturnValue = nil
+
+	cancel()
+
+	require.Eventually(t, func() bool {
+		err := readiness.Check()
+		return err != nil && err.Error() == "runner 'some_runner' isn't running"
+	}, time.Second, time.Millisecond)
+}
+
+func TestReadiness_RunnersWhichReportReadyAreWaitedFor(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	supervisor := service.NewSupervisorWithBackoff(time.Millisecond, 10*time.Millisecond, logging.NewDevNullLogger())
+	readiness := service.NewReadiness(supervisor, mocks.NewDatabaseWritabilityCheckerMock())
+	readiness.MarkMigrationsFinished()
+
+	runner := service.Runner{
+		Name:         "some_listener",
+		ReportsReady: true,
+		Run: func(ctx context.Context) error {
+			<-ctx.Done()
+			return nil
+		},
+	}
+
+	supervisor.Register(runner)
+	require.EqualError(t, readiness.Check(), "runner 'some_listener' isn't running")
+
+	go func() {
+		_ = supervisor.Supervise(ctx, runner)
+	}()
+
+	require.Eventually(t, func() bool {
+		err := readiness.Check()
+		return err != nil && err.Error() == "runner 'some_listener' isn't ready"
+	}, time.Second, time.Millisecond)
+
+	supervisor.MarkReady("some_listener")
+	require.NoError(t, readiness.Check())
+}
+
+func TestReadiness_WritabilityCheckResultIsReused(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	supervisor := service.NewSupervisorWithBackoff(time.Millisecond, 10*time.Millisecond, logging.NewDevNullLogger())
+	database := mocks.NewDatabaseWritabilityCheckerMock()
+	readiness := service.NewReadinessWithWritabilityCheckInterval(100*time.Millisecond, supervisor, database)
+	readiness.MarkMigrationsFinished()
+
+	go func() {
+		_ = supervisor.Supervise(ctx, service.Runner{
+			Name: "some_runner",
+			Run: func(ctx context.Context) error {
+				<-ctx.Done()
+				return nil
+			},
+		})
+	}()
+
+	require.Eventually(t, func() bool {
+		return readiness.Check() == nil
+	}, time.Second, time.Millisecond)
+
+	database.CheckWritableReturnValue = errors.New("some error")
+
+	for i := 0; i < 10; i++ {
+		require.NoError(t, readiness.Check())
+	}
+	require.Equal(t, 1, database.CheckWritableCalls)
+
+	<-time.After(100 * time.Millisecond)
+	require.EqualError(t, readiness.Check(), "database isn't writable: some error")
+	require.Equal(t, 2, database.CheckWritableCalls)
+}
@@ -2,11 +2,21 @@ package service
 
 import (
 	"context"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"strconv"
+	"time"
 
 	"github.com/boreq/errors"
-	"github.com/hashicorp/go-multierror"
 	"github.com/planetary-social/scuttlego-pub/service/app"
-	"github.com/planetary-social/scuttlego/service/adapters/badger"
+	pubcommands "github.com/planetary-social/scuttlego-pub/service/app/commands"
+	adminport "github.com/planetary-social/scuttlego-pub/service/ports/admin"
+	httpport "github.com/planetary-social/scuttlego-pub/service/ports/http"
+	pubnetworkport "github.com/planetary-social/scuttlego-pub/service/ports/network"
+	pubpubsubport "github.com/planetary-social/scuttlego-pub/service/ports/pubsub"
+	"github.com/planetary-social/scuttlego/logging"
 	"github.com/planetary-social/scuttlego/service/app/commands"
 	"github.com/planetary-social/scuttlego/service/app/queries"
 	"github.com/planetary-social/scuttlego/service/domain/network/local"
@@ -14,50 +24,127 @@ import (
 	pubsubport "github.com/planetary-social/scuttlego/service/ports/pubsub"
 )
 
+const (
+	preferredPeersStatusReportInterval = 1 * time.Minute
+	publishPendingWelcomesInterval     = 1 * time.Minute
+	drainPollInterval                  = 100 * time.Millisecond
+)
+
+type LogLevelSetter interface {
+	SetLevel(level LogLevel) error
+}
+
+type PeerConnections interface {
+	// CloseAll closes all connections with peers.
+	CloseAll()
+}
+
+type BadgerGarbageCollector interface {
+	// Run collects garbage until the context is cancelled.
+	Run(ctx context.Context) error
+}
+
+type MetricsExporter interface {
+	// Run prepares the metrics for exporting and blocks until the context is
+	// cancelled.
+	Run(ctx context.Context) error
+}
+
+type BlobStorageUsage interface {
+	// Run periodically determines the size of the stored blobs until the
+	// context is cancelled.
+	Run(ctx context.Context) error
+}
+
 type Service struct {
 	App app.Application
 
+	config         Config
+	currentConfig  *CurrentConfig
+	logLevelSetter LogLevelSetter
+	logger         logging.Logger
+	supervisor     *Supervisor
+	readiness      *Readiness
+	httpServer     *httpport.Server
+	adminServer    *adminport.Server
+	connections    PeerConnections
+
 	runMigrationsHandler *commands.RunMigrationsHandler
 
-	listener                     *networkport.Listener
+	listeners                    []*pubnetworkport.Listener
 	discoverer                   *networkport.Discoverer
 	connectionEstablisher        *networkport.ConnectionEstablisher
+	newPeerSubscriber            *pubsubport.NewPeerSubscriber
 	requestSubscriber            *pubsubport.RequestSubscriber
 	roomAttendantEventSubscriber *pubsubport.RoomAttendantEventSubscriber
-	advertiser                   *local.Advertiser
+	blobDownloadedSubscriber     *pubpubsubport.BlobDownloadedSubscriber
+	advertisers                  []*local.Advertiser
 	messageBuffer                *commands.MessageBuffer
 	createHistoryStreamHandler   *queries.CreateHistoryStreamHandler
-	badgerGarbageCollector       *badger.GarbageCollector
+	badgerGarbageCollector       BadgerGarbageCollector
+	metricsExporter              MetricsExporter
+	blobStorageUsage             BlobStorageUsage
+
+	runners []Runner
 }
 
 func NewService(
 	app app.Application,
+	config Config,
+	currentConfig *CurrentConfig,
+	logLevelSetter LogLevelSetter,
+	logger logging.Logger,
+	supervisor *Supervisor,
+	readiness *Readiness,
+	httpServer *httpport.Server,
+	adminServer *adminport.Server,
+	connections PeerConnections,
 	runMigrationsHandler *commands.RunMigrationsHandler,
-	listener *networkport.Listener,
+	listeners []*pubnetworkport.Listener,
 	discoverer *networkport.Discoverer,
 	connectionEstablisher *networkport.ConnectionEstablisher,
+	newPeerSubscriber *pubsubport.NewPeerSubscriber,
 	requestSubscriber *pubsubport.RequestSubscriber,
 	roomAttendantEventSubscriber *pubsubport.RoomAttendantEventSubscriber,
-	advertiser *local.Advertiser,
+	blobDownloadedSubscriber *pubpubsubport.BlobDownloadedSubscriber,
+	advertisers []*local.Advertiser,
 	messageBuffer *commands.MessageBuffer,
 	createHistoryStreamHandler *queries.CreateHistoryStreamHandler,
-	badgerGarbageCollector *badger.GarbageCollector,
+	badgerGarbageCollector BadgerGarbageCollector,
+	metricsExporter MetricsExporter,
+	blobStorageUsage BlobStorageUsage,
 ) Service {
-	return Service{
+	s := Service{
 		App: app,
 
+		config:         config,
+		currentConfig:  currentConfig,
+		logLevelSetter: logLevelSetter,
+		logger:         logger.New("service"),
+		supervisor:     supervisor,
+		readiness:      readiness,
+		httpServer:     httpServer,
+		adminServer:    adminServer,
+		connections:    connections,
+
 		runMigrationsHandler: runMigrationsHandler,
 
-		listener:                     listener,
+		listeners:                    listeners,
 		discoverer:                   discoverer,
 		connectionEstablisher:        connectionEstablisher,
+		newPeerSubscriber:            newPeerSubscriber,
 		requestSubscriber:            requestSubscriber,
 		roomAttendantEventSubscriber: roomAttendantEventSubscriber,
-		advertiser:                   advertiser,
+		blobDownloadedSubscriber:     blobDownloadedSubscriber,
+		advertisers:                  advertisers,
 		messageBuffer:                messageBuffer,
 		createHistoryStreamHandler:   createHistoryStreamHandler,
 		badgerGarbageCollector:       badgerGarbageCollector,
+		metricsExporter:              metricsExporter,
+		blobStorageUsage:             blobStorageUsage,
 	}
+	s.runners = s.newRunners()
+	return s
 }
 
 func (s Service) RunMigrations(ctx context.Context) error {
@@ -66,68 +153,353 @@ func (s Service) RunMigrations(ctx context.Context) error {
 		return errors.Wrap(err, "error creating the command")
 	}
 
-	return s.runMigrationsHandler.Run(ctx, cmd)
+	if err := s.runMigrationsHandler.Run(ctx, cmd); err != nil {
+		return errors.Wrap(err, "error running migrations")
+	}
+
+	s.readiness.MarkMigrationsFinished()
+	return nil
+}
+
+// StartHTTPServer starts serving the health check and metrics endpoints in the
+// background until the context is cancelled. It should be called before
+// RunMigrations so that the pub is reported as alive while the migrations are
+// running. The runners started by Run are registered first so that the pub
+// isn't reported as ready before all of them are ready. Nothing happens if the
+// HTTP listener is disabled.
+func (s Service) StartHTTPServer(ctx context.Context) error {
+	s.supervisor.Register(s.runners...)
+
+	// httpServer is nil if the HTTP listener is disabled
+	if s.httpServer == nil {
+		return nil
+	}
+	return s.httpServer.Start(ctx)
+}
+
+// AnnouncePub publishes a pub message containing the configured public
+// address if it differs from the last announced one.
+func (s Service) AnnouncePub() error {
+	if s.config.PublicAddress == "" {
+		return nil
+	}
+
+	host, portString, err := net.SplitHostPort(s.config.PublicAddress)
+	if err != nil {
+		return errors.Wrap(err, "error splitting the public address")
+	}
+
+	port, err := strconv.Atoi(portString)
+	if err != nil {
+		return errors.Wrap(err, "error parsing the port")
+	}
+
+	cmd, err := pubcommands.NewAnnouncePub(host, port)
+	if err != nil {
+		return errors.Wrap(err, "error creating the command")
+	}
+
+	published, err := s.App.Commands.AnnouncePub.Handle(cmd)
+	if err != nil {
+		return errors.Wrap(err, "error announcing the pub")
+	}
+
+	if published {
+		s.logger.Debug().WithField("address", s.config.PublicAddress).Message("published a pub announcement")
+	}
+
+	return nil
+}
+
+// UpdateProfile publishes an about message containing the configured name,
+// description and image if they differ from the last published ones. Fields
+// which were removed from the config are cleared.
+func (s Service) UpdateProfile() error {
+	var image io.Reader
+	if s.config.ImagePath != "" {
+		f, err := os.Open(s.config.ImagePath)
+		if err != nil {
+			return errors.Wrap(err, "error opening the image")
+		}
+		defer f.Close()
+
+		image = f
+	}
+
+	cmd, err := pubcommands.NewUpdateProfile(s.config.Name, s.config.Description, image)
+	if err != nil {
+		return errors.Wrap(err, "error creating the command")
+	}
+
+	published, err := s.App.Commands.UpdateProfile.Handle(cmd)
+	if err != nil {
+		return errors.Wrap(err, "error updating the profile")
+	}
+
+	if published {
+		s.logger.Debug().Message("published an about message")
+	}
+
+	return nil
+}
+
+// publishOnStartup publishes the pub announcement and the profile. It is
+// called by StartupPublisher once messages from the local feed had a chance to
+// be replicated from other peers.
+func (s Service) publishOnStartup() error {
+	if err := s.AnnouncePub(); err != nil {
+		return errors.Wrap(err, "error announcing the pub")
+	}
+
+	if err := s.UpdateProfile(); err != nil {
+		return errors.Wrap(err, "error updating the profile")
+	}
+
+	return nil
 }
 
+// ReloadConfig applies the fields of the new config which can be changed
+// while the pub is running. Changes to other fields are logged and ignored.
+// The new config should be validated first.
+func (s Service) ReloadConfig(config Config) error {
+	result := s.currentConfig.Reload(config)
+
+	for _, field := range result.Rejected {
+		s.logger.Error().WithField("field", field).Message("this field can't be changed without restarting the pub, ignoring the new value")
+	}
+
+	for _, field := range result.Changed {
+		if field == "LogLevel" {
+			if err := s.logLevelSetter.SetLevel(config.LogLevel); err != nil {
+				return errors.Wrap(err, "error setting the log level")
+			}
+		}
+		s.logger.Debug().WithField("field", field).Message("reloaded config field")
+	}
+
+	return nil
+}
+
+// Run runs the pub until the context is cancelled or one of the critical
+// runners stops. Other runners are restarted if they stop. The pub is then
+// stopped in stages. First the runners which accept new connections or start
+// new work are stopped. Existing connections are then given
+// ShutdownDrainPeriod to finish replication. Finally the connections are
+// closed and the remaining runners are stopped. Run returns only once all
+// runners stopped so that the database can be closed.
 func (s Service) Run(ctx context.Context) error {
-	ctx, cancel := context.WithCancel(ctx)
-	defer cancel()
+	// runners aren't stopped directly by the provided context as they have
+	// to be stopped in stages
+	runnersCtx, stopRunners := context.WithCancel(context.Background())
+	defer stopRunners()
 
-	errCh := make(chan error)
-	runners := 0
+	acceptingCtx, stopAccepting := context.WithCancel(runnersCtx)
+	defer stopAccepting()
 
-	runners++
-	go func() {
-		errCh <- s.listener.ListenAndServe(ctx)
-	}()
+	results := make(chan runnerResult)
+	for _, runner := range s.runners {
+		runner := runner
 
-	runners++
-	go func() {
-		errCh <- s.requestSubscriber.Run(ctx)
-	}()
+		runnerCtx := runnersCtx
+		if runner.Accepting {
+			runnerCtx = acceptingCtx
+		}
 
-	runners++
-	go func() {
-		errCh <- s.roomAttendantEventSubscriber.Run(ctx)
-	}()
+		go func() {
+			results <- runnerResult{
+				Runner: runner,
+				Err:    s.supervisor.Supervise(runnerCtx, runner),
+			}
+		}()
+	}
 
-	runners++
-	go func() {
-		errCh <- s.advertiser.Run(ctx)
-	}()
+	// only the error which caused the pub to stop is returned, runners often
+	// return errors caused by the cancellation of the context while stopping
+	var result error
+	remaining := len(s.runners)
 
-	runners++
-	go func() {
-		errCh <- s.discoverer.Run(ctx)
-	}()
+	select {
+	case r := <-results:
+		result = errors.Wrapf(r.Err, "error returned by runner '%s'", r.Runner.Name)
+		remaining--
+	case <-ctx.Done():
+	}
 
-	runners++
-	go func() {
-		errCh <- s.connectionEstablisher.Run(ctx)
-	}()
+	s.logger.Debug().
+		WithField("drain_period", s.config.ShutdownDrainPeriod).
+		Message("stopping, no longer accepting new connections")
+
+	stopAccepting()
+
+	remaining, drainErr := s.drain(results, remaining)
+	if result == nil {
+		result = drainErr
+	}
 
-	runners++
-	go func() {
-		errCh <- s.messageBuffer.Run(ctx)
-	}()
+	s.logger.Debug().Message("closing connections and stopping the remaining runners")
 
-	runners++
-	go func() {
-		errCh <- s.createHistoryStreamHandler.Run(ctx)
-	}()
+	s.connections.CloseAll()
+	stopRunners()
 
-	runners++
-	go func() {
-		errCh <- s.badgerGarbageCollector.Run(ctx)
-	}()
+	s.waitForRunners(results, remaining)
 
-	var err error
-	for i := 0; i < runners; i++ {
-		err = multierror.Append(err, errors.Wrap(<-errCh, "error returned by runner"))
-		cancel()
+	return result
+}
+
+func (s Service) newRunners() []Runner {
+	var runners []Runner
+
+	for i, listener := range s.listeners {
+		name := fmt.Sprintf("listener_%d", i)
+		listener := listener
+		runners = append(runners, Runner{
+			Name:         name,
+			Critical:     true,
+			Accepting:    true,
+			ReportsReady: true,
+			Run: func(ctx context.Context) error {
+				return listener.ListenAndServe(ctx, func() {
+					s.supervisor.MarkReady(name)
+				})
+			},
+		})
+	}
+
+	runners = append(runners,
+		Runner{Name: "admin_server", Run: s.adminServer.Run},
+		Runner{Name: "new_peer_subscriber", Run: s.newPeerSubscriber.Run},
+		Runner{Name: "request_subscriber", Run: s.requestSubscriber.Run},
+		Runner{Name: "room_attendant_event_subscriber", Run: s.roomAttendantEventSubscriber.Run},
+		Runner{Name: "blob_downloaded_subscriber", Run: s.blobDownloadedSubscriber.Run},
+	)
+
+	for i, advertiser := range s.advertisers {
+		runners = append(runners, Runner{Name: fmt.Sprintf("advertiser_%d", i), Accepting: true, Run: advertiser.Run})
+	}
+
+	// discoverer is nil if local discovery is disabled
+	if s.discoverer != nil {
+		runners = append(runners, Runner{Name: "discoverer", Accepting: true, Run: s.discoverer.Run})
 	}
 
-	return err
+	runners = append(runners,
+		Runner{Name: "connection_establisher", Accepting: true, Run: s.connectionEstablisher.Run},
+		Runner{Name: "message_buffer", Critical: true, Run: s.messageBuffer.Run},
+		Runner{Name: "create_history_stream_handler", Critical: true, Run: s.createHistoryStreamHandler.Run},
+		Runner{Name: "badger_garbage_collector", Critical: true, Run: s.badgerGarbageCollector.Run},
+		Runner{Name: "metrics_exporter", Run: s.metricsExporter.Run},
+		Runner{Name: "blob_storage_usage", Run: s.blobStorageUsage.Run},
+		Runner{Name: "preferred_peers_status_reporter", Run: s.reportPreferredPeersStatus},
+		Runner{Name: "pending_welcomes_publisher", Accepting: true, Run: s.publishPendingWelcomes},
+		Runner{Name: "startup_publisher", Accepting: true, Run: NewStartupPublisher(newStartupPublisherPeers(s.App), s.publishOnStartup, s.logger).Run},
+	)
+
+	return runners
+}
+
+// drain waits until no peers are connected, the drain period passes or a
+// runner which isn't accepting new work stops unexpectedly. Results of the
+// accepting runners which are being stopped are consumed. It returns the
+// number of runners which are still running.
+func (s Service) drain(results <-chan runnerResult, remaining int) (int, error) {
+	drainTimeout := time.NewTimer(s.config.ShutdownDrainPeriod)
+	defer drainTimeout.Stop()
+
+	for {
+		if len(s.App.Queries.ConnectedPeers.Handle()) == 0 {
+			return remaining, nil
+		}
+
+		select {
+		case r := <-results:
+			remaining--
+			if !r.Runner.Accepting {
+				return remaining, errors.Wrapf(r.Err, "runner '%s' stopped while draining", r.Runner.Name)
+			}
+			s.logRunnerStopped(r)
+		case <-time.After(drainPollInterval):
+		case <-drainTimeout.C:
+			s.logger.Debug().Message("drain period passed")
+			return remaining, nil
+		}
+	}
+}
+
+// waitForRunners doesn't give up as the database can't be closed while
+// runners are still using it. Runners which are still running are logged
+// periodically.
+func (s Service) waitForRunners(results <-chan runnerResult, remaining int) {
+	ticker := time.NewTicker(s.config.ShutdownDrainPeriod)
+	defer ticker.Stop()
+
+	for remaining > 0 {
+		select {
+		case r := <-results:
+			remaining--
+			s.logRunnerStopped(r)
+		case <-ticker.C:
+			for _, status := range s.supervisor.Statuses() {
+				if status.Running {
+					s.logger.Error().WithField("runner", status.Name).Message("waiting for the runner to stop")
+				}
+			}
+		}
+	}
+}
+
+func (s Service) logRunnerStopped(r runnerResult) {
+	if r.Err != nil && !errors.Is(r.Err, context.Canceled) {
+		s.logger.Debug().WithError(r.Err).WithField("runner", r.Runner.Name).Message("runner returned an error while stopping")
+	}
+}
+
+// RunnerStatuses returns the statuses of runners started by Run including
+// the number of times they were restarted.
+func (s Service) RunnerStatuses() []RunnerStatus {
+	return s.supervisor.Statuses()
+}
+
+// publishPendingWelcomes periodically publishes the posts greeting new members
+// whose names were replicated since they redeemed an invite.
+func (s Service) publishPendingWelcomes(ctx context.Context) error {
+	for {
+		published, err := s.App.Commands.PublishPendingWelcomes.Handle()
+		if err != nil {
+			s.logger.Error().WithError(err).Message("error publishing pending welcome posts")
+		} else if published > 0 {
+			s.logger.Debug().WithField("published", published).Message("published pending welcome posts")
+		}
+
+		select {
+		case <-time.After(publishPendingWelcomesInterval):
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
+
+// reportPreferredPeersStatus periodically logs if the pub is connected to the
+// preferred peers.
+func (s Service) reportPreferredPeersStatus(ctx context.Context) error {
+	for {
+		for _, status := range s.App.Queries.PreferredPeersStatus.Handle() {
+			s.logger.Debug().
+				WithField("peer", status.Peer.String()).
+				WithField("connected", status.Connected).
+				Message("preferred peer status")
+		}
+
+		select {
+		case <-time.After(preferredPeersStatusReportInterval):
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
+
+type runnerResult struct {
+	Runner Runner
+	Err    error
 }
 
 type noopProgressCallback struct {
@@ -145,3 +517,27 @@ func (n noopProgressCallback) OnError(migrationIndex int, migrationsCount int, e
 
 func (n noopProgressCallback) OnDone(migrationsCount int) {
 }
+
+// startupPublisherPeers reports that the pub is connected to peers once it is
+// connected to all preferred peers or, if there are none, to any peer.
+type startupPublisherPeers struct {
+	app app.Application
+}
+
+func newStartupPublisherPeers(app app.Application) startupPublisherPeers {
+	return startupPublisherPeers{app: app}
+}
+
+func (p startupPublisherPeers) Connected() bool {
+	statuses := p.app.Queries.PreferredPeersStatus.Handle()
+	if len(statuses) == 0 {
+		return len(p.app.Queries.ConnectedPeers.Handle()) > 0
+	}
+
+	for _, status := range statuses {
+		if !status.Connected {
+			return false
+		}
+	}
+	return true
+}
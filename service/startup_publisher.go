@@ -0,0 +1,133 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/boreq/errors"
+	pubcommands "github.com/planetary-social/scuttlego-pub/service/app/commands"
+	"github.com/planetary-social/scuttlego/logging"
+)
+
+const (
+	startupPublisherPollInterval      = 1 * time.Second
+	startupPublisherReplicationPeriod = 30 * time.Second
+	startupPublisherTimeout           = 5 * time.Minute
+	startupPublisherRetryInterval     = 1 * time.Minute
+)
+
+type StartupPublisherPeers interface {
+	// Connected returns true once the pub is connected to the peers from
+	// which messages from the local feed can be replicated.
+	Connected() bool
+}
+
+type StartupPublisherTimings struct {
+	// PollInterval is how often the connections to peers are checked.
+	PollInterval time.Duration
+
+	// ReplicationPeriod is how long the peers are given to send messages
+	// from the local feed once the pub connects to them.
+	ReplicationPeriod time.Duration
+
+	// Timeout is how long the pub waits for peers before publishing anyway.
+	Timeout time.Duration
+
+	// RetryInterval is how long the pub waits before publishing again if
+	// publishing failed.
+	RetryInterval time.Duration
+}
+
+// StartupPublisher publishes messages which the pub publishes when it starts,
+// such as the pub announcement and the profile. Other peers may have messages
+// from the local feed which are missing in the local database, for example
+// after the database was wiped or an old backup was restored. Publishing
+// before they are replicated would fork the local feed so the messages are
+// only published once the pub connected to peers and gave them time to send
+// those messages.
+type StartupPublisher struct {
+	peers   StartupPublisherPeers
+	publish func() error
+	timings StartupPublisherTimings
+	logger  logging.Logger
+}
+
+func NewStartupPublisher(peers StartupPublisherPeers, publish func() error, logger logging.Logger) *StartupPublisher {
+	return NewStartupPublisherWithTimings(
+		StartupPublisherTimings{
+			PollInterval:      startupPublisherPollInterval,
+			ReplicationPeriod: startupPublisherReplicationPeriod,
+			Timeout:           startupPublisherTimeout,
+			RetryInterval:     startupPublisherRetryInterval,
+		},
+		peers,
+		publish,
+		logger,
+	)
+}
+
+func NewStartupPublisherWithTimings(timings StartupPublisherTimings, peers StartupPublisherPeers, publish func() error, logger logging.Logger) *StartupPublisher {
+	return &StartupPublisher{
+		peers:   peers,
+		publish: publish,
+		timings: timings,
+		logger:  logger.New("startup_publisher"),
+	}
+}
+
+// Run waits for replication of the local feed, publishes the messages and
+// then blocks until the context is cancelled. Publishing is retried if it
+// fails, for example because the local feed is behind the copies held by
+// other peers.
+func (p *StartupPublisher) Run(ctx context.Context) error {
+	if err := p.waitForReplication(ctx); err != nil {
+		return nil
+	}
+
+	for {
+		err := p.publish()
+		if err == nil {
+			break
+		}
+
+		if errors.Is(err, pubcommands.ErrLocalFeedIsBehind) {
+			p.logger.Error().WithError(err).Message("local feed is behind, waiting for it to be replicated before publishing")
+		} else {
+			p.logger.Error().WithError(err).Message("error publishing")
+		}
+
+		select {
+		case <-time.After(p.timings.RetryInterval):
+		case <-ctx.Done():
+			return nil
+		}
+	}
+
+	<-ctx.Done()
+	return nil
+}
+
+func (p *StartupPublisher) waitForReplication(ctx context.Context) error {
+	timeout := time.NewTimer(p.timings.Timeout)
+	defer timeout.Stop()
+
+	for !p.peers.Connected() {
+		select {
+		case <-time.After(p.timings.PollInterval):
+		case <-timeout.C:
+			p.logger.Error().Message("not connected to peers, publishing without checking if they have newer messages from the local feed")
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+
+	select {
+	case <-time.After(p.timings.ReplicationPeriod):
+		return nil
+	case <-timeout.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
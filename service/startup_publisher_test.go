@@ -0,0 +1,168 @@
+package service_test
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/boreq/errors"
+	"github.com/planetary-social/scuttlego-pub/internal/fixtures"
+	"github.com/planetary-social/scuttlego-pub/service"
+	pubcommands "github.com/planetary-social/scuttlego-pub/service/app/commands"
+	"github.com/planetary-social/scuttlego-pub/service/di"
+	"github.com/planetary-social/scuttlego/logging"
+	"github.com/planetary-social/scuttlego/service/domain/feeds/message"
+	"github.com/planetary-social/scuttlego/service/domain/refs"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStartupPublisher_PublishesOnlyAfterReplicationPeriod(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	peers := newStartupPublisherPeersMock()
+
+	var publishes atomic.Int64
+	publisher := service.NewStartupPublisherWithTimings(
+		service.StartupPublisherTimings{
+			PollInterval:      time.Millisecond,
+			ReplicationPeriod: 100 * time.Millisecond,
+			Timeout:           time.Minute,
+			RetryInterval:     time.Millisecond,
+		},
+		peers,
+		func() error {
+			publishes.Add(1)
+			return nil
+		},
+		logging.NewDevNullLogger(),
+	)
+
+	go func() {
+		_ = publisher.Run(ctx)
+	}()
+
+	<-time.After(200 * time.Millisecond)
+	require.Equal(t, int64(0), publishes.Load(), "publishing must wait for peers")
+
+	connectedAt := time.Now()
+	peers.SetConnected(true)
+
+	require.Eventually(t, func() bool {
+		return publishes.Load() == 1
+	}, time.Second, time.Millisecond)
+	require.GreaterOrEqual(t, time.Since(connectedAt), 100*time.Millisecond)
+
+	<-time.After(50 * time.Millisecond)
+	require.Equal(t, int64(1), publishes.Load(), "messages should be published only once")
+}
+
+func TestStartupPublisher_PublishesAfterTimeoutIfPeersAreNotConnected(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var publishes atomic.Int64
+	publisher := service.NewStartupPublisherWithTimings(
+		service.StartupPublisherTimings{
+			PollInterval:      time.Millisecond,
+			ReplicationPeriod: time.Minute,
+			Timeout:           100 * time.Millisecond,
+			RetryInterval:     time.Millisecond,
+		},
+		newStartupPublisherPeersMock(),
+		func() error {
+			publishes.Add(1)
+			return nil
+		},
+		logging.NewDevNullLogger(),
+	)
+
+	go func() {
+		_ = publisher.Run(ctx)
+	}()
+
+	require.Eventually(t, func() bool {
+		return publishes.Load() == 1
+	}, time.Second, time.Millisecond)
+}
+
+func TestStartupPublisher_DoesNotForkTheLocalFeedIfTheDatabaseWasWiped(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	ts, err := di.BuildBadgerTestApplication(t)
+	require.NoError(t, err)
+
+	localFeed := refs.MustNewIdentityFromPublic(ts.LocalIdentity.Public()).MainFeed()
+
+	peers := newStartupPublisherPeersMock()
+
+	results := make(chan error, 100)
+	publisher := service.NewStartupPublisherWithTimings(
+		service.StartupPublisherTimings{
+			PollInterval:      time.Millisecond,
+			ReplicationPeriod: 100 * time.Millisecond,
+			Timeout:           time.Minute,
+			RetryInterval:     10 * time.Millisecond,
+		},
+		peers,
+		func() error {
+			cmd, err := pubcommands.NewAnnouncePub("example.com", 8008)
+			if err != nil {
+				return errors.Wrap(err, "error creating the command")
+			}
+
+			published, err := ts.Commands.AnnouncePub.Handle(cmd)
+			if err == nil && !published {
+				err = errors.New("nothing was published")
+			}
+
+			results <- err
+			return err
+		},
+		logging.NewDevNullLogger(),
+	)
+
+	go func() {
+		_ = publisher.Run(ctx)
+	}()
+
+	// the local database is empty but peers hold messages which were
+	// published before it was wiped
+	peers.SetConnected(true)
+	ts.LocalFeedHeadTracker.Report(
+		fixtures.SomePublicIdentity(),
+		fixtures.SomeMessageWithFeedSequence(localFeed, message.MustNewSequence(5)),
+	)
+
+	select {
+	case err := <-results:
+		require.ErrorIs(t, err, pubcommands.ErrLocalFeedIsBehind)
+	case <-time.After(time.Second):
+		t.Fatal("timeout")
+	}
+
+	select {
+	case err := <-results:
+		require.ErrorIs(t, err, pubcommands.ErrLocalFeedIsBehind, "publishing should be retried and refused again")
+	case <-time.After(time.Second):
+		t.Fatal("timeout")
+	}
+}
+
+type startupPublisherPeersMock struct {
+	connected atomic.Bool
+}
+
+func newStartupPublisherPeersMock() *startupPublisherPeersMock {
+	return &startupPublisherPeersMock{}
+}
+
+func (s *startupPublisherPeersMock) Connected() bool {
+	return s.connected.Load()
+}
+
+func (s *startupPublisherPeersMock) SetConnected(connected bool) {
+	s.connected.Store(connected)
+}
@@ -0,0 +1,195 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/boreq/errors"
+	"github.com/planetary-social/scuttlego/logging"
+)
+
+const (
+	minRunnerRestartBackoff = 1 * time.Second
+	maxRunnerRestartBackoff = 1 * time.Minute
+)
+
+// Runner is a long running part of the pub which runs until the context is
+// cancelled.
+type Runner struct {
+	Name string
+
+	// Critical runners stop the whole pub if they stop. Other runners are
+	// restarted.
+	Critical bool
+
+	// Accepting runners accept new connections or start new work. They are
+	// stopped first when the pub is stopping so that the work which is
+	// already in progress can be finished.
+	Accepting bool
+
+	// ReportsReady runners aren't ready until Supervisor.MarkReady is called,
+	// for example listeners which first have to bind to an address. Other
+	// runners are ready once they start.
+	ReportsReady bool
+
+	Run func(ctx context.Context) error
+}
+
+type RunnerStatus struct {
+	Name     string
+	Critical bool
+
+	// Running is false if the runner stopped and is waiting to be restarted
+	// or the pub is stopping.
+	Running bool
+
+	// Ready is true if the runner is running and is ready to do its work.
+	Ready bool
+
+	Restarts int
+}
+
+// Supervisor runs runners and restarts the ones which aren't critical if they
+// stop before the context is cancelled. It is safe for concurrent use.
+type Supervisor struct {
+	minBackoff time.Duration
+	maxBackoff time.Duration
+	logger     logging.Logger
+
+	lock     sync.Mutex
+	statuses map[string]*RunnerStatus
+}
+
+func NewSupervisor(logger logging.Logger) *Supervisor {
+	return NewSupervisorWithBackoff(minRunnerRestartBackoff, maxRunnerRestartBackoff, logger)
+}
+
+// NewSupervisorWithBackoff creates a supervisor which waits for minBackoff
+// before restarting a runner for the first time. The delay is doubled after
+// each consecutive restart up to maxBackoff.
+func NewSupervisorWithBackoff(minBackoff, maxBackoff time.Duration, logger logging.Logger) *Supervisor {
+	return &Supervisor{
+		minBackoff: minBackoff,
+		maxBackoff: maxBackoff,
+		logger:     logger.New("supervisor"),
+		statuses:   make(map[string]*RunnerStatus),
+	}
+}
+
+// Supervise runs the runner until the context is cancelled. If a critical
+// runner stops before that an error is returned. Other runners are restarted
+// with exponential backoff. Once the context is cancelled the error returned
+// by the runner is returned.
+func (s *Supervisor) Supervise(ctx context.Context, runner Runner) error {
+	s.Register(runner)
+
+	backoff := s.minBackoff
+
+	for {
+		started := time.Now()
+
+		s.setRunning(runner.Name, true, !runner.ReportsReady)
+		err := runner.Run(ctx)
+		s.setRunning(runner.Name, false, false)
+
+		if ctx.Err() != nil {
+			return err
+		}
+
+		if runner.Critical {
+			if err == nil {
+				return fmt.Errorf("critical runner '%s' stopped", runner.Name)
+			}
+			return errors.Wrapf(err, "critical runner '%s' failed", runner.Name)
+		}
+
+		// runners which ran for a long time before stopping are most likely
+		// not failing repeatedly
+		if time.Since(started) > s.maxBackoff {
+			backoff = s.minBackoff
+		}
+
+		restarts := s.incrementRestarts(runner.Name)
+
+		s.logger.Error().
+			WithError(err).
+			WithField("runner", runner.Name).
+			WithField("restarts", restarts).
+			WithField("backoff", backoff).
+			Message("runner stopped, restarting it")
+
+		select {
+		case <-time.After(backoff):
+		case <-ctx.Done():
+			return nil
+		}
+
+		backoff *= 2
+		if backoff > s.maxBackoff {
+			backoff = s.maxBackoff
+		}
+	}
+}
+
+// Statuses returns the statuses of all supervised runners sorted by name.
+func (s *Supervisor) Statuses() []RunnerStatus {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	var statuses []RunnerStatus
+	for _, status := range s.statuses {
+		statuses = append(statuses, *status)
+	}
+
+	sort.Slice(statuses, func(i, j int) bool {
+		return statuses[i].Name < statuses[j].Name
+	})
+
+	return statuses
+}
+
+// Register adds the runners to the statuses before they are supervised so
+// that they are reported as not running until they start.
+func (s *Supervisor) Register(runners ...Runner) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	for _, runner := range runners {
+		if _, ok := s.statuses[runner.Name]; !ok {
+			s.statuses[runner.Name] = &RunnerStatus{
+				Name:     runner.Name,
+				Critical: runner.Critical,
+			}
+		}
+	}
+}
+
+// MarkReady is called by runners which report when they are ready. It does
+// nothing if the runner isn't running.
+func (s *Supervisor) MarkReady(name string) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if status, ok := s.statuses[name]; ok && status.Running {
+		status.Ready = true
+	}
+}
+
+func (s *Supervisor) setRunning(name string, running, ready bool) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.statuses[name].Running = running
+	s.statuses[name].Ready = ready
+}
+
+func (s *Supervisor) incrementRestarts(name string) int {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.statuses[name].Restarts++
+	return s.statuses[name].Restarts
+}
@@ -0,0 +1,161 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/planetary-social/scuttlego-pub/service"
+	"github.com/planetary-social/scuttlego/logging"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSupervisor_RestartableRunnersAreRestarted(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	supervisor := service.NewSupervisorWithBackoff(time.Millisecond, 10*time.Millisecond, logging.NewDevNullLogger())
+
+	var runs atomic.Int64
+
+	runner := service.Runner{
+		Name: "some_runner",
+		Run: func(ctx context.Context) error {
+			if runs.Add(1) <= 3 {
+				return errors.New("some error")
+			}
+			<-ctx.Done()
+			return ctx.Err()
+		},
+	}
+
+	errCh := make(chan error)
+	go func() {
+		errCh <- supervisor.Supervise(ctx, runner)
+	}()
+
+	require.Eventually(t, func() bool {
+		return runs.Load() == 4
+	}, time.Second, time.Millisecond)
+
+	require.Equal(t,
+		[]service.RunnerStatus{
+			{
+				Name:     "some_runner",
+				Critical: false,
+				Running:  true,
+				Ready:    true,
+				Restarts: 3,
+			},
+		},
+		supervisor.Statuses(),
+	)
+
+	cancel()
+
+	select {
+	case err := <-errCh:
+		require.ErrorIs(t, err, context.Canceled)
+	case <-time.After(time.Second):
+		t.Fatal("timeout")
+	}
+}
+
+func TestSupervisor_RunnersWhichReportReadyAreReadyOnlyOnceMarked(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	supervisor := service.NewSupervisorWithBackoff(time.Millisecond, 10*time.Millisecond, logging.NewDevNullLogger())
+
+	runner := service.Runner{
+		Name:         "some_runner",
+		ReportsReady: true,
+		Run: func(ctx context.Context) error {
+			<-ctx.Done()
+			return ctx.Err()
+		},
+	}
+
+	supervisor.Register(runner)
+	require.Equal(t,
+		[]service.RunnerStatus{
+			{
+				Name: "some_runner",
+			},
+		},
+		supervisor.Statuses(),
+		"registered runners should be reported before they start",
+	)
+
+	go func() {
+		_ = supervisor.Supervise(ctx, runner)
+	}()
+
+	require.Eventually(t, func() bool {
+		statuses := supervisor.Statuses()
+		return statuses[0].Running
+	}, time.Second, time.Millisecond)
+	require.False(t, supervisor.Statuses()[0].Ready)
+
+	supervisor.MarkReady("some_runner")
+	require.True(t, supervisor.Statuses()[0].Ready)
+
+	cancel()
+
+	require.Eventually(t, func() bool {
+		statuses := supervisor.Statuses()
+		return !statuses[0].Running && !statuses[0].Ready
+	}, time.Second, time.Millisecond)
+}
+
+func TestSupervisor_CriticalRunnersAreNotRestarted(t *testing.T) {
+	ctx := context.Background()
+
+	supervisor := service.NewSupervisorWithBackoff(time.Millisecond, 10*time.Millisecond, logging.NewDevNullLogger())
+
+	var runs atomic.Int64
+
+	runner := service.Runner{
+		Name:     "some_runner",
+		Critical: true,
+		Run: func(ctx context.Context) error {
+			runs.Add(1)
+			return nil
+		},
+	}
+
+	err := supervisor.Supervise(ctx, runner)
+	require.EqualError(t, err, "critical runner 'some_runner' stopped")
+	require.Equal(t, int64(1), runs.Load())
+
+	require.Equal(t,
+		[]service.RunnerStatus{
+			{
+				Name:     "some_runner",
+				Critical: true,
+				Running:  false,
+				Restarts: 0,
+			},
+		},
+		supervisor.Statuses(),
+	)
+}
+
+func TestSupervisor_CriticalRunnerErrorsAreReturned(t *testing.T) {
+	ctx := context.Background()
+
+	supervisor := service.NewSupervisorWithBackoff(time.Millisecond, 10*time.Millisecond, logging.NewDevNullLogger())
+
+	runner := service.Runner{
+		Name:     "some_runner",
+		Critical: true,
+		Run: func(ctx context.Context) error {
+			return errors.New("some error")
+		},
+	}
+
+	err := supervisor.Supervise(ctx, runner)
+	require.EqualError(t, err, "critical runner 'some_runner' failed: some error")
+}